@@ -15,10 +15,28 @@
 package attributes
 
 import (
+	"encoding/hex"
 	"fmt"
+	"strings"
+	"unicode"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes/azure"
+)
+
+// Semantic conventions keys that are not part of v1.6.1.
+// They are defined here so that they can be mapped along with the v1.6.1 ones.
+const (
+	// attributeContainerImageID is the runtime specific image identifier (v1.21.0).
+	attributeContainerImageID = "container.image.id"
+	// attributeFaaSInvocationID replaces faas.execution (v1.21.0).
+	attributeFaaSInvocationID = "faas.invocation_id"
+	// attributeDeploymentEnvironmentName replaces deployment.environment (v1.27.0).
+	attributeDeploymentEnvironmentName = "deployment.environment.name"
+	// attributeK8SHPAName is the name of the Kubernetes HorizontalPodAutoscaler.
+	attributeK8SHPAName = "k8s.hpa.name"
 )
 
 var (
@@ -30,6 +48,10 @@ var (
 		conventions.AttributeDeploymentEnvironment: "env",
 		conventions.AttributeServiceName:           "service",
 		conventions.AttributeServiceVersion:        "version",
+		attributeDeploymentEnvironmentName:         "env",
+		// service.namespace groups related services (e.g. those of a team or of a product). It has no
+		// unified service tagging equivalent: it is kept as its own tag rather than overloading env or service.
+		conventions.AttributeServiceNamespace: "service_namespace",
 
 		// Containers
 		conventions.AttributeContainerID:        "container_id",
@@ -37,6 +59,7 @@ var (
 		conventions.AttributeContainerImageName: "image_name",
 		conventions.AttributeContainerImageTag:  "image_tag",
 		conventions.AttributeContainerRuntime:   "runtime",
+		attributeContainerImageID:               "image_id",
 
 		// Cloud conventions
 		// https://www.datadoghq.com/blog/tagging-best-practices/
@@ -52,6 +75,13 @@ var (
 		conventions.AttributeAWSECSTaskRevision: "task_version",
 		conventions.AttributeAWSECSContainerARN: "ecs_container_name",
 
+		// FaaS conventions
+		// https://docs.datadoghq.com/serverless/guide/serverless_tagging/
+		conventions.AttributeFaaSName:      "function_name",
+		conventions.AttributeFaaSVersion:   "function_version",
+		conventions.AttributeFaaSExecution: "lambda_request_id",
+		attributeFaaSInvocationID:          "lambda_request_id",
+
 		// Kubernetes resource name (via semantic conventions)
 		// https://github.com/DataDog/datadog-agent/blob/e081bed/pkg/util/kubernetes/const.go
 		conventions.AttributeK8SContainerName:   "kube_container_name",
@@ -64,6 +94,12 @@ var (
 		conventions.AttributeK8SCronJobName:     "kube_cronjob",
 		conventions.AttributeK8SNamespaceName:   "kube_namespace",
 		conventions.AttributeK8SPodName:         "pod_name",
+		conventions.AttributeK8SNodeName:        "kube_node",
+		attributeK8SHPAName:                     "kube_hpa",
+
+		// Process runtime conventions
+		conventions.AttributeProcessRuntimeName:    "runtime_name",
+		conventions.AttributeProcessRuntimeVersion: "runtime_version",
 	}
 
 	// containerTagsAttributes contains a set of attributes that will be extracted as Datadog container tags.
@@ -73,6 +109,7 @@ var (
 		conventions.AttributeContainerImageName,
 		conventions.AttributeContainerImageTag,
 		conventions.AttributeContainerRuntime,
+		attributeContainerImageID,
 		conventions.AttributeK8SContainerName,
 		conventions.AttributeK8SClusterName,
 		conventions.AttributeK8SDeploymentName,
@@ -81,6 +118,7 @@ var (
 		conventions.AttributeK8SDaemonSetName,
 		conventions.AttributeK8SJobName,
 		conventions.AttributeK8SCronJobName,
+		attributeK8SHPAName,
 		conventions.AttributeK8SNamespaceName,
 		conventions.AttributeK8SPodName,
 		conventions.AttributeCloudProvider,
@@ -93,6 +131,21 @@ var (
 		conventions.AttributeAWSECSContainerARN,
 	}
 
+	// cloudProviderMappings defines, for each cloud provider, the mapping between OpenTelemetry
+	// semantic conventions and Datadog conventions that only applies when running on that provider.
+	cloudProviderMappings = map[string]map[string]string{
+		// GCP conventions
+		// https://docs.datadoghq.com/integrations/google_cloud_platform/
+		conventions.AttributeCloudProviderGCP: {
+			conventions.AttributeCloudAccountID: "project_id",
+		},
+		// Azure conventions
+		// https://docs.datadoghq.com/integrations/azure/
+		conventions.AttributeCloudProviderAzure: {
+			azure.AttributeResourceGroupName: "resource_group",
+		},
+	}
+
 	// Kubernetes mappings defines the mapping between Kubernetes conventions (both general and Datadog specific)
 	// and Datadog Agent conventions. The Datadog Agent conventions can be found at
 	// https://github.com/DataDog/datadog-agent/blob/e081bed/pkg/tagger/collectors/const.go and
@@ -113,15 +166,65 @@ var (
 	}
 )
 
+// AttributeMapping is a user-defined rule mapping an attribute to a Datadog tag.
+type AttributeMapping struct {
+	// OTLPKey is the attribute key to map.
+	OTLPKey string `json:"otlp_key"`
+	// DatadogTag is the name of the resulting Datadog tag.
+	DatadogTag string `json:"datadog_tag"`
+	// ValueTransformFunc optionally transforms the attribute value before it is used as tag value.
+	// It is not serialized.
+	ValueTransformFunc func(string) string `json:"-"`
+}
+
 // TagsFromAttributes converts a selected list of attributes
 // to a tag list that can be added to metrics.
-func TagsFromAttributes(attrs pcommon.Map) []string {
+func TagsFromAttributes(attrs pcommon.Map, opts ...TagOption) []string {
+	tags := TagsFromAttributesWithCustomMappings(attrs, nil)
+
+	var cfg tagConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if !cfg.podLabelsAsTags {
+		return tags
+	}
+	for _, tag := range cfg.podLabelTags(attrs) {
+		tags = append(tags, replaceControlChars(tag))
+	}
+	return DedupTags(tags)
+}
+
+// TagsFromAttributesWithCustomMappings converts a selected list of attributes, along with
+// those matching the given custom mappings, to a tag list that can be added to metrics.
+// Custom mappings take precedence over the built-in ones: an attribute matched by a custom
+// mapping is only converted through that mapping.
+func TagsFromAttributesWithCustomMappings(attrs pcommon.Map, mappings []AttributeMapping) []string {
 	tags := make([]string, 0, attrs.Len())
 
-	var processAttributes processAttributes
+	customMappings := make(map[string][]AttributeMapping, len(mappings))
+	for _, mapping := range mappings {
+		customMappings[mapping.OTLPKey] = append(customMappings[mapping.OTLPKey], mapping)
+	}
+
+	var processAttributes ProcessMetadata
 	var systemAttributes systemAttributes
 
+	var cloudProvider string
+	if v, ok := attrs.Get(conventions.AttributeCloudProvider); ok {
+		cloudProvider = v.Str()
+	}
+	cloudProviderMapping := cloudProviderMappings[cloudProvider]
+
 	attrs.Range(func(key string, value pcommon.Value) bool {
+		// Custom mappings
+		if keyMappings, found := customMappings[key]; found {
+			for _, mapping := range keyMappings {
+				tags = appendValueTags(tags, mapping.DatadogTag, value, mapping.ValueTransformFunc)
+			}
+			return true
+		}
+
 		switch key {
 		// Process attributes
 		case conventions.AttributeProcessExecutableName:
@@ -143,30 +246,137 @@ func TagsFromAttributes(attrs pcommon.Map) []string {
 		}
 
 		// conventions mapping
-		if datadogKey, found := conventionsMapping[key]; found && value.Str() != "" {
-			tags = append(tags, fmt.Sprintf("%s:%s", datadogKey, value.Str()))
+		if datadogKey, found := conventionsMapping[key]; found {
+			tags = appendValueTags(tags, datadogKey, value, nil)
 		}
 
 		// Kubernetes labels mapping
-		if datadogKey, found := kubernetesMapping[key]; found && value.Str() != "" {
-			tags = append(tags, fmt.Sprintf("%s:%s", datadogKey, value.Str()))
+		if datadogKey, found := kubernetesMapping[key]; found {
+			tags = appendValueTags(tags, datadogKey, value, nil)
+		}
+
+		// Cloud provider specific mapping
+		if datadogKey, found := cloudProviderMapping[key]; found {
+			tags = appendValueTags(tags, datadogKey, value, nil)
 		}
 		return true
 	})
 
+	// On AKS, the cluster name may only be available through the node resource group.
+	if cloudProvider == conventions.AttributeCloudProviderAzure {
+		if _, ok := attrs.Get(conventions.AttributeK8SClusterName); !ok {
+			if clusterName, ok := azure.ClusterNameFromAttributes(attrs); ok {
+				tags = append(tags, fmt.Sprintf("%s:%s", conventionsMapping[conventions.AttributeK8SClusterName], clusterName))
+			}
+		}
+	}
+
 	tags = append(tags, processAttributes.extractTags()...)
 	tags = append(tags, systemAttributes.extractTags()...)
 
+	for i, tag := range tags {
+		tags[i] = replaceControlChars(tag)
+	}
+	return DedupTags(tags)
+}
+
+// replaceControlChars replaces the control characters (e.g. newlines or null bytes) of
+// an attribute-derived string by underscores, so that it can be safely used in a tag.
+func replaceControlChars(s string) string {
+	if strings.IndexFunc(s, unicode.IsControl) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// dedupLinearScanMaxLen is the maximum number of tags DedupTags deduplicates with a linear scan
+// rather than with a map, which is faster and doesn't allocate for short lists.
+const dedupLinearScanMaxLen = 16
+
+// DedupTags removes duplicate tags in place, keeping the first occurrence of each tag.
+func DedupTags(tags []string) []string {
+	deduped := tags[:0]
+	if len(tags) <= dedupLinearScanMaxLen {
+		for _, tag := range tags {
+			if !containsTag(deduped, tag) {
+				deduped = append(deduped, tag)
+			}
+		}
+		return deduped
+	}
+
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		deduped = append(deduped, tag)
+	}
+	return deduped
+}
+
+// containsTag reports whether tags contains tag.
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// appendValueTags appends the tags for an attribute value with the given tag key.
+// Slice values produce one tag per element, all with the same key. Map values are flattened,
+// producing one tag per entry with the entry key appended to the tag key (e.g. `labels.app:myapp`).
+// The transform function, if any, is applied to every tag value. Empty values are skipped.
+func appendValueTags(tags []string, key string, value pcommon.Value, transform func(string) string) []string {
+	switch value.Type() {
+	case pcommon.ValueTypeSlice:
+		slice := value.Slice()
+		for i := 0; i < slice.Len(); i++ {
+			tags = appendValueTags(tags, key, slice.At(i), transform)
+		}
+	case pcommon.ValueTypeMap:
+		value.Map().Range(func(k string, v pcommon.Value) bool {
+			tags = appendValueTags(tags, key+"."+k, v, transform)
+			return true
+		})
+	default:
+		tagValue := valueString(value)
+		if transform != nil {
+			tagValue = transform(tagValue)
+		}
+		if tagValue != "" {
+			tags = append(tags, fmt.Sprintf("%s:%s", key, tagValue))
+		}
+	}
 	return tags
 }
 
+// valueString returns the string representation of a value to be used in a tag.
+// Bytes values, typically identifiers, are hex-encoded.
+func valueString(value pcommon.Value) string {
+	if value.Type() == pcommon.ValueTypeBytes {
+		return hex.EncodeToString(value.Bytes().AsRaw())
+	}
+	return value.AsString()
+}
+
 // OriginIDFromAttributes gets the origin IDs from resource attributes.
 // If not found, an empty string is returned for each of them.
 func OriginIDFromAttributes(attrs pcommon.Map) (originID string) {
-	// originID is always empty. Container ID is preferred over Kubernetes pod UID.
+	// originID is always empty. Container ID is preferred over ECS container ARN, which is preferred over Kubernetes pod UID.
 	// Prefixes come from pkg/util/kubernetes/kubelet and pkg/util/containers.
 	if containerID, ok := attrs.Get(conventions.AttributeContainerID); ok {
 		originID = "container_id://" + containerID.AsString()
+	} else if containerARN, ok := attrs.Get(conventions.AttributeAWSECSContainerARN); ok {
+		originID = "ecs_container_arn://" + containerARN.AsString()
 	} else if podUID, ok := attrs.Get(conventions.AttributeK8SPodUID); ok {
 		originID = "kubernetes_pod_uid://" + podUID.AsString()
 	}
@@ -184,5 +394,28 @@ func ContainerTagFromAttributes(attr map[string]string) map[string]string {
 		}
 		ddtags[conventionsMapping[key]] = val
 	}
+	cloudProvider := attr[conventions.AttributeCloudProvider]
+	for key, datadogKey := range cloudProviderMappings[cloudProvider] {
+		if val, ok := attr[key]; ok {
+			ddtags[datadogKey] = val
+		}
+	}
+	if isLambda(attr[conventions.AttributeCloudPlatform]) {
+		for key, datadogKey := range lambdaMappings {
+			if val, ok := attr[key]; ok {
+				ddtags[datadogKey] = val
+			}
+		}
+	}
+	// On AKS, the cluster name may only be available through the node resource group.
+	clusterNameKey := conventionsMapping[conventions.AttributeK8SClusterName]
+	if _, ok := ddtags[clusterNameKey]; !ok && cloudProvider == conventions.AttributeCloudProviderAzure {
+		if clusterName, ok := azure.ClusterNameFromResourceGroup(attr[azure.AttributeResourceGroupName]); ok {
+			ddtags[clusterNameKey] = clusterName
+		}
+	}
+	for key, val := range ddtags {
+		ddtags[key] = replaceControlChars(val)
+	}
 	return ddtags
 }
@@ -15,12 +15,17 @@
 package attributes
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes/azure"
 )
 
 func TestTagsFromAttributes(t *testing.T) {
@@ -35,6 +40,13 @@ func TestTagsFromAttributes(t *testing.T) {
 		conventions.AttributeK8SDaemonSetName:      "daemon_set_name",
 		conventions.AttributeAWSECSClusterARN:      "cluster_arn",
 		conventions.AttributeContainerRuntime:      "cro",
+		conventions.AttributeK8SNodeName:           "node-1",
+		conventions.AttributeK8SDeploymentName:     "deployment_name",
+		conventions.AttributeK8SStatefulSetName:    "stateful_set_name",
+		conventions.AttributeK8SJobName:            "job_name",
+		conventions.AttributeK8SCronJobName:        "cronjob_name",
+		conventions.AttributeK8SReplicaSetName:     "replica_set_name",
+		attributeK8SHPAName:                        "hpa_name",
 		"tags.datadoghq.com/service":               "service_name",
 	}
 	attrs := pcommon.NewMap()
@@ -47,9 +59,479 @@ func TestTagsFromAttributes(t *testing.T) {
 		fmt.Sprintf("%s:%s", "ecs_cluster_name", "cluster_arn"),
 		fmt.Sprintf("%s:%s", "service", "service_name"),
 		fmt.Sprintf("%s:%s", "runtime", "cro"),
+		fmt.Sprintf("%s:%s", "kube_node", "node-1"),
+		fmt.Sprintf("%s:%s", "kube_deployment", "deployment_name"),
+		fmt.Sprintf("%s:%s", "kube_stateful_set", "stateful_set_name"),
+		fmt.Sprintf("%s:%s", "kube_job", "job_name"),
+		fmt.Sprintf("%s:%s", "kube_cronjob", "cronjob_name"),
+		fmt.Sprintf("%s:%s", "kube_replica_set", "replica_set_name"),
+		fmt.Sprintf("%s:%s", "kube_hpa", "hpa_name"),
+	}, TagsFromAttributes(attrs))
+}
+
+func TestTagsFromAttributesNewerConventions(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		tag   string
+	}{
+		{key: attributeContainerImageID, value: "sha256:8d2f", tag: "image_id:sha256:8d2f"},
+		{key: attributeFaaSInvocationID, value: "af9c3a5e", tag: "lambda_request_id:af9c3a5e"},
+		{key: attributeDeploymentEnvironmentName, value: "prod", tag: "env:prod"},
+		{key: conventions.AttributeProcessRuntimeName, value: "OpenJDK Runtime Environment", tag: "runtime_name:OpenJDK Runtime Environment"},
+		{key: conventions.AttributeProcessRuntimeVersion, value: "17.0.8+7", tag: "runtime_version:17.0.8+7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			attrs.PutStr(tt.key, tt.value)
+			assert.Equal(t, []string{tt.tag}, TagsFromAttributes(attrs))
+		})
+	}
+}
+
+func TestTagsFromAttributesLambda(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeCloudProvider: conventions.AttributeCloudProviderAWS,
+		conventions.AttributeCloudRegion:   "us-east-1",
+		conventions.AttributeFaaSName:      "checkout-handler",
+		conventions.AttributeFaaSVersion:   "$LATEST",
+		conventions.AttributeFaaSInstance:  "2023/06/01/[$LATEST]0123456789abcdef0123456789abcdef",
+		conventions.AttributeFaaSExecution: "af9c3a5e-1b7c-4a4b-9f2a-0c1d2e3f4a5b",
+		conventions.AttributeServiceName:   "checkout",
+	})
+
+	assert.ElementsMatch(t, []string{
+		"cloud_provider:aws",
+		"region:us-east-1",
+		"function_name:checkout-handler",
+		"function_version:$LATEST",
+		"lambda_request_id:af9c3a5e-1b7c-4a4b-9f2a-0c1d2e3f4a5b",
+		"service:checkout",
+	}, TagsFromAttributes(attrs))
+}
+
+func TestTagsFromAttributesPodLabels(t *testing.T) {
+	podAttrs := map[string]interface{}{
+		conventions.AttributeK8SPodName:     "checkout-6d4cf56db6-x7k2p",
+		"k8s.pod.labels.app":                "checkout",
+		"k8s.pod.labels.team":               "payments",
+		"k8s.pod.labels.pod-template-hash":  "6d4cf56db6",
+		"k8s.pod.labels.":                   "no-label",
+		"k8s.pod.labels.empty":              "",
+		"k8s.pod.annotations.sidecar.istio": "enabled",
+	}
+	tests := []struct {
+		name     string
+		attrs    map[string]interface{}
+		opts     []TagOption
+		expected []string
+	}{
+		{
+			name:     "disabled by default",
+			attrs:    podAttrs,
+			expected: []string{"pod_name:checkout-6d4cf56db6-x7k2p"},
+		},
+		{
+			name:  "all labels",
+			attrs: podAttrs,
+			opts:  []TagOption{WithPodLabelsAsTags()},
+			expected: []string{
+				"pod_name:checkout-6d4cf56db6-x7k2p",
+				"app:checkout",
+				"team:payments",
+				"pod-template-hash:6d4cf56db6",
+			},
+		},
+		{
+			name:  "restricted labels",
+			attrs: podAttrs,
+			opts:  []TagOption{WithPodLabelsAsTags("app"), WithPodLabelsAsTags("team", "missing")},
+			expected: []string{
+				"pod_name:checkout-6d4cf56db6-x7k2p",
+				"app:checkout",
+				"team:payments",
+			},
+		},
+		{
+			name:     "no labels",
+			attrs:    map[string]interface{}{conventions.AttributeK8SPodName: "checkout-6d4cf56db6-x7k2p"},
+			opts:     []TagOption{WithPodLabelsAsTags()},
+			expected: []string{"pod_name:checkout-6d4cf56db6-x7k2p"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			attrs.FromRaw(tt.attrs)
+			assert.ElementsMatch(t, tt.expected, TagsFromAttributes(attrs, tt.opts...))
+		})
+	}
+}
+
+func TestPodLabelTags(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeK8SPodName: "checkout-6d4cf56db6-x7k2p",
+		"k8s.pod.labels.app":            "checkout",
+		"k8s.pod.labels.team":           "payments",
+	})
+
+	assert.ElementsMatch(t, []string{"app:checkout", "team:payments"}, PodLabelTags(attrs, nil))
+	assert.Equal(t, []string{"kube_app:checkout"}, PodLabelTags(attrs, map[string]string{"app": "kube_app", "missing": "missing"}))
+}
+
+func TestTagsFromAttributesKubernetesNamespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "kubernetes namespace",
+			attrs:    map[string]interface{}{conventions.AttributeK8SNamespaceName: "payments"},
+			expected: []string{"kube_namespace:payments"},
+		},
+		{
+			name:     "empty kubernetes namespace",
+			attrs:    map[string]interface{}{conventions.AttributeK8SNamespaceName: ""},
+			expected: []string{},
+		},
+		{
+			// A custom namespace attribute is not a semantic convention and is not mapped.
+			name:     "custom namespace",
+			attrs:    map[string]interface{}{"namespace": "custom"},
+			expected: []string{},
+		},
+		{
+			name: "kubernetes and custom namespaces",
+			attrs: map[string]interface{}{
+				conventions.AttributeK8SNamespaceName: "payments",
+				"namespace":                           "custom",
+			},
+			expected: []string{"kube_namespace:payments"},
+		},
+		{
+			name: "kubernetes and service namespaces",
+			attrs: map[string]interface{}{
+				conventions.AttributeK8SNamespaceName: "payments",
+				conventions.AttributeServiceNamespace: "shop",
+			},
+			expected: []string{"kube_namespace:payments", "service_namespace:shop"},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			attrs.FromRaw(testInstance.attrs)
+			assert.ElementsMatch(t, testInstance.expected, TagsFromAttributes(attrs))
+		})
+	}
+}
+
+func TestTagsFromAttributesServiceNamespace(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeServiceName:      "checkout",
+		conventions.AttributeServiceNamespace: "shop",
+	})
+
+	assert.ElementsMatch(t, []string{
+		"service:checkout",
+		"service_namespace:shop",
+	}, TagsFromAttributes(attrs))
+}
+
+func TestTagsFromAttributesGCP(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeCloudProvider:         conventions.AttributeCloudProviderGCP,
+		conventions.AttributeCloudPlatform:         conventions.AttributeCloudPlatformGCPKubernetesEngine,
+		conventions.AttributeCloudAccountID:        "my-project",
+		conventions.AttributeCloudRegion:           "us-central1",
+		conventions.AttributeCloudAvailabilityZone: "us-central1-c",
+		conventions.AttributeK8SClusterName:        "my-cluster",
+	})
+
+	assert.ElementsMatch(t, []string{
+		"cloud_provider:gcp",
+		"project_id:my-project",
+		"region:us-central1",
+		"zone:us-central1-c",
+		"kube_cluster_name:my-cluster",
+	}, TagsFromAttributes(attrs))
+}
+
+func TestTagsFromAttributesCloudAccountIDNotGCP(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeCloudProvider:  conventions.AttributeCloudProviderAWS,
+		conventions.AttributeCloudAccountID: "123456789012",
+	})
+
+	assert.ElementsMatch(t, []string{
+		"cloud_provider:aws",
+	}, TagsFromAttributes(attrs))
+}
+
+func TestTagsFromAttributesAzure(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeCloudProvider: conventions.AttributeCloudProviderAzure,
+		conventions.AttributeCloudPlatform: "azure_aks",
+		conventions.AttributeCloudRegion:   "westeurope",
+		azure.AttributeResourceGroupName:   "MC_my-group_my-cluster_westeurope",
+	})
+
+	assert.ElementsMatch(t, []string{
+		"cloud_provider:azure",
+		"region:westeurope",
+		"resource_group:MC_my-group_my-cluster_westeurope",
+		"kube_cluster_name:my-cluster",
+	}, TagsFromAttributes(attrs))
+}
+
+func TestTagsFromAttributesAzurePartial(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		tags  []string
+	}{
+		{
+			name: "cluster name set",
+			attrs: map[string]interface{}{
+				conventions.AttributeCloudProvider:  conventions.AttributeCloudProviderAzure,
+				conventions.AttributeK8SClusterName: "other-cluster",
+				azure.AttributeResourceGroupName:    "MC_my-group_my-cluster_westeurope",
+			},
+			tags: []string{
+				"cloud_provider:azure",
+				"kube_cluster_name:other-cluster",
+				"resource_group:MC_my-group_my-cluster_westeurope",
+			},
+		},
+		{
+			name: "resource group not from AKS",
+			attrs: map[string]interface{}{
+				conventions.AttributeCloudProvider: conventions.AttributeCloudProviderAzure,
+				azure.AttributeResourceGroupName:   "my-group",
+			},
+			tags: []string{
+				"cloud_provider:azure",
+				"resource_group:my-group",
+			},
+		},
+		{
+			name: "resource group on other cloud provider",
+			attrs: map[string]interface{}{
+				conventions.AttributeCloudProvider: conventions.AttributeCloudProviderGCP,
+				azure.AttributeResourceGroupName:   "MC_my-group_my-cluster_westeurope",
+			},
+			tags: []string{
+				"cloud_provider:gcp",
+			},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			attrs.FromRaw(testInstance.attrs)
+			assert.ElementsMatch(t, testInstance.tags, TagsFromAttributes(attrs))
+		})
+	}
+}
+
+func TestTagsFromAttributesWithCustomMappings(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeServiceName:           "otelcol",
+		conventions.AttributeDeploymentEnvironment: "prod",
+		"acme.team":        "Payments",
+		"acme.cost_center": 1234,
+		"acme.empty":       "",
+		"unmapped":         "value",
+	})
+
+	mappings := []AttributeMapping{
+		{OTLPKey: "acme.team", DatadogTag: "team", ValueTransformFunc: strings.ToLower},
+		{OTLPKey: "acme.cost_center", DatadogTag: "cost_center"},
+		{OTLPKey: "acme.empty", DatadogTag: "empty"},
+		{OTLPKey: conventions.AttributeDeploymentEnvironment, DatadogTag: "environment"},
+	}
+
+	assert.ElementsMatch(t, []string{
+		"service:otelcol",
+		"environment:prod",
+		"team:payments",
+		"cost_center:1234",
+	}, TagsFromAttributesWithCustomMappings(attrs, mappings))
+}
+
+func TestTagsFromAttributesWithCustomMappingsNoMappings(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeServiceName: "otelcol",
+		"acme.team":                      "payments",
+	})
+
+	assert.Equal(t, TagsFromAttributes(attrs), TagsFromAttributesWithCustomMappings(attrs, nil))
+}
+
+func TestTagsFromAttributesSliceAndMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    map[string]interface{}
+		mappings []AttributeMapping
+		tags     []string
+	}{
+		{
+			name: "slice",
+			attrs: map[string]interface{}{
+				conventions.AttributeContainerImageTag: []interface{}{"1.0", "latest"},
+			},
+			tags: []string{"image_tag:1.0", "image_tag:latest"},
+		},
+		{
+			name: "empty slice",
+			attrs: map[string]interface{}{
+				conventions.AttributeContainerImageTag: []interface{}{},
+			},
+			tags: []string{},
+		},
+		{
+			name: "slice with empty elements",
+			attrs: map[string]interface{}{
+				conventions.AttributeContainerImageTag: []interface{}{"", nil, "latest"},
+			},
+			tags: []string{"image_tag:latest"},
+		},
+		{
+			name: "map",
+			attrs: map[string]interface{}{
+				"k8s.pod.labels": map[string]interface{}{
+					"app":  "myapp",
+					"tier": "backend",
+				},
+			},
+			mappings: []AttributeMapping{{OTLPKey: "k8s.pod.labels", DatadogTag: "k8s.labels"}},
+			tags:     []string{"k8s.labels.app:myapp", "k8s.labels.tier:backend"},
+		},
+		{
+			name: "nested map and slice",
+			attrs: map[string]interface{}{
+				"k8s.pod.labels": map[string]interface{}{
+					"app":   "myapp",
+					"zones": []interface{}{"a", "b"},
+					"owner": map[string]interface{}{"team": "payments"},
+				},
+			},
+			mappings: []AttributeMapping{{OTLPKey: "k8s.pod.labels", DatadogTag: "k8s.labels"}},
+			tags: []string{
+				"k8s.labels.app:myapp",
+				"k8s.labels.zones:a",
+				"k8s.labels.zones:b",
+				"k8s.labels.owner.team:payments",
+			},
+		},
+		{
+			name: "map with nil values",
+			attrs: map[string]interface{}{
+				"k8s.pod.labels": map[string]interface{}{
+					"app":   "myapp",
+					"empty": nil,
+				},
+			},
+			mappings: []AttributeMapping{{OTLPKey: "k8s.pod.labels", DatadogTag: "k8s.labels"}},
+			tags:     []string{"k8s.labels.app:myapp"},
+		},
+		{
+			name: "empty map",
+			attrs: map[string]interface{}{
+				"k8s.pod.labels": map[string]interface{}{},
+			},
+			mappings: []AttributeMapping{{OTLPKey: "k8s.pod.labels", DatadogTag: "k8s.labels"}},
+			tags:     []string{},
+		},
+		{
+			name: "slice with transform",
+			attrs: map[string]interface{}{
+				"acme.teams": []interface{}{"Payments", "Billing"},
+			},
+			mappings: []AttributeMapping{{OTLPKey: "acme.teams", DatadogTag: "team", ValueTransformFunc: strings.ToLower}},
+			tags:     []string{"team:payments", "team:billing"},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			assert.NoError(t, attrs.FromRaw(testInstance.attrs))
+			assert.ElementsMatch(t, testInstance.tags, TagsFromAttributesWithCustomMappings(attrs, testInstance.mappings))
+		})
+	}
+}
+
+func TestTagsFromAttributesBytes(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.PutEmptyBytes(conventions.AttributeContainerID).FromRaw([]byte{0xde, 0xad, 0xbe, 0xef, 0x01})
+	attrs.PutEmptyBytes("span.id").FromRaw([]byte{0x01, 0x02})
+
+	assert.ElementsMatch(t, []string{"container_id:deadbeef01"}, TagsFromAttributes(attrs))
+	// High cardinality identifiers are still converted when explicitly mapped.
+	assert.ElementsMatch(t, []string{"container_id:deadbeef01", "span_id:0102"}, TagsFromAttributesWithCustomMappings(attrs, []AttributeMapping{
+		{OTLPKey: "span.id", DatadogTag: "span_id"},
+	}))
+}
+
+func TestTagsFromAttributesDuplicates(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeDeploymentEnvironment: "prod",
+		"deployment.environment.name":              "prod",
+		"tags.datadoghq.com/env":                   "prod",
+		"tags.datadoghq.com/service":               "checkout",
+		conventions.AttributeServiceName:           "frontend",
+	})
+
+	assert.ElementsMatch(t, []string{
+		"env:prod",
+		"service:checkout",
+		"service:frontend",
 	}, TagsFromAttributes(attrs))
 }
 
+func TestDedupTags(t *testing.T) {
+	assert.Equal(t,
+		[]string{"env:prod", "service:checkout", "env:dev"},
+		DedupTags([]string{"env:prod", "service:checkout", "env:prod", "env:dev", "service:checkout"}),
+	)
+	assert.Empty(t, DedupTags(nil))
+
+	// Long lists are deduplicated with a map.
+	var tags, expected []string
+	for i := 0; i < 2*dedupLinearScanMaxLen; i++ {
+		tag := fmt.Sprintf("tag:%d", i)
+		tags = append(tags, tag, tag)
+		expected = append(expected, tag)
+	}
+	assert.Equal(t, expected, DedupTags(tags))
+}
+
+func TestDedupTagsShortNoAllocation(t *testing.T) {
+	tags := []string{"env:prod", "service:checkout", "env:prod", "env:dev", "service:checkout"}
+	buf := make([]string, len(tags))
+	allocs := testing.AllocsPerRun(100, func() {
+		copy(buf, tags)
+		DedupTags(buf)
+	})
+	assert.Zero(t, allocs)
+}
+
 func TestTagsFromAttributesEmpty(t *testing.T) {
 	attrs := pcommon.NewMap()
 
@@ -60,10 +542,16 @@ func TestContainerTagFromAttributes(t *testing.T) {
 	attributeMap := map[string]string{
 		conventions.AttributeContainerName:         "sample_app",
 		conventions.AttributeContainerImageTag:     "sample_app_image_tag",
+		attributeContainerImageID:                  "sample_app_image_id",
 		conventions.AttributeContainerRuntime:      "cro",
 		conventions.AttributeK8SContainerName:      "kube_sample_app",
 		conventions.AttributeK8SReplicaSetName:     "sample_replica_set",
 		conventions.AttributeK8SDaemonSetName:      "sample_daemonset_name",
+		conventions.AttributeK8SDeploymentName:     "sample_deployment_name",
+		conventions.AttributeK8SStatefulSetName:    "sample_statefulset_name",
+		conventions.AttributeK8SJobName:            "sample_job_name",
+		conventions.AttributeK8SCronJobName:        "sample_cronjob_name",
+		attributeK8SHPAName:                        "sample_hpa_name",
 		conventions.AttributeK8SPodName:            "sample_pod_name",
 		conventions.AttributeCloudProvider:         "sample_cloud_provider",
 		conventions.AttributeCloudRegion:           "sample_region",
@@ -79,10 +567,16 @@ func TestContainerTagFromAttributes(t *testing.T) {
 	assert.Equal(t, map[string]string{
 		"container_name":      "sample_app",
 		"image_tag":           "sample_app_image_tag",
+		"image_id":            "sample_app_image_id",
 		"runtime":             "cro",
 		"kube_container_name": "kube_sample_app",
 		"kube_replica_set":    "sample_replica_set",
 		"kube_daemon_set":     "sample_daemonset_name",
+		"kube_deployment":     "sample_deployment_name",
+		"kube_stateful_set":   "sample_statefulset_name",
+		"kube_job":            "sample_job_name",
+		"kube_cronjob":        "sample_cronjob_name",
+		"kube_hpa":            "sample_hpa_name",
 		"pod_name":            "sample_pod_name",
 		"cloud_provider":      "sample_cloud_provider",
 		"region":              "sample_region",
@@ -93,6 +587,74 @@ func TestContainerTagFromAttributes(t *testing.T) {
 	}, ContainerTagFromAttributes(attributeMap))
 }
 
+func TestContainerTagFromAttributesLambda(t *testing.T) {
+	attributeMap := make(map[string]string, len(lambdaAttributes))
+	for key, val := range lambdaAttributes {
+		attributeMap[key] = fmt.Sprint(val)
+	}
+
+	assert.Equal(t, map[string]string{
+		"cloud_provider":   "aws",
+		"region":           "us-east-1",
+		"function_name":    "checkout-handler",
+		"function_version": "$LATEST",
+		"aws_account":      "123456789012",
+	}, ContainerTagFromAttributes(attributeMap))
+
+	// The Lambda tags are only set on AWS Lambda.
+	delete(attributeMap, conventions.AttributeCloudPlatform)
+	assert.Equal(t, map[string]string{
+		"cloud_provider": "aws",
+		"region":         "us-east-1",
+	}, ContainerTagFromAttributes(attributeMap))
+}
+
+func TestContainerTagFromAttributesGCP(t *testing.T) {
+	attributeMap := map[string]string{
+		conventions.AttributeCloudProvider:  conventions.AttributeCloudProviderGCP,
+		conventions.AttributeCloudAccountID: "my-project",
+		conventions.AttributeK8SClusterName: "my-cluster",
+	}
+
+	assert.Equal(t, map[string]string{
+		"cloud_provider":    "gcp",
+		"project_id":        "my-project",
+		"kube_cluster_name": "my-cluster",
+	}, ContainerTagFromAttributes(attributeMap))
+}
+
+func TestContainerTagFromAttributesAzure(t *testing.T) {
+	attributeMap := map[string]string{
+		conventions.AttributeCloudProvider: conventions.AttributeCloudProviderAzure,
+		azure.AttributeResourceGroupName:   "MC_my-group_my-cluster_westeurope",
+	}
+
+	assert.Equal(t, map[string]string{
+		"cloud_provider":    "azure",
+		"resource_group":    "MC_my-group_my-cluster_westeurope",
+		"kube_cluster_name": "my-cluster",
+	}, ContainerTagFromAttributes(attributeMap))
+}
+
+func TestContainerTagFromAttributesImage(t *testing.T) {
+	attributeMap := map[string]string{
+		conventions.AttributeContainerImageName: "myapp",
+		conventions.AttributeContainerImageTag:  "v1.2.3",
+	}
+
+	assert.Equal(t, map[string]string{
+		"image_name": "myapp",
+		"image_tag":  "v1.2.3",
+	}, ContainerTagFromAttributes(attributeMap))
+
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeContainerImageName: "myapp",
+		conventions.AttributeContainerImageTag:  "v1.2.3",
+	})
+	assert.ElementsMatch(t, []string{"image_name:myapp", "image_tag:v1.2.3"}, TagsFromAttributes(attrs))
+}
+
 func TestContainerTagFromAttributesEmpty(t *testing.T) {
 	assert.Empty(t, ContainerTagFromAttributes(map[string]string{}))
 }
@@ -137,6 +699,41 @@ func TestOriginIDFromAttributes(t *testing.T) {
 			}(),
 			originID: "kubernetes_pod_uid://k8s_pod_uid_goes_here",
 		},
+		{
+			name: "container ID and ECS container ARN",
+			attrs: func() pcommon.Map {
+				attributes := pcommon.NewMap()
+				attributes.FromRaw(map[string]interface{}{
+					conventions.AttributeContainerID:        "container_id_goes_here",
+					conventions.AttributeAWSECSContainerARN: "ecs_container_arn_goes_here",
+				})
+				return attributes
+			}(),
+			originID: "container_id://container_id_goes_here",
+		},
+		{
+			name: "ECS container ARN and pod UID",
+			attrs: func() pcommon.Map {
+				attributes := pcommon.NewMap()
+				attributes.FromRaw(map[string]interface{}{
+					conventions.AttributeAWSECSContainerARN: "ecs_container_arn_goes_here",
+					conventions.AttributeK8SPodUID:          "k8s_pod_uid_goes_here",
+				})
+				return attributes
+			}(),
+			originID: "ecs_container_arn://ecs_container_arn_goes_here",
+		},
+		{
+			name: "only ECS container ARN",
+			attrs: func() pcommon.Map {
+				attributes := pcommon.NewMap()
+				attributes.FromRaw(map[string]interface{}{
+					conventions.AttributeAWSECSContainerARN: "arn:aws:ecs:us-east-1:123456789012:container/cluster/task/container",
+				})
+				return attributes
+			}(),
+			originID: "ecs_container_arn://arn:aws:ecs:us-east-1:123456789012:container/cluster/task/container",
+		},
 		{
 			name:  "none",
 			attrs: pcommon.NewMap(),
@@ -150,3 +747,102 @@ func TestOriginIDFromAttributes(t *testing.T) {
 		})
 	}
 }
+
+// fuzzSeeds are attribute payloads taken from the test cases above.
+var fuzzSeeds = []map[string]interface{}{
+	{
+		conventions.AttributeProcessExecutableName: "otelcol",
+		conventions.AttributeProcessCommandLine:    "cmd/otelcol --config=\"/path/to/config.yaml\"",
+		conventions.AttributeProcessPID:            1,
+		conventions.AttributeOSType:                "linux",
+		conventions.AttributeK8SDaemonSetName:      "daemon_set_name",
+		conventions.AttributeAWSECSClusterARN:      "cluster_arn",
+		"tags.datadoghq.com/service":               "service_name",
+	},
+	{
+		attributeDeploymentEnvironmentName:     "prod",
+		attributeContainerImageID:              "sha256:8d2f",
+		conventions.AttributeContainerImageTag: []interface{}{"1.0", "latest"},
+	},
+	{
+		conventions.AttributeCloudProvider:  conventions.AttributeCloudProviderGCP,
+		conventions.AttributeCloudAccountID: "my-project",
+		conventions.AttributeK8SClusterName: "my-cluster",
+	},
+	{
+		conventions.AttributeCloudProvider: conventions.AttributeCloudProviderAzure,
+		azure.AttributeResourceGroupName:   "MC_my-group_my-cluster_westeurope",
+	},
+	{
+		conventions.AttributeServiceName: map[string]interface{}{"name": "otelcol", "nested": map[string]interface{}{"a": true}},
+		conventions.AttributeContainerID: nil,
+		"":                               "empty_string_key",
+	},
+	{
+		conventions.AttributeServiceName:      "line\nbreak",
+		conventions.AttributeContainerName:    "null\x00byte",
+		"app.kubernetes.io/name":              "\r\n",
+		conventions.AttributeK8SNamespaceName: 3.5,
+	},
+}
+
+// fuzzAttributes decodes a fuzzed JSON object into attributes.
+// It reports false if the payload is not a JSON object.
+func fuzzAttributes(payload []byte) (pcommon.Map, bool) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payload, &raw); err != nil || raw == nil {
+		return pcommon.Map{}, false
+	}
+	attrs := pcommon.NewMap()
+	if err := attrs.FromRaw(raw); err != nil {
+		return pcommon.Map{}, false
+	}
+	return attrs, true
+}
+
+// assertValidTag checks that a tag can be safely reported.
+func assertValidTag(t *testing.T, tag string) {
+	assert.NotContains(t, tag, "\n")
+	assert.NotContains(t, tag, "\x00")
+}
+
+func FuzzTagsFromAttributes(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		payload, err := json.Marshal(seed)
+		require.NoError(f, err)
+		f.Add(payload)
+	}
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		attrs, ok := fuzzAttributes(payload)
+		if !ok {
+			t.Skip()
+		}
+		for _, tag := range TagsFromAttributes(attrs) {
+			assertValidTag(t, tag)
+		}
+	})
+}
+
+func FuzzContainerTagFromAttributes(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		payload, err := json.Marshal(seed)
+		require.NoError(f, err)
+		f.Add(payload)
+	}
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		attrs, ok := fuzzAttributes(payload)
+		if !ok {
+			t.Skip()
+		}
+		attributeMap := make(map[string]string, attrs.Len())
+		attrs.Range(func(k string, v pcommon.Value) bool {
+			attributeMap[k] = v.AsString()
+			return true
+		})
+		for key, value := range ContainerTagFromAttributes(attributeMap) {
+			assertValidTag(t, key+":"+value)
+		}
+	})
+}
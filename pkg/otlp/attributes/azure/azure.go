@@ -48,12 +48,18 @@ func HostnameFromAttrs(attrs pcommon.Map) (string, bool) {
 func ClusterNameFromAttributes(attrs pcommon.Map) (string, bool) {
 	// Get cluster name from resource group from pkg/util/cloudprovider/azure:GetClusterName
 	if resourceGroup, ok := attrs.Get(AttributeResourceGroupName); ok {
-		splitAll := strings.Split(resourceGroup.Str(), "_")
-		if len(splitAll) < 4 || strings.ToLower(splitAll[0]) != "mc" {
-			return "", false // Failed to parse
-		}
-		return splitAll[len(splitAll)-2], true
+		return ClusterNameFromResourceGroup(resourceGroup.Str())
 	}
 
 	return "", false
 }
+
+// ClusterNameFromResourceGroup gets the Azure cluster name from the name of an AKS node resource group
+// (e.g. MC_myResourceGroup_myAKSCluster_eastus).
+func ClusterNameFromResourceGroup(resourceGroup string) (string, bool) {
+	splitAll := strings.Split(resourceGroup, "_")
+	if len(splitAll) < 4 || strings.ToLower(splitAll[0]) != "mc" {
+		return "", false // Failed to parse
+	}
+	return splitAll[len(splitAll)-2], true
+}
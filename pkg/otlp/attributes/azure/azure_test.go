@@ -59,6 +59,18 @@ func TestClusterNameFromAttributes(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestClusterNameFromResourceGroup(t *testing.T) {
+	cluster, ok := ClusterNameFromResourceGroup("MC_aks-kenafeh_aks-kenafeh-eu_westeurope")
+	assert.True(t, ok)
+	assert.Equal(t, "aks-kenafeh-eu", cluster)
+
+	_, ok = ClusterNameFromResourceGroup("rg_aks-kenafeh_aks-kenafeh-eu_westeurope")
+	assert.False(t, ok)
+
+	_, ok = ClusterNameFromResourceGroup("")
+	assert.False(t, ok)
+}
+
 func TestHostnameFromAttrs(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+// ContainerTags holds the Datadog container tags extracted from a set of attributes.
+// Empty fields are not set.
+type ContainerTags struct {
+	// Containers
+	ContainerID   string
+	ContainerName string
+	ImageName     string
+	ImageTag      string
+	ImageID       string
+	Runtime       string
+
+	// Kubernetes
+	KubeContainerName string
+	KubeClusterName   string
+	KubeDeployment    string
+	KubeReplicaSet    string
+	KubeStatefulSet   string
+	KubeDaemonSet     string
+	KubeJob           string
+	KubeCronJob       string
+	KubeHPA           string
+	KubeNamespace     string
+	PodName           string
+
+	// Cloud
+	CloudProvider string
+	Region        string
+	Zone          string
+	ProjectID     string
+	ResourceGroup string
+
+	// AWS Lambda
+	FunctionName    string
+	FunctionVersion string
+	AWSAccount      string
+
+	// ECS
+	TaskFamily       string
+	TaskARN          string
+	ECSClusterName   string
+	TaskVersion      string
+	ECSContainerName string
+}
+
+// fields returns pointers to the fields of the container tags, indexed by Datadog tag key.
+func (c *ContainerTags) fields() map[string]*string {
+	return map[string]*string{
+		"container_id":        &c.ContainerID,
+		"container_name":      &c.ContainerName,
+		"image_name":          &c.ImageName,
+		"image_tag":           &c.ImageTag,
+		"image_id":            &c.ImageID,
+		"runtime":             &c.Runtime,
+		"kube_container_name": &c.KubeContainerName,
+		"kube_cluster_name":   &c.KubeClusterName,
+		"kube_deployment":     &c.KubeDeployment,
+		"kube_replica_set":    &c.KubeReplicaSet,
+		"kube_stateful_set":   &c.KubeStatefulSet,
+		"kube_daemon_set":     &c.KubeDaemonSet,
+		"kube_job":            &c.KubeJob,
+		"kube_cronjob":        &c.KubeCronJob,
+		"kube_hpa":            &c.KubeHPA,
+		"kube_namespace":      &c.KubeNamespace,
+		"pod_name":            &c.PodName,
+		"cloud_provider":      &c.CloudProvider,
+		"region":              &c.Region,
+		"zone":                &c.Zone,
+		"project_id":          &c.ProjectID,
+		"resource_group":      &c.ResourceGroup,
+		"function_name":       &c.FunctionName,
+		"function_version":    &c.FunctionVersion,
+		"aws_account":         &c.AWSAccount,
+		"task_family":         &c.TaskFamily,
+		"task_arn":            &c.TaskARN,
+		"ecs_cluster_name":    &c.ECSClusterName,
+		"task_version":        &c.TaskVersion,
+		"ecs_container_name":  &c.ECSContainerName,
+	}
+}
+
+// ToMap returns the container tags as a map from Datadog tag key to value, in the format
+// returned by ContainerTagFromAttributes. Empty fields are omitted.
+func (c ContainerTags) ToMap() map[string]string {
+	ddtags := make(map[string]string)
+	for key, val := range c.fields() {
+		if *val != "" {
+			ddtags[key] = *val
+		}
+	}
+	return ddtags
+}
+
+// ContainerTagFromAttributesV2 extracts the Datadog container tags from the given set of attributes.
+// It works like ContainerTagFromAttributes, but returns a ContainerTags struct.
+func ContainerTagFromAttributesV2(attr map[string]string) ContainerTags {
+	var containerTags ContainerTags
+	fields := containerTags.fields()
+	for key, val := range ContainerTagFromAttributes(attr) {
+		if field, ok := fields[key]; ok {
+			*field = val
+		}
+	}
+	return containerTags
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes/azure"
+)
+
+func TestContainerTagFromAttributesV2(t *testing.T) {
+	attributeMap := map[string]string{
+		conventions.AttributeContainerName:      "sample_app",
+		conventions.AttributeContainerImageTag:  "sample_app_image_tag",
+		conventions.AttributeContainerRuntime:   "cro",
+		conventions.AttributeK8SClusterName:     "sample_cluster",
+		conventions.AttributeK8SNamespaceName:   "sample_namespace",
+		conventions.AttributeCloudProvider:      conventions.AttributeCloudProviderGCP,
+		conventions.AttributeCloudAccountID:     "sample_project",
+		conventions.AttributeAWSECSTaskRevision: "3",
+		"custom_tag":                            "example_custom_tag",
+	}
+
+	assert.Equal(t, ContainerTags{
+		ContainerName:   "sample_app",
+		ImageTag:        "sample_app_image_tag",
+		Runtime:         "cro",
+		KubeClusterName: "sample_cluster",
+		KubeNamespace:   "sample_namespace",
+		CloudProvider:   "gcp",
+		ProjectID:       "sample_project",
+		TaskVersion:     "3",
+	}, ContainerTagFromAttributesV2(attributeMap))
+}
+
+func TestContainerTagFromAttributesV2Parity(t *testing.T) {
+	allAttributes := make(map[string]string)
+	for _, key := range containerTagsAttributes {
+		allAttributes[key] = "value_of_" + key
+	}
+
+	tests := []struct {
+		name string
+		attr map[string]string
+	}{
+		{
+			name: "empty",
+			attr: map[string]string{},
+		},
+		{
+			name: "all container attributes",
+			attr: allAttributes,
+		},
+		{
+			name: "GCP",
+			attr: map[string]string{
+				conventions.AttributeCloudProvider:  conventions.AttributeCloudProviderGCP,
+				conventions.AttributeCloudAccountID: "my-project",
+				conventions.AttributeK8SClusterName: "my-cluster",
+			},
+		},
+		{
+			name: "Azure",
+			attr: map[string]string{
+				conventions.AttributeCloudProvider: conventions.AttributeCloudProviderAzure,
+				azure.AttributeResourceGroupName:   "MC_my-group_my-cluster_westeurope",
+			},
+		},
+		{
+			name: "AWS Lambda",
+			attr: map[string]string{
+				conventions.AttributeCloudProvider:  conventions.AttributeCloudProviderAWS,
+				conventions.AttributeCloudPlatform:  conventions.AttributeCloudPlatformAWSLambda,
+				conventions.AttributeFaaSName:       "checkout-handler",
+				conventions.AttributeCloudAccountID: "123456789012",
+			},
+		},
+		{
+			name: "unknown attributes",
+			attr: map[string]string{
+				conventions.AttributeContainerName: "sample_app",
+				"custom_tag":                       "example_custom_tag",
+				"":                                 "empty_string_key",
+			},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			assert.Equal(t,
+				ContainerTagFromAttributes(testInstance.attr),
+				ContainerTagFromAttributesV2(testInstance.attr).ToMap(),
+			)
+		})
+	}
+}
+
+// TestContainerTagsFields tests that every container tag has a ContainerTags field.
+func TestContainerTagsFields(t *testing.T) {
+	fields := (&ContainerTags{}).fields()
+	for _, key := range containerTagsAttributes {
+		assert.Contains(t, fields, conventionsMapping[key])
+	}
+	for _, mapping := range cloudProviderMappings {
+		for _, datadogKey := range mapping {
+			assert.Contains(t, fields, datadogKey)
+		}
+	}
+	for _, datadogKey := range lambdaMappings {
+		assert.Contains(t, fields, datadogKey)
+	}
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+// lambdaMappings defines the mapping between OpenTelemetry semantic conventions and the tags
+// of the Datadog AWS Lambda integration, which only applies on AWS Lambda.
+// https://docs.datadoghq.com/serverless/guide/serverless_tagging/
+var lambdaMappings = map[string]string{
+	conventions.AttributeFaaSName:       "function_name",
+	conventions.AttributeFaaSVersion:    "function_version",
+	conventions.AttributeCloudRegion:    "region",
+	conventions.AttributeCloudAccountID: "aws_account",
+}
+
+// isLambda returns whether the given cloud platform is AWS Lambda.
+func isLambda(cloudPlatform string) bool {
+	return cloudPlatform == conventions.AttributeCloudPlatformAWSLambda
+}
+
+// FaaSTagsFromAttributes extracts the tags of the Datadog AWS Lambda integration from the resource
+// attributes of a Lambda function, as reported by the OpenTelemetry Lambda layer.
+// It returns an empty map if cloud.platform is not aws_lambda.
+func FaaSTagsFromAttributes(attrs pcommon.Map) map[string]string {
+	ddtags := make(map[string]string)
+	if platform, ok := attrs.Get(conventions.AttributeCloudPlatform); !ok || !isLambda(platform.AsString()) {
+		return ddtags
+	}
+	for key, datadogKey := range lambdaMappings {
+		if val, ok := attrs.Get(key); ok {
+			ddtags[datadogKey] = replaceControlChars(val.AsString())
+		}
+	}
+	return ddtags
+}
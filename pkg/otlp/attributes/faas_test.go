@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+// lambdaAttributes are the resource attributes set by the OpenTelemetry Lambda layer.
+var lambdaAttributes = map[string]interface{}{
+	conventions.AttributeCloudProvider:  conventions.AttributeCloudProviderAWS,
+	conventions.AttributeCloudPlatform:  conventions.AttributeCloudPlatformAWSLambda,
+	conventions.AttributeCloudRegion:    "us-east-1",
+	conventions.AttributeCloudAccountID: "123456789012",
+	conventions.AttributeFaaSName:       "checkout-handler",
+	conventions.AttributeFaaSVersion:    "$LATEST",
+	conventions.AttributeFaaSInstance:   "2023/06/01/[$LATEST]0123456789abcdef0123456789abcdef",
+	conventions.AttributeFaaSMaxMemory:  512,
+	conventions.AttributeServiceName:    "checkout",
+}
+
+func TestFaaSTagsFromAttributes(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(lambdaAttributes)
+
+	assert.Equal(t, map[string]string{
+		"function_name":    "checkout-handler",
+		"function_version": "$LATEST",
+		"region":           "us-east-1",
+		"aws_account":      "123456789012",
+	}, FaaSTagsFromAttributes(attrs))
+}
+
+func TestFaaSTagsFromAttributesNotLambda(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeCloudProvider:  conventions.AttributeCloudProviderAWS,
+		conventions.AttributeCloudPlatform:  conventions.AttributeCloudPlatformAWSECS,
+		conventions.AttributeCloudRegion:    "us-east-1",
+		conventions.AttributeCloudAccountID: "123456789012",
+	})
+	assert.Empty(t, FaaSTagsFromAttributes(attrs))
+	assert.Empty(t, FaaSTagsFromAttributes(pcommon.NewMap()))
+}
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// AttributeMappingTable is a set of user-defined attribute mappings that can be loaded from a JSON configuration.
+// Its JSON representation is described by the testdata/attribute_mapping_table.schema.json schema.
+type AttributeMappingTable struct {
+	// Mappings are the attribute mappings, in the format expected by TagsFromAttributesWithCustomMappings.
+	Mappings []AttributeMapping `json:"mappings"`
+}
+
+// LoadAttributeMappingTable reads an AttributeMappingTable from its JSON representation.
+// Unknown fields are rejected, and every mapping must have an OTLP key and a Datadog tag.
+func LoadAttributeMappingTable(r io.Reader) (*AttributeMappingTable, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var table AttributeMappingTable
+	if err := decoder.Decode(&table); err != nil {
+		return nil, fmt.Errorf("failed to decode attribute mapping table: %w", err)
+	}
+	for i, mapping := range table.Mappings {
+		if mapping.OTLPKey == "" || mapping.DatadogTag == "" {
+			return nil, fmt.Errorf("attribute mapping %d must have an OTLP key and a Datadog tag", i)
+		}
+	}
+	return &table, nil
+}
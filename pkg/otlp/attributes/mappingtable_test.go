@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestLoadAttributeMappingTableRoundTrip(t *testing.T) {
+	f, err := os.Open("testdata/attribute_mapping_table.json")
+	require.NoError(t, err)
+	defer f.Close()
+
+	table, err := LoadAttributeMappingTable(f)
+	require.NoError(t, err)
+	assert.Equal(t, &AttributeMappingTable{
+		Mappings: []AttributeMapping{
+			{OTLPKey: "acme.team", DatadogTag: "team"},
+			{OTLPKey: "deployment.environment", DatadogTag: "environment"},
+		},
+	}, table)
+
+	b, err := json.Marshal(table)
+	require.NoError(t, err)
+	roundTripped, err := LoadAttributeMappingTable(bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, table, roundTripped)
+
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		"acme.team":              "payments",
+		"deployment.environment": "prod",
+	})
+	assert.ElementsMatch(t, []string{"team:payments", "environment:prod"}, TagsFromAttributesWithCustomMappings(attrs, table.Mappings))
+}
+
+func TestLoadAttributeMappingTableErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{
+			name: "invalid JSON",
+			json: `{"mappings": [`,
+			err:  "failed to decode attribute mapping table: unexpected EOF",
+		},
+		{
+			name: "unknown field",
+			json: `{"mappings": [{"otlp_key": "a", "datadog_tag": "b", "transform": "lower"}]}`,
+			err:  `failed to decode attribute mapping table: json: unknown field "transform"`,
+		},
+		{
+			name: "missing Datadog tag",
+			json: `{"mappings": [{"otlp_key": "a", "datadog_tag": "b"}, {"otlp_key": "c"}]}`,
+			err:  "attribute mapping 1 must have an OTLP key and a Datadog tag",
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			_, err := LoadAttributeMappingTable(strings.NewReader(testInstance.json))
+			assert.EqualError(t, err, testInstance.err)
+		})
+	}
+}
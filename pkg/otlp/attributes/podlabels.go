@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// kubernetesPodLabelPrefix is the prefix of the attributes holding Kubernetes pod labels,
+// as set by the k8sattributes processor.
+const kubernetesPodLabelPrefix = "k8s.pod.labels."
+
+type tagConfig struct {
+	podLabelsAsTags bool
+	// podLabels maps the pod labels converted to tags to their tag keys; all labels are converted if empty.
+	podLabels map[string]string
+}
+
+// TagOption is an option for TagsFromAttributes.
+type TagOption func(*tagConfig)
+
+// WithPodLabelsAsTags converts the Kubernetes pod labels, reported as k8s.pod.labels.<label> attributes,
+// to <label>:<value> tags. If labels are given, only these labels are converted, to avoid
+// a cardinality explosion; otherwise, every label is converted.
+func WithPodLabelsAsTags(labels ...string) TagOption {
+	return func(c *tagConfig) {
+		c.podLabelsAsTags = true
+		if len(labels) == 0 {
+			return
+		}
+		if c.podLabels == nil {
+			c.podLabels = make(map[string]string, len(labels))
+		}
+		for _, label := range labels {
+			c.podLabels[label] = label
+		}
+	}
+}
+
+// podLabelTags returns the tags of the Kubernetes pod labels allowed by the configuration.
+func (c *tagConfig) podLabelTags(attrs pcommon.Map) []string {
+	return PodLabelTags(attrs, c.podLabels)
+}
+
+// PodLabelTags returns the tags of the Kubernetes pod labels reported as k8s.pod.labels.<label> attributes.
+// labelMapping maps the labels to convert to the keys of their tags. If it is empty, every label
+// is converted to a tag whose key is the label name.
+func PodLabelTags(attrs pcommon.Map, labelMapping map[string]string) []string {
+	var tags []string
+	attrs.Range(func(key string, value pcommon.Value) bool {
+		label := strings.TrimPrefix(key, kubernetesPodLabelPrefix)
+		if label == key || label == "" {
+			return true
+		}
+		if len(labelMapping) == 0 {
+			tags = appendValueTags(tags, label, value, nil)
+		} else if tagKey, ok := labelMapping[label]; ok {
+			tags = appendValueTags(tags, tagKey, value, nil)
+		}
+		return true
+	})
+	return tags
+}
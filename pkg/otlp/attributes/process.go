@@ -17,10 +17,12 @@ package attributes
 import (
 	"fmt"
 
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
 )
 
-type processAttributes struct {
+// ProcessMetadata is the metadata of a process, as described by the process.* resource attributes.
+type ProcessMetadata struct {
 	ExecutableName string
 	ExecutablePath string
 	Command        string
@@ -29,7 +31,7 @@ type processAttributes struct {
 	Owner          string
 }
 
-func (pattrs *processAttributes) extractTags() []string {
+func (pattrs *ProcessMetadata) extractTags() []string {
 	tags := make([]string, 0, 1)
 
 	// According to OTel conventions: https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/resource/semantic_conventions/process.md,
@@ -54,3 +56,34 @@ func (pattrs *processAttributes) extractTags() []string {
 
 	return tags
 }
+
+// HostMetadata is the system metadata of the host a resource runs on.
+type HostMetadata struct {
+	// Process is the metadata of the process producing the resource.
+	Process ProcessMetadata
+}
+
+// ProcessAttributesToHostMetadata extracts the host metadata from the process.* attributes of a resource.
+// The zero value is returned if the resource has none of them.
+func ProcessAttributesToHostMetadata(attrs pcommon.Map) HostMetadata {
+	var process ProcessMetadata
+	if v, ok := attrs.Get(conventions.AttributeProcessExecutableName); ok {
+		process.ExecutableName = v.AsString()
+	}
+	if v, ok := attrs.Get(conventions.AttributeProcessExecutablePath); ok {
+		process.ExecutablePath = v.AsString()
+	}
+	if v, ok := attrs.Get(conventions.AttributeProcessCommand); ok {
+		process.Command = v.AsString()
+	}
+	if v, ok := attrs.Get(conventions.AttributeProcessCommandLine); ok {
+		process.CommandLine = v.AsString()
+	}
+	if v, ok := attrs.Get(conventions.AttributeProcessPID); ok && v.Type() == pcommon.ValueTypeInt {
+		process.PID = v.Int()
+	}
+	if v, ok := attrs.Get(conventions.AttributeProcessOwner); ok {
+		process.Owner = v.AsString()
+	}
+	return HostMetadata{Process: process}
+}
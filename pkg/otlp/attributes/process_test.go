@@ -19,11 +19,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
 )
 
 func TestProcessExtractTags(t *testing.T) {
-	pattrs := processAttributes{
+	pattrs := ProcessMetadata{
 		ExecutableName: "otelcol",
 		ExecutablePath: "/usr/bin/cmd/otelcol",
 		Command:        "cmd/otelcol",
@@ -36,7 +37,7 @@ func TestProcessExtractTags(t *testing.T) {
 		fmt.Sprintf("%s:%s", conventions.AttributeProcessExecutableName, "otelcol"),
 	}, pattrs.extractTags())
 
-	pattrs = processAttributes{
+	pattrs = ProcessMetadata{
 		ExecutablePath: "/usr/bin/cmd/otelcol",
 		Command:        "cmd/otelcol",
 		CommandLine:    "cmd/otelcol --config=\"/path/to/config.yaml\"",
@@ -48,7 +49,7 @@ func TestProcessExtractTags(t *testing.T) {
 		fmt.Sprintf("%s:%s", conventions.AttributeProcessExecutablePath, "/usr/bin/cmd/otelcol"),
 	}, pattrs.extractTags())
 
-	pattrs = processAttributes{
+	pattrs = ProcessMetadata{
 		Command:     "cmd/otelcol",
 		CommandLine: "cmd/otelcol --config=\"/path/to/config.yaml\"",
 		PID:         1,
@@ -59,7 +60,7 @@ func TestProcessExtractTags(t *testing.T) {
 		fmt.Sprintf("%s:%s", conventions.AttributeProcessCommand, "cmd/otelcol"),
 	}, pattrs.extractTags())
 
-	pattrs = processAttributes{
+	pattrs = ProcessMetadata{
 		CommandLine: "cmd/otelcol --config=\"/path/to/config.yaml\"",
 		PID:         1,
 		Owner:       "root",
@@ -71,7 +72,28 @@ func TestProcessExtractTags(t *testing.T) {
 }
 
 func TestProcessExtractTagsEmpty(t *testing.T) {
-	pattrs := processAttributes{}
+	pattrs := ProcessMetadata{}
 
 	assert.Equal(t, []string{}, pattrs.extractTags())
 }
+
+func TestProcessAttributesToHostMetadata(t *testing.T) {
+	attrs := pcommon.NewMap()
+	assert.Equal(t, HostMetadata{}, ProcessAttributesToHostMetadata(attrs))
+
+	attrs.PutStr(conventions.AttributeProcessExecutableName, "otelcol")
+	attrs.PutStr(conventions.AttributeProcessExecutablePath, "/usr/bin/cmd/otelcol")
+	attrs.PutStr(conventions.AttributeProcessCommand, "cmd/otelcol")
+	attrs.PutStr(conventions.AttributeProcessCommandLine, "cmd/otelcol --config=\"/path/to/config.yaml\"")
+	attrs.PutInt(conventions.AttributeProcessPID, 1)
+	attrs.PutStr(conventions.AttributeProcessOwner, "root")
+	attrs.PutStr(conventions.AttributeServiceName, "collector")
+	assert.Equal(t, HostMetadata{Process: ProcessMetadata{
+		ExecutableName: "otelcol",
+		ExecutablePath: "/usr/bin/cmd/otelcol",
+		Command:        "cmd/otelcol",
+		CommandLine:    "cmd/otelcol --config=\"/path/to/config.yaml\"",
+		PID:            1,
+		Owner:          "root",
+	}}, ProcessAttributesToHostMetadata(attrs))
+}
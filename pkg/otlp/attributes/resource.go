@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+// processAndSystemAttributes contains the attributes handled by TagsFromAttributes
+// that are not part of the conventions mappings.
+var processAndSystemAttributes = map[string]struct{}{
+	conventions.AttributeProcessExecutableName: {},
+	conventions.AttributeProcessExecutablePath: {},
+	conventions.AttributeProcessCommand:        {},
+	conventions.AttributeProcessCommandLine:    {},
+	conventions.AttributeProcessPID:            {},
+	conventions.AttributeProcessOwner:          {},
+	conventions.AttributeOSType:                {},
+}
+
+// highCardinalityAttributes contains identifiers that are never converted to raw tags,
+// since they would result in a new timeseries for each value.
+var highCardinalityAttributes = []string{
+	"span.id",
+	"trace.id",
+}
+
+type resourceTagConfig struct {
+	deniedKeys map[string]struct{}
+}
+
+// ResourceTagOption is an option for TagsFromResourceAttributes.
+type ResourceTagOption func(*resourceTagConfig)
+
+// WithDeniedResourceAttributes skips the given attribute keys, both for the
+// standard mapping and for the raw tags.
+func WithDeniedResourceAttributes(keys ...string) ResourceTagOption {
+	return func(c *resourceTagConfig) {
+		for _, key := range keys {
+			c.deniedKeys[key] = struct{}{}
+		}
+	}
+}
+
+// TagsFromResourceAttributes converts resource attributes to a tag list that can be added to metrics.
+// Attributes are first converted with the same mapping as TagsFromAttributes; every remaining
+// attribute is then added verbatim as a `key:value` tag. Map and Slice values are serialized to JSON,
+// and Bytes values are hex-encoded. High cardinality identifiers such as `span.id` are always skipped.
+func TagsFromResourceAttributes(attrs pcommon.Map, opts ...ResourceTagOption) []string {
+	cfg := resourceTagConfig{deniedKeys: make(map[string]struct{})}
+	WithDeniedResourceAttributes(highCardinalityAttributes...)(&cfg)
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if hasDeniedAttribute(attrs, cfg.deniedKeys) {
+		filtered := pcommon.NewMap()
+		attrs.CopyTo(filtered)
+		filtered.RemoveIf(func(key string, _ pcommon.Value) bool {
+			_, denied := cfg.deniedKeys[key]
+			return denied
+		})
+		attrs = filtered
+	}
+
+	tags := TagsFromAttributes(attrs)
+
+	var cloudProvider string
+	if v, ok := attrs.Get(conventions.AttributeCloudProvider); ok {
+		cloudProvider = v.Str()
+	}
+	cloudProviderMapping := cloudProviderMappings[cloudProvider]
+
+	attrs.Range(func(key string, value pcommon.Value) bool {
+		if isMappedAttribute(key, cloudProviderMapping) {
+			return true
+		}
+		if tagValue := valueString(value); tagValue != "" {
+			tags = append(tags, fmt.Sprintf("%s:%s", key, tagValue))
+		}
+		return true
+	})
+
+	return tags
+}
+
+// hasDeniedAttribute checks if any of the attributes is denied.
+func hasDeniedAttribute(attrs pcommon.Map, deniedKeys map[string]struct{}) bool {
+	for key := range deniedKeys {
+		if _, ok := attrs.Get(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isMappedAttribute checks if an attribute is handled by TagsFromAttributes.
+func isMappedAttribute(key string, cloudProviderMapping map[string]string) bool {
+	if _, ok := conventionsMapping[key]; ok {
+		return true
+	}
+	if _, ok := kubernetesMapping[key]; ok {
+		return true
+	}
+	if _, ok := cloudProviderMapping[key]; ok {
+		return true
+	}
+	_, ok := processAndSystemAttributes[key]
+	return ok
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+func TestTagsFromResourceAttributes(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeServiceName:           "otelcol",
+		conventions.AttributeDeploymentEnvironment: "prod",
+		conventions.AttributeProcessExecutableName: "otelcol",
+		conventions.AttributeProcessPID:            1,
+		conventions.AttributeCloudProvider:         conventions.AttributeCloudProviderGCP,
+		conventions.AttributeCloudAccountID:        "my-project",
+		"team":                                     "payments",
+		"replicas":                                 3,
+		"canary":                                   true,
+		"empty":                                    "",
+	})
+
+	assert.ElementsMatch(t, []string{
+		"service:otelcol",
+		"env:prod",
+		"process.executable.name:otelcol",
+		"cloud_provider:gcp",
+		"project_id:my-project",
+		"team:payments",
+		"replicas:3",
+		"canary:true",
+	}, TagsFromResourceAttributes(attrs))
+}
+
+func TestTagsFromResourceAttributesDenied(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeServiceName:           "otelcol",
+		conventions.AttributeDeploymentEnvironment: "prod",
+		"team":   "payments",
+		"secret": "hunter2",
+	})
+
+	assert.ElementsMatch(t, []string{
+		"service:otelcol",
+		"team:payments",
+	}, TagsFromResourceAttributes(attrs, WithDeniedResourceAttributes("secret", conventions.AttributeDeploymentEnvironment)))
+
+	// The original attributes are left untouched.
+	assert.Equal(t, 4, attrs.Len())
+}
+
+func TestTagsFromResourceAttributesNested(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		"labels": map[string]interface{}{
+			"app": "myapp",
+		},
+		"zones":        []interface{}{"us-east-1a", "us-east-1b"},
+		"empty.labels": map[string]interface{}{},
+	})
+
+	assert.ElementsMatch(t, []string{
+		`labels:{"app":"myapp"}`,
+		`zones:["us-east-1a","us-east-1b"]`,
+		`empty.labels:{}`,
+	}, TagsFromResourceAttributes(attrs))
+}
+
+func TestTagsFromResourceAttributesBytes(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.PutEmptyBytes("build.id").FromRaw([]byte{0xca, 0xfe})
+	attrs.PutEmptyBytes("span.id").FromRaw([]byte{0x01, 0x02})
+	attrs.PutEmptyBytes("trace.id").FromRaw([]byte{0x03, 0x04})
+	attrs.PutEmptyBytes("empty").FromRaw([]byte{})
+
+	assert.ElementsMatch(t, []string{"build.id:cafe"}, TagsFromResourceAttributes(attrs))
+	// The original attributes are left untouched.
+	assert.Equal(t, 4, attrs.Len())
+}
@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+// attributeContainerImageTags replaces container.image.tag in semantic conventions v1.22.0.
+// It holds all the tags of the container image.
+const attributeContainerImageTags = "container.image.tags"
+
+// schemaVersion is a semantic conventions version, as found at the end of a schema URL
+// (e.g. https://opentelemetry.io/schemas/1.21.0).
+type schemaVersion struct {
+	major, minor, patch int
+}
+
+// less checks if v is older than o.
+func (v schemaVersion) less(o schemaVersion) bool {
+	if v.major != o.major {
+		return v.major < o.major
+	}
+	if v.minor != o.minor {
+		return v.minor < o.minor
+	}
+	return v.patch < o.patch
+}
+
+// parseSchemaVersion parses the semantic conventions version of a schema URL.
+func parseSchemaVersion(schemaURL string) (schemaVersion, bool) {
+	parts := strings.Split(schemaURL[strings.LastIndex(schemaURL, "/")+1:], ".")
+	if len(parts) != 3 {
+		return schemaVersion{}, false
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return schemaVersion{}, false
+		}
+		numbers[i] = n
+	}
+	return schemaVersion{numbers[0], numbers[1], numbers[2]}, true
+}
+
+// schemaMappings lists, for each known semantic conventions version, the mappings for the attributes
+// that this version renamed. The built-in mapping follows v1.6.1; the mappings of a version apply
+// to all the newer versions.
+var schemaMappings = []struct {
+	version  schemaVersion
+	mappings []AttributeMapping
+}{
+	{version: schemaVersion{1, 6, 1}},
+	{version: schemaVersion{1, 22, 0}, mappings: []AttributeMapping{
+		{OTLPKey: attributeContainerImageTags, DatadogTag: conventionsMapping[conventions.AttributeContainerImageTag]},
+	}},
+}
+
+// SchemaAttributeMappings returns the mappings needed, in addition to the built-in ones, to convert
+// the attributes of a resource following the semantic conventions version of the given schema URL.
+// If the version is not known, the closest older known version is used; the oldest known version is used
+// if the schema URL is empty, invalid or older than all known versions.
+func SchemaAttributeMappings(schemaURL string) []AttributeMapping {
+	version, ok := parseSchemaVersion(schemaURL)
+	if !ok {
+		return nil
+	}
+	var mappings []AttributeMapping
+	for _, schema := range schemaMappings {
+		if version.less(schema.version) {
+			break
+		}
+		mappings = append(mappings, schema.mappings...)
+	}
+	return mappings
+}
+
+// TagsFromAttributesVersioned is like TagsFromAttributesWithCustomMappings, but it also maps the attributes
+// renamed by the semantic conventions version of the given schema URL. Custom mappings take precedence over
+// the version specific ones.
+func TagsFromAttributesVersioned(attrs pcommon.Map, schemaURL string, mappings []AttributeMapping) []string {
+	versioned := SchemaAttributeMappings(schemaURL)
+	if len(versioned) == 0 {
+		return TagsFromAttributesWithCustomMappings(attrs, mappings)
+	}
+	customKeys := make(map[string]struct{}, len(mappings))
+	for _, mapping := range mappings {
+		customKeys[mapping.OTLPKey] = struct{}{}
+	}
+	all := append([]AttributeMapping{}, mappings...)
+	for _, mapping := range versioned {
+		if _, ok := customKeys[mapping.OTLPKey]; !ok {
+			all = append(all, mapping)
+		}
+	}
+	return TagsFromAttributesWithCustomMappings(attrs, all)
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+func TestTagsFromAttributesVersioned(t *testing.T) {
+	// The same logical attribute, the image tags, under its v1.6.1 and v1.22.0 keys.
+	oldAttrs := pcommon.NewMap()
+	oldAttrs.PutStr(conventions.AttributeContainerImageTag, "1.2.3")
+	newAttrs := pcommon.NewMap()
+	newAttrs.PutEmptySlice(attributeContainerImageTags).FromRaw([]any{"1.2.3", "latest"})
+
+	tests := []struct {
+		name      string
+		attrs     pcommon.Map
+		schemaURL string
+		mappings  []AttributeMapping
+		expected  []string
+	}{
+		{
+			name:      "v1.6.1 key, v1.6.1 schema",
+			attrs:     oldAttrs,
+			schemaURL: "https://opentelemetry.io/schemas/1.6.1",
+			expected:  []string{"image_tag:1.2.3"},
+		},
+		{
+			name:      "v1.6.1 key, v1.21.0 schema",
+			attrs:     oldAttrs,
+			schemaURL: "https://opentelemetry.io/schemas/1.21.0",
+			expected:  []string{"image_tag:1.2.3"},
+		},
+		{
+			name:      "v1.22.0 key, v1.21.0 schema",
+			attrs:     newAttrs,
+			schemaURL: "https://opentelemetry.io/schemas/1.21.0",
+			expected:  []string{},
+		},
+		{
+			name:      "v1.22.0 key, v1.22.0 schema",
+			attrs:     newAttrs,
+			schemaURL: "https://opentelemetry.io/schemas/1.22.0",
+			expected:  []string{"image_tag:1.2.3", "image_tag:latest"},
+		},
+		{
+			name:      "v1.22.0 key, unknown newer schema",
+			attrs:     newAttrs,
+			schemaURL: "https://opentelemetry.io/schemas/1.24.0",
+			expected:  []string{"image_tag:1.2.3", "image_tag:latest"},
+		},
+		{
+			name:     "v1.22.0 key, no schema",
+			attrs:    newAttrs,
+			expected: []string{},
+		},
+		{
+			name:      "v1.22.0 key, invalid schema",
+			attrs:     newAttrs,
+			schemaURL: "https://opentelemetry.io/schemas/latest",
+			expected:  []string{},
+		},
+		{
+			name:      "custom mapping takes precedence",
+			attrs:     newAttrs,
+			schemaURL: "https://opentelemetry.io/schemas/1.22.0",
+			mappings:  []AttributeMapping{{OTLPKey: attributeContainerImageTags, DatadogTag: "image_tags"}},
+			expected:  []string{"image_tags:1.2.3", "image_tags:latest"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TagsFromAttributesVersioned(tt.attrs, tt.schemaURL, tt.mappings))
+		})
+	}
+}
+
+func TestParseSchemaVersion(t *testing.T) {
+	version, ok := parseSchemaVersion("https://opentelemetry.io/schemas/1.21.0")
+	assert.True(t, ok)
+	assert.Equal(t, schemaVersion{1, 21, 0}, version)
+
+	for _, schemaURL := range []string{"", "https://opentelemetry.io/schemas/", "https://opentelemetry.io/schemas/1.21", "1.-1.0"} {
+		_, ok := parseSchemaVersion(schemaURL)
+		assert.False(t, ok, schemaURL)
+	}
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+// SDKMetadata is the metadata of the OpenTelemetry SDK that produced a resource,
+// as described by the telemetry.sdk.* resource attributes.
+type SDKMetadata struct {
+	// Name is the name of the SDK (e.g. "opentelemetry").
+	Name string
+	// Language is the language of the SDK (e.g. "go").
+	Language string
+	// Version is the version of the SDK.
+	Version string
+}
+
+// SDKMetadataFromAttributes extracts the SDK metadata from the telemetry.sdk.* attributes of a resource.
+// The zero value is returned if the resource has none of them.
+func SDKMetadataFromAttributes(attrs pcommon.Map) SDKMetadata {
+	var sdk SDKMetadata
+	if v, ok := attrs.Get(conventions.AttributeTelemetrySDKName); ok {
+		sdk.Name = v.AsString()
+	}
+	if v, ok := attrs.Get(conventions.AttributeTelemetrySDKLanguage); ok {
+		sdk.Language = v.AsString()
+	}
+	if v, ok := attrs.Get(conventions.AttributeTelemetrySDKVersion); ok {
+		sdk.Version = v.AsString()
+	}
+	return sdk
+}
@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+func TestSDKMetadataFromAttributes(t *testing.T) {
+	attrs := pcommon.NewMap()
+	assert.Equal(t, SDKMetadata{}, SDKMetadataFromAttributes(attrs))
+
+	attrs.PutStr(conventions.AttributeTelemetrySDKName, "opentelemetry")
+	attrs.PutStr(conventions.AttributeTelemetrySDKLanguage, "go")
+	attrs.PutStr(conventions.AttributeTelemetrySDKVersion, "1.11.1")
+	attrs.PutStr(conventions.AttributeServiceName, "checkout")
+	assert.Equal(t, SDKMetadata{
+		Name:     "opentelemetry",
+		Language: "go",
+		Version:  "1.11.1",
+	}, SDKMetadataFromAttributes(attrs))
+}
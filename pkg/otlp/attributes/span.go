@@ -0,0 +1,289 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+const (
+	// attributeHTTPRequestMethod and attributeHTTPResponseStatusCode are the newer
+	// semantic conventions for the HTTP method and status code.
+	attributeHTTPRequestMethod      = "http.request.method"
+	attributeHTTPResponseStatusCode = "http.response.status_code"
+)
+
+// spanMapping defines the mapping between OpenTelemetry span attributes
+// and Datadog APM span tags.
+var spanMapping = map[string]string{
+	// HTTP
+	conventions.AttributeHTTPMethod:                "http.method",
+	attributeHTTPRequestMethod:                     "http.method",
+	conventions.AttributeHTTPStatusCode:            "http.status_code",
+	attributeHTTPResponseStatusCode:                "http.status_code",
+	conventions.AttributeHTTPURL:                   "http.url",
+	conventions.AttributeHTTPRoute:                 "http.route",
+	conventions.AttributeHTTPFlavor:                "http.version",
+	conventions.AttributeHTTPUserAgent:             "http.useragent",
+	conventions.AttributeHTTPClientIP:              "http.client_ip",
+	conventions.AttributeHTTPRequestContentLength:  "http.request.content_length",
+	conventions.AttributeHTTPResponseContentLength: "http.response.content_length",
+
+	// RPC
+	conventions.AttributeRPCSystem:         "rpc.system",
+	conventions.AttributeRPCService:        "rpc.service",
+	conventions.AttributeRPCMethod:         "rpc.method",
+	conventions.AttributeRPCGRPCStatusCode: "grpc.code",
+
+	// Database
+	conventions.AttributeDBSystem:       "db.type",
+	conventions.AttributeDBName:         "db.instance",
+	conventions.AttributeDBUser:         "db.user",
+	conventions.AttributeDBOperation:    "db.operation",
+	conventions.AttributeDBSQLTable:     "db.sql.table",
+	conventions.AttributeDBRedisDBIndex: "db.redis.database_index",
+
+	// Messaging
+	conventions.AttributeMessagingSystem:      "messaging.system",
+	conventions.AttributeMessagingDestination: "messaging.destination",
+	conventions.AttributeMessagingOperation:   "messaging.operation",
+	conventions.AttributeMessagingMessageID:   "messaging.message_id",
+
+	// Network
+	conventions.AttributeNetPeerName: "peer.hostname",
+	conventions.AttributeNetPeerPort: "out.port",
+}
+
+// supersededSpanAttributes maps the span attributes of older semantic conventions to the attributes
+// superseding them. Both map to the same Datadog tag: when both are set, the newer attribute is used.
+var supersededSpanAttributes = map[string]string{
+	conventions.AttributeHTTPMethod:     attributeHTTPRequestMethod,
+	conventions.AttributeHTTPStatusCode: attributeHTTPResponseStatusCode,
+}
+
+// grpcSpanMapping defines the additional Datadog APM tags of gRPC spans, used by the gRPC analytics.
+var grpcSpanMapping = map[string]string{
+	conventions.AttributeRPCService: "grpc.service",
+	conventions.AttributeRPCMethod:  "grpc.method",
+}
+
+// grpcStatusCodeNames maps the gRPC status codes to their canonical names.
+var grpcStatusCodeNames = map[string]string{
+	conventions.AttributeRPCGRPCStatusCodeOk:                 "OK",
+	conventions.AttributeRPCGRPCStatusCodeCancelled:          "CANCELLED",
+	conventions.AttributeRPCGRPCStatusCodeUnknown:            "UNKNOWN",
+	conventions.AttributeRPCGRPCStatusCodeInvalidArgument:    "INVALID_ARGUMENT",
+	conventions.AttributeRPCGRPCStatusCodeDeadlineExceeded:   "DEADLINE_EXCEEDED",
+	conventions.AttributeRPCGRPCStatusCodeNotFound:           "NOT_FOUND",
+	conventions.AttributeRPCGRPCStatusCodeAlreadyExists:      "ALREADY_EXISTS",
+	conventions.AttributeRPCGRPCStatusCodePermissionDenied:   "PERMISSION_DENIED",
+	conventions.AttributeRPCGRPCStatusCodeResourceExhausted:  "RESOURCE_EXHAUSTED",
+	conventions.AttributeRPCGRPCStatusCodeFailedPrecondition: "FAILED_PRECONDITION",
+	conventions.AttributeRPCGRPCStatusCodeAborted:            "ABORTED",
+	conventions.AttributeRPCGRPCStatusCodeOutOfRange:         "OUT_OF_RANGE",
+	conventions.AttributeRPCGRPCStatusCodeUnimplemented:      "UNIMPLEMENTED",
+	conventions.AttributeRPCGRPCStatusCodeInternal:           "INTERNAL",
+	conventions.AttributeRPCGRPCStatusCodeUnavailable:        "UNAVAILABLE",
+	conventions.AttributeRPCGRPCStatusCodeDataLoss:           "DATA_LOSS",
+	conventions.AttributeRPCGRPCStatusCodeUnauthenticated:    "UNAUTHENTICATED",
+}
+
+// grpcStatusCodeName returns the canonical name of a gRPC status code.
+// Unknown codes are returned unchanged.
+func grpcStatusCodeName(code string) string {
+	if name, ok := grpcStatusCodeNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// sqlDBSystems contains the database systems whose statements are SQL queries.
+var sqlDBSystems = map[string]struct{}{
+	conventions.AttributeDBSystemOtherSQL:    {},
+	conventions.AttributeDBSystemMSSQL:       {},
+	conventions.AttributeDBSystemMySQL:       {},
+	conventions.AttributeDBSystemOracle:      {},
+	conventions.AttributeDBSystemDB2:         {},
+	conventions.AttributeDBSystemPostgreSQL:  {},
+	conventions.AttributeDBSystemRedshift:    {},
+	conventions.AttributeDBSystemMariaDB:     {},
+	conventions.AttributeDBSystemSqlite:      {},
+	conventions.AttributeDBSystemCockroachdb: {},
+}
+
+// dbTypes maps the database systems whose Datadog db.type differs from the db.system value.
+var dbTypes = map[string]string{
+	conventions.AttributeDBSystemPostgreSQL: "postgres",
+	conventions.AttributeDBSystemMSSQL:      "sqlserver",
+	conventions.AttributeDBSystemOtherSQL:   "sql",
+}
+
+// maxDBStatementLength is the maximum length of a database statement tag.
+const maxDBStatementLength = 5000
+
+// dbStatement returns the value of the statement tag of a database span: the literal values
+// of SQL statements and the arguments of Redis commands are replaced with '?', and statements
+// are truncated to maxDBStatementLength.
+func dbStatement(dbSystem string, statement string) string {
+	if _, ok := sqlDBSystems[dbSystem]; ok {
+		statement = scrubSQL(statement)
+	} else if dbSystem == conventions.AttributeDBSystemRedis {
+		statement = scrubRedis(statement)
+	}
+	return truncateRunes(statement, maxDBStatementLength)
+}
+
+// scrubRedis keeps the name and the key of each command of a Redis statement, and replaces their
+// other arguments with a single '?'. Commands are separated by newlines, e.g. in pipelines.
+// The argument of AUTH is a password rather than a key, so it is replaced as well.
+func scrubRedis(statement string) string {
+	commands := strings.Split(statement, "\n")
+	for i, command := range commands {
+		fields := strings.Fields(command)
+		keep := 2
+		if len(fields) > 0 && strings.EqualFold(fields[0], "AUTH") {
+			keep = 1
+		}
+		if len(fields) > keep {
+			fields = append(fields[:keep], "?")
+		}
+		commands[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(commands, "\n")
+}
+
+// scrubSQL replaces the string and numeric literals of a SQL statement with '?'.
+func scrubSQL(query string) string {
+	var sb strings.Builder
+	sb.Grow(len(query))
+	prevIdentChar := false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			// Skip the string literal, including escaped quotes ('').
+			for i++; i < len(query); i++ {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			i++
+			sb.WriteByte('?')
+			prevIdentChar = false
+		case isDigit(c) && !prevIdentChar:
+			// Skip the numeric literal, including decimals, exponents and hexadecimal digits.
+			for i < len(query) && (isIdentChar(query[i]) || query[i] == '.') {
+				i++
+			}
+			sb.WriteByte('?')
+			prevIdentChar = false
+		default:
+			sb.WriteByte(c)
+			prevIdentChar = isIdentChar(c)
+			i++
+		}
+	}
+	return sb.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentChar checks if a character can be part of a SQL identifier.
+func isIdentChar(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || c >= 0x80
+}
+
+// dbStatementTag returns the Datadog tag key for the statement of a database span.
+func dbStatementTag(dbSystem string) string {
+	if dbSystem == conventions.AttributeDBSystemRedis {
+		return "redis.raw_command"
+	}
+	if _, ok := sqlDBSystems[dbSystem]; ok {
+		return "sql.query"
+	}
+	return "db.statement"
+}
+
+// TagsFromSpanAttributes converts a selected list of span attributes (HTTP, RPC, database,
+// messaging and network semantic conventions) to Datadog APM span tags.
+// Database systems are converted to the Datadog db.type vocabulary (e.g. postgres), and database
+// statements are scrubbed and truncated (see dbStatement). The peer name of database spans is
+// converted to the out.host tag rather than peer.hostname.
+// gRPC spans also get the grpc.service, grpc.method and grpc.status_code tags, the latter
+// holding the canonical name of the status code (e.g. NOT_FOUND).
+// Attributes that are not part of the mapping are ignored.
+func TagsFromSpanAttributes(attrs pcommon.Map) map[string]string {
+	tags := make(map[string]string)
+
+	var dbSystem string
+	if v, ok := attrs.Get(conventions.AttributeDBSystem); ok {
+		dbSystem = v.Str()
+	}
+	var isGRPC bool
+	if v, ok := attrs.Get(conventions.AttributeRPCSystem); ok {
+		isGRPC = v.Str() == "grpc"
+	}
+
+	attrs.Range(func(key string, value pcommon.Value) bool {
+		if newerKey, ok := supersededSpanAttributes[key]; ok {
+			if newerValue, ok := attrs.Get(newerKey); ok && valueString(newerValue) != "" {
+				return true
+			}
+		}
+		datadogKey, found := spanMapping[key]
+		switch key {
+		case conventions.AttributeDBStatement:
+			if statement := dbStatement(dbSystem, valueString(value)); statement != "" {
+				tags[dbStatementTag(dbSystem)] = statement
+			}
+			return true
+		case conventions.AttributeDBSystem:
+			if dbType, ok := dbTypes[dbSystem]; ok {
+				tags[datadogKey] = dbType
+				return true
+			}
+		case conventions.AttributeNetPeerName:
+			if dbSystem != "" {
+				// The Datadog database analytics expect the host of the database in out.host.
+				datadogKey = "out.host"
+			}
+		}
+		if isGRPC {
+			addGRPCTag(tags, key, value)
+		}
+		if !found {
+			return true
+		}
+		if tagValue := valueString(value); tagValue != "" {
+			tags[datadogKey] = tagValue
+		}
+		return true
+	})
+
+	return tags
+}
+
+// addGRPCTag adds the additional gRPC tag of a span attribute, if any.
+func addGRPCTag(tags map[string]string, key string, value pcommon.Value) {
+	tagValue := valueString(value)
+	if tagValue == "" {
+		return
+	}
+	if key == conventions.AttributeRPCGRPCStatusCode {
+		tags["grpc.status_code"] = grpcStatusCodeName(tagValue)
+	} else if datadogKey, ok := grpcSpanMapping[key]; ok {
+		tags[datadogKey] = tagValue
+	}
+}
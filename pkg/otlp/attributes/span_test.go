@@ -0,0 +1,336 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+func TestTagsFromSpanAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    map[string]interface{}
+		expected map[string]string
+	}{
+		{
+			name: "HTTP",
+			attrs: map[string]interface{}{
+				conventions.AttributeHTTPMethod:     "GET",
+				conventions.AttributeHTTPStatusCode: 200,
+				conventions.AttributeHTTPURL:        "https://example.com/users/1",
+				conventions.AttributeHTTPRoute:      "/users/:id",
+				conventions.AttributeNetPeerName:    "example.com",
+				conventions.AttributeNetPeerPort:    443,
+			},
+			expected: map[string]string{
+				"http.method":      "GET",
+				"http.status_code": "200",
+				"http.url":         "https://example.com/users/1",
+				"http.route":       "/users/:id",
+				"peer.hostname":    "example.com",
+				"out.port":         "443",
+			},
+		},
+		{
+			name: "HTTP server",
+			attrs: map[string]interface{}{
+				conventions.AttributeHTTPMethod:                "POST",
+				conventions.AttributeHTTPStatusCode:            500,
+				conventions.AttributeHTTPScheme:                "https",
+				conventions.AttributeHTTPTarget:                "/checkout?cart=42",
+				conventions.AttributeHTTPHost:                  "shop.example.com",
+				conventions.AttributeHTTPServerName:            "shop",
+				conventions.AttributeHTTPRoute:                 "/checkout",
+				conventions.AttributeHTTPFlavor:                conventions.AttributeHTTPFlavorHTTP11,
+				conventions.AttributeHTTPUserAgent:             "Mozilla/5.0",
+				conventions.AttributeHTTPClientIP:              "203.0.113.7",
+				conventions.AttributeHTTPRequestContentLength:  512,
+				conventions.AttributeHTTPResponseContentLength: 64,
+				conventions.AttributeNetHostName:               "shop.example.com",
+				conventions.AttributeNetHostPort:               8443,
+				conventions.AttributeNetPeerIP:                 "10.0.0.4",
+				conventions.AttributeNetPeerPort:               52311,
+			},
+			expected: map[string]string{
+				"http.method":                  "POST",
+				"http.status_code":             "500",
+				"http.route":                   "/checkout",
+				"http.version":                 "1.1",
+				"http.useragent":               "Mozilla/5.0",
+				"http.client_ip":               "203.0.113.7",
+				"http.request.content_length":  "512",
+				"http.response.content_length": "64",
+				"out.port":                     "52311",
+			},
+		},
+		{
+			name: "HTTP newer conventions",
+			attrs: map[string]interface{}{
+				"http.request.method":       "POST",
+				"http.response.status_code": 201,
+			},
+			expected: map[string]string{
+				"http.method":      "POST",
+				"http.status_code": "201",
+			},
+		},
+		{
+			name: "HTTP older and newer conventions",
+			attrs: map[string]interface{}{
+				conventions.AttributeHTTPMethod:     "GET",
+				"http.request.method":               "POST",
+				conventions.AttributeHTTPStatusCode: 200,
+				"http.response.status_code":         201,
+			},
+			expected: map[string]string{
+				"http.method":      "POST",
+				"http.status_code": "201",
+			},
+		},
+		{
+			name: "HTTP empty newer conventions",
+			attrs: map[string]interface{}{
+				conventions.AttributeHTTPMethod: "GET",
+				"http.request.method":           "",
+			},
+			expected: map[string]string{
+				"http.method": "GET",
+			},
+		},
+		{
+			name: "gRPC",
+			attrs: map[string]interface{}{
+				conventions.AttributeRPCSystem:         "grpc",
+				conventions.AttributeRPCService:        "helloworld.Greeter",
+				conventions.AttributeRPCMethod:         "SayHello",
+				conventions.AttributeRPCGRPCStatusCode: 0,
+			},
+			expected: map[string]string{
+				"rpc.system":       "grpc",
+				"rpc.service":      "helloworld.Greeter",
+				"rpc.method":       "SayHello",
+				"grpc.code":        "0",
+				"grpc.service":     "helloworld.Greeter",
+				"grpc.method":      "SayHello",
+				"grpc.status_code": "OK",
+			},
+		},
+		{
+			name: "gRPC server error",
+			attrs: map[string]interface{}{
+				conventions.AttributeRPCSystem:         "grpc",
+				conventions.AttributeRPCService:        "shop.v1.CheckoutService",
+				conventions.AttributeRPCMethod:         "PlaceOrder",
+				conventions.AttributeRPCGRPCStatusCode: 14,
+				conventions.AttributeNetPeerIP:         "10.0.0.4",
+				conventions.AttributeNetPeerPort:       52311,
+				conventions.AttributeNetHostName:       "checkout",
+			},
+			expected: map[string]string{
+				"rpc.system":       "grpc",
+				"rpc.service":      "shop.v1.CheckoutService",
+				"rpc.method":       "PlaceOrder",
+				"grpc.code":        "14",
+				"grpc.service":     "shop.v1.CheckoutService",
+				"grpc.method":      "PlaceOrder",
+				"grpc.status_code": "UNAVAILABLE",
+				"out.port":         "52311",
+			},
+		},
+		{
+			name: "gRPC unknown status code",
+			attrs: map[string]interface{}{
+				conventions.AttributeRPCSystem:         "grpc",
+				conventions.AttributeRPCGRPCStatusCode: 42,
+			},
+			expected: map[string]string{
+				"rpc.system":       "grpc",
+				"grpc.code":        "42",
+				"grpc.status_code": "42",
+			},
+		},
+		{
+			name: "other RPC system",
+			attrs: map[string]interface{}{
+				conventions.AttributeRPCSystem:  "java_rmi",
+				conventions.AttributeRPCService: "example.Greeter",
+				conventions.AttributeRPCMethod:  "greet",
+			},
+			expected: map[string]string{
+				"rpc.system":  "java_rmi",
+				"rpc.service": "example.Greeter",
+				"rpc.method":  "greet",
+			},
+		},
+		{
+			name: "SQL",
+			attrs: map[string]interface{}{
+				conventions.AttributeDBSystem:    conventions.AttributeDBSystemPostgreSQL,
+				conventions.AttributeDBName:      "customers",
+				conventions.AttributeDBUser:      "readonly",
+				conventions.AttributeDBStatement: "SELECT * FROM users WHERE id = ?",
+				conventions.AttributeDBOperation: "SELECT",
+			},
+			expected: map[string]string{
+				"db.type":      "postgres",
+				"db.instance":  "customers",
+				"db.user":      "readonly",
+				"sql.query":    "SELECT * FROM users WHERE id = ?",
+				"db.operation": "SELECT",
+			},
+		},
+		{
+			name: "PostgreSQL",
+			attrs: map[string]interface{}{
+				conventions.AttributeDBSystem:    conventions.AttributeDBSystemPostgreSQL,
+				conventions.AttributeDBName:      "shop",
+				conventions.AttributeDBStatement: "UPDATE orders SET status = 'shipped', total = 12.5 WHERE id = 42",
+				conventions.AttributeDBOperation: "UPDATE",
+				conventions.AttributeDBSQLTable:  "orders",
+				conventions.AttributeNetPeerName: "db.internal",
+				conventions.AttributeNetPeerPort: 5432,
+			},
+			expected: map[string]string{
+				"db.type":      "postgres",
+				"db.instance":  "shop",
+				"sql.query":    "UPDATE orders SET status = ?, total = ? WHERE id = ?",
+				"db.operation": "UPDATE",
+				"db.sql.table": "orders",
+				"out.host":     "db.internal",
+				"out.port":     "5432",
+			},
+		},
+		{
+			name: "MySQL",
+			attrs: map[string]interface{}{
+				conventions.AttributeDBSystem:    conventions.AttributeDBSystemMySQL,
+				conventions.AttributeDBName:      "users",
+				conventions.AttributeDBStatement: "SELECT name FROM accounts WHERE email = 'o''brien@example.com' AND id IN (1, 2)",
+				conventions.AttributeDBSQLTable:  "accounts",
+				conventions.AttributeNetPeerName: "mysql.internal",
+			},
+			expected: map[string]string{
+				"db.type":      "mysql",
+				"db.instance":  "users",
+				"sql.query":    "SELECT name FROM accounts WHERE email = ? AND id IN (?, ?)",
+				"db.sql.table": "accounts",
+				"out.host":     "mysql.internal",
+			},
+		},
+		{
+			name: "truncated statement",
+			attrs: map[string]interface{}{
+				conventions.AttributeDBSystem:    conventions.AttributeDBSystemCassandra,
+				conventions.AttributeDBStatement: strings.Repeat("a", maxDBStatementLength+10),
+			},
+			expected: map[string]string{
+				"db.type":      "cassandra",
+				"db.statement": strings.Repeat("a", maxDBStatementLength),
+			},
+		},
+		{
+			name: "Redis",
+			attrs: map[string]interface{}{
+				conventions.AttributeDBSystem:       conventions.AttributeDBSystemRedis,
+				conventions.AttributeDBStatement:    "SET session:1 s3cr3t EX 60",
+				conventions.AttributeDBRedisDBIndex: 3,
+				conventions.AttributeNetPeerName:    "cache.internal",
+				conventions.AttributeNetPeerPort:    6379,
+			},
+			expected: map[string]string{
+				"db.type":                 "redis",
+				"redis.raw_command":       "SET session:1 ?",
+				"db.redis.database_index": "3",
+				"out.host":                "cache.internal",
+				"out.port":                "6379",
+			},
+		},
+		{
+			name: "other database",
+			attrs: map[string]interface{}{
+				conventions.AttributeDBSystem:    conventions.AttributeDBSystemMongoDB,
+				conventions.AttributeDBStatement: `{"find": "users"}`,
+			},
+			expected: map[string]string{
+				"db.type":      "mongodb",
+				"db.statement": `{"find": "users"}`,
+			},
+		},
+		{
+			name: "messaging",
+			attrs: map[string]interface{}{
+				conventions.AttributeMessagingSystem:      "kafka",
+				conventions.AttributeMessagingDestination: "orders",
+				conventions.AttributeMessagingOperation:   "process",
+				conventions.AttributeMessagingMessageID:   "5fa1",
+			},
+			expected: map[string]string{
+				"messaging.system":      "kafka",
+				"messaging.destination": "orders",
+				"messaging.operation":   "process",
+				"messaging.message_id":  "5fa1",
+			},
+		},
+		{
+			name: "unmapped and empty attributes",
+			attrs: map[string]interface{}{
+				"custom.attribute":           "value",
+				conventions.AttributeHTTPURL: "",
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			attrs.FromRaw(testInstance.attrs)
+			assert.Equal(t, testInstance.expected, TagsFromSpanAttributes(attrs))
+		})
+	}
+}
+
+func TestScrubSQL(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "SELECT * FROM users", expected: "SELECT * FROM users"},
+		{query: "SELECT * FROM users WHERE name = 'alice'", expected: "SELECT * FROM users WHERE name = ?"},
+		{query: "SELECT * FROM users WHERE name = 'o''brien'", expected: "SELECT * FROM users WHERE name = ?"},
+		{query: "SELECT * FROM t1 WHERE score > 1.5e3 AND flags = 0x1F", expected: "SELECT * FROM t1 WHERE score > ? AND flags = ?"},
+		{query: `SELECT "col2" FROM table_3 LIMIT 10`, expected: `SELECT "col2" FROM table_3 LIMIT ?`},
+		{query: "INSERT INTO t VALUES ('unterminated", expected: "INSERT INTO t VALUES (?"},
+	}
+
+	for _, testInstance := range tests {
+		assert.Equal(t, testInstance.expected, scrubSQL(testInstance.query), testInstance.query)
+	}
+}
+
+func TestScrubRedis(t *testing.T) {
+	tests := []struct {
+		statement string
+		expected  string
+	}{
+		{statement: "PING", expected: "PING"},
+		{statement: "HGETALL user:1", expected: "HGETALL user:1"},
+		{statement: "SET session:1 s3cr3t", expected: "SET session:1 ?"},
+		{statement: "HSET user:1 name alice email alice@example.com", expected: "HSET user:1 ?"},
+		{statement: "auth s3cr3t", expected: "auth ?"},
+		{statement: "AUTH admin s3cr3t", expected: "AUTH ?"},
+		{statement: "SET a 1\nSET b  2\nGET a", expected: "SET a ?\nSET b ?\nGET a"},
+	}
+
+	for _, testInstance := range tests {
+		assert.Equal(t, testInstance.expected, scrubRedis(testInstance.statement), testInstance.statement)
+	}
+}
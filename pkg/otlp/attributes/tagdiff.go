@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// TagChange is a tag whose value changed between two attribute maps.
+type TagChange struct {
+	Key      string
+	OldValue string
+	NewValue string
+}
+
+// TagChangeset is the difference between the tags of two attribute maps.
+type TagChangeset struct {
+	// Added are the key:value tags of the keys only present in the current map.
+	Added []string
+	// Removed are the key:value tags of the keys only present in the previous map.
+	Removed []string
+	// Changed are the keys present in both maps with different values.
+	Changed []TagChange
+}
+
+// TagDiff computes the tags added, removed and changed between two snapshots of an attribute map,
+// for example the resource attributes of a pod. Every attribute is considered as a tag with its key.
+// The changes are sorted by key.
+func TagDiff(prev, curr pcommon.Map) TagChangeset {
+	var changeset TagChangeset
+	prev.Range(func(key string, prevValue pcommon.Value) bool {
+		currValue, ok := curr.Get(key)
+		if !ok {
+			changeset.Removed = append(changeset.Removed, key+":"+valueString(prevValue))
+		} else if oldValue, newValue := valueString(prevValue), valueString(currValue); oldValue != newValue {
+			changeset.Changed = append(changeset.Changed, TagChange{Key: key, OldValue: oldValue, NewValue: newValue})
+		}
+		return true
+	})
+	curr.Range(func(key string, currValue pcommon.Value) bool {
+		if _, ok := prev.Get(key); !ok {
+			changeset.Added = append(changeset.Added, key+":"+valueString(currValue))
+		}
+		return true
+	})
+	sort.Strings(changeset.Added)
+	sort.Strings(changeset.Removed)
+	sort.Slice(changeset.Changed, func(i, j int) bool {
+		return changeset.Changed[i].Key < changeset.Changed[j].Key
+	})
+	return changeset
+}
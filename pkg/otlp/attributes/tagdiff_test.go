@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestTagDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev      map[string]interface{}
+		curr      map[string]interface{}
+		changeset TagChangeset
+	}{
+		{
+			name: "no change",
+			prev: map[string]interface{}{"k8s.pod.name": "checkout", "k8s.pod.labels.app": "checkout"},
+			curr: map[string]interface{}{"k8s.pod.labels.app": "checkout", "k8s.pod.name": "checkout"},
+		},
+		{
+			name: "added",
+			prev: map[string]interface{}{"k8s.pod.name": "checkout"},
+			curr: map[string]interface{}{"k8s.pod.name": "checkout", "k8s.pod.labels.version": "1.2.3", "k8s.pod.labels.app": "checkout"},
+			changeset: TagChangeset{
+				Added: []string{"k8s.pod.labels.app:checkout", "k8s.pod.labels.version:1.2.3"},
+			},
+		},
+		{
+			name: "removed",
+			prev: map[string]interface{}{"k8s.pod.name": "checkout", "k8s.pod.labels.app": "checkout"},
+			curr: map[string]interface{}{"k8s.pod.name": "checkout"},
+			changeset: TagChangeset{
+				Removed: []string{"k8s.pod.labels.app:checkout"},
+			},
+		},
+		{
+			name: "changed",
+			prev: map[string]interface{}{"k8s.pod.name": "checkout", "process.pid": 42},
+			curr: map[string]interface{}{"k8s.pod.name": "checkout", "process.pid": 43},
+			changeset: TagChangeset{
+				Changed: []TagChange{{Key: "process.pid", OldValue: "42", NewValue: "43"}},
+			},
+		},
+		{
+			name: "all change types",
+			prev: map[string]interface{}{"k8s.pod.labels.b": "1", "k8s.pod.labels.a": "1", "k8s.pod.labels.removed": "x"},
+			curr: map[string]interface{}{"k8s.pod.labels.b": "2", "k8s.pod.labels.a": "2", "k8s.pod.labels.added": "y"},
+			changeset: TagChangeset{
+				Added:   []string{"k8s.pod.labels.added:y"},
+				Removed: []string{"k8s.pod.labels.removed:x"},
+				Changed: []TagChange{
+					{Key: "k8s.pod.labels.a", OldValue: "1", NewValue: "2"},
+					{Key: "k8s.pod.labels.b", OldValue: "1", NewValue: "2"},
+				},
+			},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			prev, curr := pcommon.NewMap(), pcommon.NewMap()
+			assert.NoError(t, prev.FromRaw(testInstance.prev))
+			assert.NoError(t, curr.FromRaw(testInstance.curr))
+			assert.Equal(t, testInstance.changeset, TagDiff(prev, curr))
+		})
+	}
+}
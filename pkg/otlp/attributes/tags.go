@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxTagLength is the maximum length, in characters, of a Datadog tag (`key:value`).
+const maxTagLength = 200
+
+// TagNormalizationError reports the Datadog tag constraints violated by a tag.
+type TagNormalizationError struct {
+	// Key is the original tag key.
+	Key string
+	// Value is the original tag value.
+	Value string
+	// Violations describes each violated constraint.
+	Violations []string
+}
+
+// Error implements the error interface.
+func (e *TagNormalizationError) Error() string {
+	return fmt.Sprintf("invalid tag %q:%q: %s", e.Key, e.Value, strings.Join(e.Violations, "; "))
+}
+
+// isValidTagChar checks if a character is allowed in a Datadog tag key.
+// Colons are also allowed in tag values.
+func isValidTagChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == '/'
+}
+
+// normalizeTagPart lowercases s and replaces the characters that are not allowed by underscores.
+// It reports whether any character was replaced.
+func normalizeTagPart(s string, allowColon bool) (string, bool) {
+	replaced := false
+	normalized := strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || !(isValidTagChar(r) || (allowColon && r == ':')) {
+			replaced = true
+			return '_'
+		}
+		return r
+	}, strings.ToLower(s))
+	return normalized, replaced
+}
+
+// NormalizeTag makes a tag follow the Datadog tag constraints, and returns the normalized key and value.
+// Tags are lowercased, keys must start with a letter and must not contain colons, keys and values
+// may only contain letters, digits, underscores, minuses, periods and slashes (and colons for values),
+// and the whole `key:value` tag must be at most 200 characters long.
+// Lowercasing is only a recommendation: every other violated constraint is reported in a
+// *TagNormalizationError, along with the original key and value, so that callers can decide
+// whether to use the normalized tag or to skip it. A tag with no valid key character can't be normalized,
+// in which case empty strings are returned.
+func NormalizeTag(key, value string) (string, string, error) {
+	var violations []string
+
+	normalizedKey := strings.TrimLeftFunc(key, func(r rune) bool { return !unicode.IsLetter(r) })
+	if normalizedKey == "" {
+		violations = append(violations, "key must contain a letter")
+		return "", "", &TagNormalizationError{Key: key, Value: value, Violations: violations}
+	}
+	if len(normalizedKey) != len(key) {
+		violations = append(violations, "key must start with a letter")
+	}
+	if strings.Contains(normalizedKey, ":") {
+		violations = append(violations, "key must not contain colons")
+	}
+	normalizedKey, replaced := normalizeTagPart(normalizedKey, false)
+	if replaced {
+		violations = append(violations, "key must only contain letters, digits, underscores, minuses, periods and slashes")
+	}
+	normalizedValue, replaced := normalizeTagPart(value, true)
+	if replaced {
+		violations = append(violations, "value must only contain letters, digits, underscores, minuses, periods, slashes and colons")
+	}
+
+	keyLength := utf8.RuneCountInString(normalizedKey)
+	valueLength := utf8.RuneCountInString(normalizedValue)
+	if keyLength+1+valueLength > maxTagLength {
+		violations = append(violations, fmt.Sprintf("tag must be at most %d characters long", maxTagLength))
+		// Truncate the value first, then the key.
+		if keyLength >= maxTagLength-1 {
+			normalizedKey = truncateRunes(normalizedKey, maxTagLength-1)
+			normalizedValue = ""
+		} else {
+			normalizedValue = truncateRunes(normalizedValue, maxTagLength-1-keyLength)
+		}
+	}
+
+	if len(violations) > 0 {
+		return normalizedKey, normalizedValue, &TagNormalizationError{Key: key, Value: value, Violations: violations}
+	}
+	return normalizedKey, normalizedValue, nil
+}
+
+// truncateRunes truncates s to at most n characters.
+func truncateRunes(s string, n int) string {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
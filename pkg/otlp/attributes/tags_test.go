@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		value      string
+		outKey     string
+		outValue   string
+		violations []string
+	}{
+		{
+			name:     "valid",
+			key:      "env",
+			value:    "prod",
+			outKey:   "env",
+			outValue: "prod",
+		},
+		{
+			name:     "lowercased",
+			key:      "Service",
+			value:    "Checkout",
+			outKey:   "service",
+			outValue: "checkout",
+		},
+		{
+			name:     "unicode letters",
+			key:      "région",
+			value:    "île-de-france/東京:1",
+			outKey:   "région",
+			outValue: "île-de-france/東京:1",
+		},
+		{
+			name:       "leading non-letters",
+			key:        "_1team",
+			value:      "a",
+			outKey:     "team",
+			outValue:   "a",
+			violations: []string{"key must start with a letter"},
+		},
+		{
+			name:     "colon and invalid characters",
+			key:      "http:method",
+			value:    "GET /users?id=1",
+			outKey:   "http_method",
+			outValue: "get_/users_id_1",
+			violations: []string{
+				"key must not contain colons",
+				"key must only contain letters, digits, underscores, minuses, periods and slashes",
+				"value must only contain letters, digits, underscores, minuses, periods, slashes and colons",
+			},
+		},
+		{
+			name:       "no letter",
+			key:        "123",
+			value:      "a",
+			violations: []string{"key must contain a letter"},
+		},
+		{
+			name:       "too long value",
+			key:        "key",
+			value:      strings.Repeat("é", 300),
+			outKey:     "key",
+			outValue:   strings.Repeat("é", 196),
+			violations: []string{"tag must be at most 200 characters long"},
+		},
+		{
+			name:       "too long key",
+			key:        strings.Repeat("k", 300),
+			value:      "value",
+			outKey:     strings.Repeat("k", 199),
+			violations: []string{"tag must be at most 200 characters long"},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			key, value, err := NormalizeTag(testInstance.key, testInstance.value)
+			assert.Equal(t, testInstance.outKey, key)
+			assert.Equal(t, testInstance.outValue, value)
+			if len(testInstance.violations) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var tagErr *TagNormalizationError
+			require.ErrorAs(t, err, &tagErr)
+			assert.Equal(t, testInstance.key, tagErr.Key)
+			assert.Equal(t, testInstance.value, tagErr.Value)
+			assert.Equal(t, testInstance.violations, tagErr.Violations)
+		})
+	}
+}
+
+func FuzzNormalizeTag(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"env", "prod"},
+		{"İstanbul", "ǅ"},
+		{"ß:ẞ", "ﬁ̇"},
+		{"\xff\xfekey", "\xc3\x28"},
+		{"😀emoji", "🏳️‍🌈"},
+		{"key​", "value\u0000"},
+		{"١٢", "٣"},
+		{strings.Repeat("ǆ", 150), strings.Repeat("ß", 150)},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+
+	f.Fuzz(func(t *testing.T, key, value string) {
+		normalizedKey, normalizedValue, err := NormalizeTag(key, value)
+		if normalizedKey == "" {
+			require.Error(t, err)
+			assert.Empty(t, normalizedValue)
+			return
+		}
+
+		assert.True(t, utf8.ValidString(normalizedKey) && utf8.ValidString(normalizedValue))
+		assert.LessOrEqual(t, utf8.RuneCountInString(normalizedKey)+1+utf8.RuneCountInString(normalizedValue), maxTagLength)
+		first, _ := utf8.DecodeRuneInString(normalizedKey)
+		assert.True(t, unicode.IsLetter(first), "key %q must start with a letter", normalizedKey)
+		assert.NotContains(t, normalizedKey, ":")
+		for _, r := range normalizedKey + normalizedValue {
+			assert.True(t, isValidTagChar(r) || r == ':', "invalid character %q", r)
+		}
+
+		// Normalizing a normalized tag is a no-op.
+		key2, value2, err := NormalizeTag(normalizedKey, normalizedValue)
+		assert.NoError(t, err)
+		assert.Equal(t, normalizedKey, key2)
+		assert.Equal(t, normalizedValue, value2)
+	})
+}
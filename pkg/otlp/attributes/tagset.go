@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import "go.opentelemetry.io/collector/pdata/pcommon"
+
+// TagSet is a set of `key:value` tags with constant time membership tests, additions and removals.
+// The zero value is an empty set ready to use. A TagSet must not be copied after first use,
+// since copies share the underlying index: pass *TagSet instead.
+type TagSet struct {
+	tags []string
+	// index maps each tag to its position in tags.
+	index map[string]int
+}
+
+// TagSetFromSlice creates a TagSet from the given tags. Duplicate tags are only kept once.
+func TagSetFromSlice(tags []string) *TagSet {
+	ts := &TagSet{
+		tags:  make([]string, 0, len(tags)),
+		index: make(map[string]int, len(tags)),
+	}
+	for _, tag := range tags {
+		ts.Add(tag)
+	}
+	return ts
+}
+
+// TagSetFromAttributes is like TagsFromAttributes, but returns the tags as a TagSet.
+func TagSetFromAttributes(attrs pcommon.Map, opts ...TagOption) *TagSet {
+	return TagSetFromSlice(TagsFromAttributes(attrs, opts...))
+}
+
+// Len returns the number of tags in the set.
+func (ts *TagSet) Len() int {
+	return len(ts.tags)
+}
+
+// Contains reports whether the tag is in the set.
+func (ts *TagSet) Contains(tag string) bool {
+	_, ok := ts.index[tag]
+	return ok
+}
+
+// Add adds the tag to the set, unless it is already present. It reports whether the tag was added.
+func (ts *TagSet) Add(tag string) bool {
+	if ts.Contains(tag) {
+		return false
+	}
+	if ts.index == nil {
+		ts.index = make(map[string]int)
+	}
+	ts.index[tag] = len(ts.tags)
+	ts.tags = append(ts.tags, tag)
+	return true
+}
+
+// Remove removes the tag from the set. It reports whether the tag was present.
+// The last tag of the set takes the position of the removed one.
+func (ts *TagSet) Remove(tag string) bool {
+	i, ok := ts.index[tag]
+	if !ok {
+		return false
+	}
+	last := len(ts.tags) - 1
+	ts.tags[i] = ts.tags[last]
+	ts.index[ts.tags[i]] = i
+	ts.tags = ts.tags[:last]
+	delete(ts.index, tag)
+	return true
+}
+
+// ToSlice returns a copy of the tags in the set, in insertion order unless tags were removed.
+func (ts *TagSet) ToSlice() []string {
+	tags := make([]string, len(ts.tags))
+	copy(tags, ts.tags)
+	return tags
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package attributes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+func TestTagSet(t *testing.T) {
+	ts := TagSetFromSlice([]string{"env:prod", "service:checkout", "env:prod", "version:1.0"})
+	assert.Equal(t, 3, ts.Len())
+	assert.Equal(t, []string{"env:prod", "service:checkout", "version:1.0"}, ts.ToSlice())
+	assert.True(t, ts.Contains("service:checkout"))
+	assert.False(t, ts.Contains("service"))
+
+	assert.False(t, ts.Add("env:prod"))
+	assert.True(t, ts.Add("region:eu"))
+	assert.True(t, ts.Contains("region:eu"))
+
+	assert.True(t, ts.Remove("env:prod"))
+	assert.False(t, ts.Remove("env:prod"))
+	assert.False(t, ts.Contains("env:prod"))
+	// The last tag takes the position of the removed one.
+	assert.Equal(t, []string{"region:eu", "service:checkout", "version:1.0"}, ts.ToSlice())
+
+	assert.True(t, ts.Remove("version:1.0"))
+	assert.True(t, ts.Add("env:prod"))
+	assert.Equal(t, []string{"region:eu", "service:checkout", "env:prod"}, ts.ToSlice())
+}
+
+func TestTagSetZeroValue(t *testing.T) {
+	var ts TagSet
+	assert.Equal(t, 0, ts.Len())
+	assert.False(t, ts.Contains("env:prod"))
+	assert.False(t, ts.Remove("env:prod"))
+	assert.Equal(t, []string{}, ts.ToSlice())
+
+	assert.True(t, ts.Add("env:prod"))
+	assert.Equal(t, []string{"env:prod"}, ts.ToSlice())
+}
+
+func TestTagSetToSliceCopy(t *testing.T) {
+	ts := TagSetFromSlice([]string{"env:prod"})
+	tags := ts.ToSlice()
+	tags[0] = "env:dev"
+	assert.True(t, ts.Contains("env:prod"))
+	assert.Equal(t, []string{"env:prod"}, ts.ToSlice())
+}
+
+func TestTagSetSharedReference(t *testing.T) {
+	ts := TagSetFromSlice([]string{"env:prod", "service:checkout"})
+	other := ts
+	assert.True(t, other.Remove("env:prod"))
+	assert.True(t, other.Add("version:1.0"))
+
+	// Both references see the same, consistent set.
+	assert.Equal(t, 2, ts.Len())
+	assert.False(t, ts.Contains("env:prod"))
+	assert.True(t, ts.Contains("version:1.0"))
+	assert.Equal(t, []string{"service:checkout", "version:1.0"}, ts.ToSlice())
+}
+
+func TestTagSetFromAttributes(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.FromRaw(map[string]interface{}{
+		conventions.AttributeDeploymentEnvironment: "prod",
+		conventions.AttributeServiceName:           "checkout",
+	})
+
+	ts := TagSetFromAttributes(attrs)
+	assert.ElementsMatch(t, TagsFromAttributes(attrs), ts.ToSlice())
+	assert.True(t, ts.Contains("env:prod"))
+	assert.True(t, ts.Contains("service:checkout"))
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// MergePrecedence is the level of attributes that wins when the resource, scope and datapoint
+// attributes merged by an AttributeMerger have the same key.
+type MergePrecedence string
+
+const (
+	// MergePrecedenceDatapoint gives precedence to the datapoint attributes, then to the scope attributes.
+	// The most specific value wins.
+	MergePrecedenceDatapoint MergePrecedence = "datapoint"
+
+	// MergePrecedenceResource gives precedence to the resource attributes, then to the scope attributes.
+	// This prevents datapoint attributes from overriding the attributes set by the resource detection.
+	MergePrecedenceResource MergePrecedence = "resource"
+)
+
+// AttributeMerger merges the resource, scope and datapoint attributes of a datapoint.
+type AttributeMerger struct {
+	precedence MergePrecedence
+}
+
+// NewAttributeMerger creates an AttributeMerger resolving key conflicts with the given precedence.
+func NewAttributeMerger(precedence MergePrecedence) AttributeMerger {
+	return AttributeMerger{precedence: precedence}
+}
+
+// Precedence of the merger.
+func (m AttributeMerger) Precedence() MergePrecedence {
+	return m.precedence
+}
+
+// Merge returns a new map with the attributes of the three levels. On key conflicts,
+// the value of the level with the highest precedence is kept. The given maps are not modified.
+func (m AttributeMerger) Merge(resource, scope, datapoint pcommon.Map) pcommon.Map {
+	merged := pcommon.NewMap()
+	merged.EnsureCapacity(resource.Len() + scope.Len() + datapoint.Len())
+	levels := []pcommon.Map{resource, scope, datapoint}
+	if m.precedence == MergePrecedenceResource {
+		levels = []pcommon.Map{datapoint, scope, resource}
+	}
+	// Levels are copied from the lowest to the highest precedence, so that the latter overrides the former.
+	for _, attrs := range levels {
+		attrs.Range(func(key string, value pcommon.Value) bool {
+			value.CopyTo(merged.PutEmpty(key))
+			return true
+		})
+	}
+	return merged
+}
+
+// mergedAttributesKey is the context key of the resource and scope attributes merged into datapoint attributes.
+type mergedAttributesKey struct{}
+
+// mergedAttributes are the resource and scope attributes of the datapoints being mapped.
+type mergedAttributes struct {
+	resource pcommon.Map
+	scope    pcommon.Map
+}
+
+// contextWithMergedAttributes returns a context carrying the resource and scope attributes
+// to merge into the datapoint attributes.
+func contextWithMergedAttributes(ctx context.Context, resource, scope pcommon.Map) context.Context {
+	return context.WithValue(ctx, mergedAttributesKey{}, mergedAttributes{resource: resource, scope: scope})
+}
+
+// mergeAttributes merges the resource and scope attributes carried by the context into the
+// given datapoint attributes, if an attribute merger is set.
+func (t *Translator) mergeAttributes(ctx context.Context, attrs pcommon.Map) pcommon.Map {
+	if t.cfg.attributeMerger == nil {
+		return attrs
+	}
+	levels, ok := ctx.Value(mergedAttributesKey{}).(mergedAttributes)
+	if !ok {
+		return attrs
+	}
+	return t.cfg.attributeMerger.Merge(levels.resource, levels.scope, attrs)
+}
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestAttributeMergerMerge(t *testing.T) {
+	resource := pcommon.NewMap()
+	resource.FromRaw(map[string]interface{}{"region": "eu", "team": "resource", "tier": "resource"})
+	scope := pcommon.NewMap()
+	scope.FromRaw(map[string]interface{}{"library": "otel", "team": "scope", "tier": "scope"})
+	datapoint := pcommon.NewMap()
+	datapoint.FromRaw(map[string]interface{}{"route": "/", "team": "datapoint"})
+
+	tests := []struct {
+		precedence MergePrecedence
+		expected   map[string]interface{}
+	}{
+		{
+			precedence: MergePrecedenceDatapoint,
+			expected: map[string]interface{}{
+				"region":  "eu",
+				"library": "otel",
+				"route":   "/",
+				"team":    "datapoint",
+				"tier":    "scope",
+			},
+		},
+		{
+			precedence: MergePrecedenceResource,
+			expected: map[string]interface{}{
+				"region":  "eu",
+				"library": "otel",
+				"route":   "/",
+				"team":    "resource",
+				"tier":    "resource",
+			},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(string(testInstance.precedence), func(t *testing.T) {
+			merger := NewAttributeMerger(testInstance.precedence)
+			assert.Equal(t, testInstance.precedence, merger.Precedence())
+			assert.Equal(t, testInstance.expected, merger.Merge(resource, scope, datapoint).AsRaw())
+			// The given maps are not modified.
+			assert.Equal(t, 3, resource.Len())
+			assert.Equal(t, 3, scope.Len())
+			assert.Equal(t, 2, datapoint.Len())
+		})
+	}
+}
+
+func TestMapMetricsAttributeMerger(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("deployment.environment", "prod")
+	rm.Resource().Attributes().PutStr("team", "platform")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().Attributes().PutStr("library", "otelhttp")
+	met := sm.Metrics().AppendEmpty()
+	met.SetName("http.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("team", "payments")
+
+	tests := []struct {
+		name     string
+		options  []TranslatorOption
+		expected []string
+	}{
+		{
+			name:     "no merger",
+			expected: []string{"env:prod", "team:payments"},
+		},
+		{
+			name:     "datapoint precedence",
+			options:  []TranslatorOption{WithAttributeMerger(NewAttributeMerger(MergePrecedenceDatapoint))},
+			expected: []string{"env:prod", "deployment.environment:prod", "library:otelhttp", "team:payments"},
+		},
+		{
+			name:     "resource precedence",
+			options:  []TranslatorOption{WithAttributeMerger(NewAttributeMerger(MergePrecedenceResource))},
+			expected: []string{"env:prod", "deployment.environment:prod", "library:otelhttp", "team:platform"},
+		},
+		{
+			name: "deny list applies to merged attributes",
+			options: []TranslatorOption{
+				WithAttributeMerger(NewAttributeMerger(MergePrecedenceDatapoint)),
+				WithAttributeDenyList("deployment.environment", "library"),
+			},
+			expected: []string{"team:payments"},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), testInstance.options...)
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+			require.Len(t, consumer.metrics, 1)
+			assert.ElementsMatch(t, testInstance.expected, consumer.metrics[0].tags)
+		})
+	}
+
+	// The original attributes are not modified.
+	assert.Equal(t, map[string]interface{}{"team": "payments"}, dp.Attributes().AsRaw())
+}
+
+func TestWithAttributeMergerInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithAttributeMerger(AttributeMerger{}))
+	assert.EqualError(t, err, `unknown merge precedence: ""`)
+
+	tr, err := NewTranslator(zap.NewNop(), WithAttributeMerger(NewAttributeMerger(MergePrecedenceResource)))
+	require.NoError(t, err)
+	require.NotNil(t, tr.Config().AttributeMerger)
+	assert.Equal(t, MergePrecedenceResource, tr.Config().AttributeMerger.Precedence())
+}
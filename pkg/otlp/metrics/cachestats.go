@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CacheStats are statistics about the in-memory delta cache, reported by WithCacheStatsCallback.
+// Except for Size, they cover the period since the previous sweep.
+type CacheStats struct {
+	// Hits is the number of lookups of a stored timeseries.
+	Hits int
+	// Misses is the number of lookups of an unknown or expired timeseries.
+	Misses int
+	// Evictions is the number of timeseries that expired, or were evicted because the cache was full.
+	Evictions int
+	// Size is the number of entries of the cache after the sweep.
+	Size int
+	// SweepDuration is the time it took to remove the expired entries.
+	SweepDuration time.Duration
+}
+
+var _ DeltaStore = (*statsDeltaStore)(nil)
+var _ TTLDeltaStore = (*statsDeltaStore)(nil)
+var _ evictionCounter = (*statsDeltaStore)(nil)
+var _ resetter = (*statsDeltaStore)(nil)
+var _ sweepStopper = (*statsDeltaStore)(nil)
+
+// sweepStopper is implemented by the delta stores that run their own sweep goroutine.
+type sweepStopper interface {
+	// stopSweeps stops the sweep goroutine. It is safe to call it several times.
+	stopSweeps()
+}
+
+// statsDeltaStore is an in-memory DeltaStore that reports its statistics after each sweep.
+// Sweeps are run by a goroutine that only references the wrapped store, so that, like the
+// janitor of the default store, it is stopped once the statsDeltaStore is garbage collected,
+// unless stopSweeps was called before.
+type statsDeltaStore struct {
+	*inMemoryDeltaStore
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// newStatsDeltaStore creates an in-memory DeltaStore that calls the callback after each sweep.
+func newStatsDeltaStore(sweepInterval int64, deltaTTL int64, maxSize int, logger *zap.Logger, callback func(CacheStats)) *statsDeltaStore {
+	// A zero sweep interval disables the janitor of the cache.
+	inner := newInMemoryDeltaStore(0, deltaTTL, maxSize, logger)
+	store := &statsDeltaStore{inMemoryDeltaStore: inner, stop: make(chan struct{})}
+	go inner.runSweeps(time.Duration(sweepInterval)*time.Second, callback, store.stop)
+	runtime.SetFinalizer(store, func(s *statsDeltaStore) { s.stopSweeps() })
+	return store
+}
+
+// stopSweeps implements the sweepStopper interface.
+func (s *statsDeltaStore) stopSweeps() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// runSweeps sweeps the store at the given interval until stop is closed.
+func (s *inMemoryDeltaStore) runSweeps(interval time.Duration, callback func(CacheStats), stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			callback(s.sweep())
+		case <-stop:
+			return
+		}
+	}
+}
+
+// sweep removes the expired entries, and returns the statistics since the previous sweep.
+func (s *inMemoryDeltaStore) sweep() CacheStats {
+	start := time.Now()
+	s.cache.DeleteExpired()
+	duration := time.Since(start)
+	return CacheStats{
+		Hits:          int(atomic.SwapInt64(&s.hits, 0)),
+		Misses:        int(atomic.SwapInt64(&s.misses, 0)),
+		Evictions:     int(atomic.SwapInt64(&s.sweepEvictions, 0)),
+		Size:          s.cache.ItemCount(),
+		SweepDuration: duration,
+	}
+}
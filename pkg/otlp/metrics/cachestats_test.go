@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestInMemoryDeltaStoreSweep(t *testing.T) {
+	store := newInMemoryDeltaStore(0, 3600, 0, zap.NewNop())
+	store.Set("a", 1, 10)
+	store.Set("a"+startTsKeySuffix, 0, 5)
+	store.cache.Set("expired", storedPoint{}, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	store.Get("a")
+	store.Get("a" + startTsKeySuffix)
+	store.Get("b")
+	store.Get("c")
+
+	stats := store.sweep()
+	assert.Equal(t, 1, stats.Hits)
+	assert.Equal(t, 2, stats.Misses)
+	assert.Equal(t, 1, stats.Evictions)
+	assert.Equal(t, 2, stats.Size)
+
+	// The statistics are reset after each sweep.
+	stats = store.sweep()
+	assert.Equal(t, CacheStats{Size: 2, SweepDuration: stats.SweepDuration}, stats)
+}
+
+func TestWithCacheStatsCallback(t *testing.T) {
+	statsCh := make(chan CacheStats, 10)
+	tr, err := NewTranslator(zap.NewNop(),
+		WithDeltaTTL(2),
+		WithCacheStatsCallback(func(stats CacheStats) { statsCh <- stats }),
+	)
+	require.NoError(t, err)
+	_, err = tr.MapMetrics(context.Background(), createTestIntCumulativeMonotonicMetrics(false), &mockFullConsumer{})
+	require.NoError(t, err)
+
+	select {
+	case stats := <-statsCh:
+		// The first point of the timeseries is a miss, the next two are hits.
+		assert.Equal(t, 2, stats.Hits)
+		assert.Equal(t, 1, stats.Misses)
+		assert.Equal(t, 2, stats.Size)
+	case <-time.After(5 * time.Second):
+		t.Fatal("cache stats callback was not called")
+	}
+}
+
+func TestWithCacheStatsCallbackInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithCacheStatsCallback(nil))
+	assert.EqualError(t, err, "cache stats callback must not be nil")
+
+	_, err = NewTranslator(zap.NewNop(), WithCacheStatsCallback(func(CacheStats) {}), WithDeltaStore(newMapDeltaStore()))
+	assert.EqualError(t, err, errStatsDeltaStore)
+}
@@ -16,28 +16,140 @@ package metrics
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes"
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes/source"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 )
 
+// NOTE: Keep this in sync with the TranslatorConfig struct.
 type translatorConfig struct {
 	// metrics export behavior
-	HistMode                  HistogramMode
-	SendHistogramAggregations bool
-	Quantiles                 bool
-	SendMonotonic             bool
-	ResourceAttributesAsTags  bool
+	HistMode                 HistogramMode
+	HistogramModeRules       map[string]HistogramMode
+	StaleMode                StaleMode
+	SendHistogramCountSum    bool
+	SendHistogramMinMax      bool
+	SummaryMode              SummaryMode
+	SendMonotonic            bool
+	NumberModeRules          map[string]NumberMode
+	ResourceAttributesAsTags bool
+	ResourceAttributePrefix  string
+	ProcessMetadata          bool
+	SDKMetadataAsTags        bool
+	DryRun                   bool
+	ExemplarTranslation      bool
+	ExemplarAPMLink          bool
 	// Deprecated: use InstrumentationScopeMetadataAsTags instead in favor of
 	// https://github.com/open-telemetry/opentelemetry-proto/releases/tag/v0.15.0
 	// Both must not be enabled at the same time.
 	InstrumentationLibraryMetadataAsTags bool
 	InstrumentationScopeMetadataAsTags   bool
+	ScopeTagPrefix                       string
+	ScopeVersionTag                      bool
+	KubernetesPodLabelsAsTags            map[string]string
+	MetricNamePrefix                     string
+	MetricNameSuffix                     string
+	SanitizeMetricNames                  bool
+	AttributeDenyList                    []string
+	AttributeValueAllowList              map[string][]string
+	HostTagAttributes                    []string
+	GaugeToDistributionPatterns          []string
+	ServiceCheckRules                    []ServiceCheckRule
+	MetricRenameRules                    []RenameRule
+	HistogramExcludeInfBucket            bool
+	HistogramExcludedBucketBounds        []float64
+	MaxTagCount                          int
+	ConstantTags                         []string
+	TagKeyRenameRules                    map[string]string
+	TagSorting                           bool
+	ValueRange                           *ValueRange
+	NegativeDeltaMode                    NegativeDeltaMode
+	InvalidCumulativeMode                InvalidCumulativeMode
+	LogSamplingInterval                  time.Duration
+	MetricTimestampLag                   time.Duration
+	NormalizeSumTemporality              pmetric.AggregationTemporality
+	DeltaTTLRules                        map[string]int64
 
 	// cache configuration
-	sweepInterval int64
-	deltaTTL      int64
+	sweepInterval      int64
+	deltaTTL           int64
+	deltaCacheMaxSize  int
+	cacheStatsCallback func(CacheStats)
 
 	fallbackSourceProvider source.Provider
+	metricFilter           MetricFilter
+	deltaStore             DeltaStore
+	unitConversionTable    UnitConversionTable
+	tagValueTransform      TagValueTransform
+	translationHooks       []TranslationHook
+	attributeMappingTable  *attributes.AttributeMappingTable
+	attributeMerger        *AttributeMerger
+}
+
+// TranslatorConfig is a read-only snapshot of the configuration of a Translator.
+// NOTE: Keep this in sync with the translatorConfig struct.
+type TranslatorConfig struct {
+	HistMode                             HistogramMode
+	HistogramModeRules                   map[string]HistogramMode
+	StaleMode                            StaleMode
+	SendHistogramCountSum                bool
+	SendHistogramMinMax                  bool
+	SummaryMode                          SummaryMode
+	SendMonotonic                        bool
+	NumberModeRules                      map[string]NumberMode
+	ResourceAttributesAsTags             bool
+	ResourceAttributePrefix              string
+	ProcessMetadata                      bool
+	SDKMetadataAsTags                    bool
+	KubernetesPodLabelsAsTags            map[string]string
+	DryRun                               bool
+	ExemplarTranslation                  bool
+	ExemplarAPMLink                      bool
+	InstrumentationLibraryMetadataAsTags bool
+	InstrumentationScopeMetadataAsTags   bool
+	ScopeTagPrefix                       string
+	ScopeVersionTag                      bool
+	MetricNamePrefix                     string
+	MetricNameSuffix                     string
+	SanitizeMetricNames                  bool
+	AttributeDenyList                    []string
+	AttributeValueAllowList              map[string][]string
+	HostTagAttributes                    []string
+	GaugeToDistributionPatterns          []string
+	ServiceCheckRules                    []ServiceCheckRule
+	MetricRenameRules                    []RenameRule
+	HistogramExcludeInfBucket            bool
+	HistogramExcludedBucketBounds        []float64
+	MaxTagCount                          int
+	ConstantTags                         []string
+	TagKeyRenameRules                    map[string]string
+	TagSorting                           bool
+	ValueRange                           *ValueRange
+	NegativeDeltaMode                    NegativeDeltaMode
+	InvalidCumulativeMode                InvalidCumulativeMode
+	LogSamplingInterval                  time.Duration
+	MetricTimestampLag                   time.Duration
+	NormalizeSumTemporality              pmetric.AggregationTemporality
+	DeltaTTLRules                        map[string]int64
+
+	SweepInterval      int64
+	DeltaTTL           int64
+	DeltaCacheMaxSize  int
+	CacheStatsCallback func(CacheStats)
+
+	FallbackSourceProvider source.Provider
+	MetricFilter           MetricFilter
+	DeltaStore             DeltaStore
+	UnitConversionTable    UnitConversionTable
+	TagValueTransform      TagValueTransform
+	TranslationHooks       []TranslationHook
+	AttributeMappingTable  *attributes.AttributeMappingTable
+	AttributeMerger        *AttributeMerger
 }
 
 // TranslatorOption is a translator creation option.
@@ -59,6 +171,92 @@ func WithDeltaTTL(deltaTTL int64) TranslatorOption {
 	}
 }
 
+// WithSweepInterval sets the interval, in seconds, at which expired cumulative metrics datapoints
+// are removed from the cache. It must be positive and not greater than the delta TTL.
+// By default, half of the delta TTL is used. Since WithDeltaTTL resets the sweep interval,
+// this option must be set after it.
+func WithSweepInterval(sweepInterval int64) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if sweepInterval <= 0 {
+			return fmt.Errorf("sweep interval must be positive: %d", sweepInterval)
+		}
+		if sweepInterval > t.deltaTTL {
+			return fmt.Errorf("sweep interval must not be greater than the time to live (%d): %d", t.deltaTTL, sweepInterval)
+		}
+		t.sweepInterval = sweepInterval
+		return nil
+	}
+}
+
+// WithDeltaCacheMaxSize caps the number of entries of the in-memory delta cache, to bound its memory usage
+// under high cardinality. When the cap is exceeded, the least recently used entries are evicted and a warning
+// is logged; the next points of evicted timeseries are handled as their first points.
+// Each cumulative timeseries uses up to two entries, which are evicted together. By default, the number of entries is unbounded.
+// This option can't be used with WithDeltaStore.
+func WithDeltaCacheMaxSize(n int) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if n <= 0 {
+			return fmt.Errorf("delta cache max size must be positive: %d", n)
+		}
+		t.deltaCacheMaxSize = n
+		return nil
+	}
+}
+
+// WithCacheStatsCallback sets a callback called with the statistics of the in-memory delta cache after each sweep,
+// e.g. to tune WithDeltaTTL and WithDeltaCacheMaxSize. The callback is called from a separate goroutine.
+// This option can't be used with WithDeltaStore.
+func WithCacheStatsCallback(callback func(stats CacheStats)) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if callback == nil {
+			return fmt.Errorf("cache stats callback must not be nil")
+		}
+		t.cacheStatsCallback = callback
+		return nil
+	}
+}
+
+// WithDeltaTTLPerMetric sets the delta TTL, in seconds, of the cumulative metrics whose Datadog metric name
+// matches a key of the given rules. Keys may be exact names or glob patterns (e.g. "http.server.*");
+// an exact name takes precedence over patterns, and longer patterns over shorter ones.
+// Per-metric TTLs take precedence over the TTL set by WithDeltaTTL. Expired datapoints are removed
+// at least every TTL/2 seconds. A store set with WithDeltaStore only honors these TTLs if it implements TTLDeltaStore.
+// Multiple calls to this option accumulate rules.
+func WithDeltaTTLPerMetric(rules map[string]int64) TranslatorOption {
+	return func(t *translatorConfig) error {
+		names := make([]string, 0, len(rules))
+		for name, ttl := range rules {
+			if ttl <= 0 {
+				return fmt.Errorf("time to live for %q must be positive: %d", name, ttl)
+			}
+			names = append(names, name)
+		}
+		if err := validatePatterns(names); err != nil {
+			return err
+		}
+		if t.DeltaTTLRules == nil {
+			t.DeltaTTLRules = make(map[string]int64, len(rules))
+		}
+		for name, ttl := range rules {
+			t.DeltaTTLRules[name] = ttl
+		}
+		return nil
+	}
+}
+
+// WithDeltaStore sets the store used to keep the last values of cumulative timeseries.
+// The store is responsible for expiring stale entries, so WithDeltaTTL has no effect on it.
+// By default, an in-memory store is used.
+func WithDeltaStore(store DeltaStore) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if store == nil {
+			return fmt.Errorf("delta store must not be nil")
+		}
+		t.deltaStore = store
+		return nil
+	}
+}
+
 // WithFallbackSourceProvider sets the fallback source provider.
 // By default, an empty hostname is used as a fallback.
 func WithFallbackSourceProvider(provider source.Provider) TranslatorOption {
@@ -68,14 +266,315 @@ func WithFallbackSourceProvider(provider source.Provider) TranslatorOption {
 	}
 }
 
-// WithQuantiles enables quantiles exporting for summary metrics.
-func WithQuantiles() TranslatorOption {
+// WithMetricFilter sets a filter deciding which metrics are translated. Excluded metrics are counted in both
+// the DroppedMetrics and UnsupportedMetricTypes stats, to help debugging misconfigured filters.
+// By default, all metrics are translated.
+func WithMetricFilter(filter MetricFilter) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if filter == nil {
+			return fmt.Errorf("metric filter must not be nil")
+		}
+		t.metricFilter = filter
+		return nil
+	}
+}
+
+// WithUnitNormalization normalizes Gauge and Sum metrics according to their OTLP unit:
+// values are multiplied by the scale of the unit conversion and its suffix is appended to the metric name.
+// Metrics with a unit not present in the table are not normalized.
+// By default, metrics are not normalized.
+func WithUnitNormalization(table UnitConversionTable) TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.unitConversionTable = make(UnitConversionTable, len(table))
+		for unit, conversion := range table {
+			if conversion.Scale <= 0 || math.IsInf(conversion.Scale, 0) || math.IsNaN(conversion.Scale) {
+				return fmt.Errorf("invalid scale for unit %q: %v", unit, conversion.Scale)
+			}
+			if conversion.Suffix != "" && !metricNameSuffixRegexp.MatchString(conversion.Suffix) {
+				return fmt.Errorf("invalid suffix for unit %q: %q", unit, conversion.Suffix)
+			}
+			t.unitConversionTable[unit] = conversion
+		}
+		return nil
+	}
+}
+
+// WithMetricNameSanitization enables or disables the sanitization of metric names so that they follow
+// the Datadog metric naming constraints. Illegal characters are replaced by dots and names are
+// truncated to 200 characters. Sanitization applies to the full name, including prefix and suffix.
+// Metrics whose name is empty once sanitized (e.g. "123") are dropped.
+// By default, metric names are not sanitized.
+func WithMetricNameSanitization(enabled bool) TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.SanitizeMetricNames = enabled
+		return nil
+	}
+}
+
+// WithAttributeDenyList prevents the given resource and datapoint attribute keys from being converted to tags.
+// Keys are either exact attribute keys or glob patterns following the path.Match syntax (e.g. "http.request.header.*").
+// Multiple calls to this option accumulate keys.
+func WithAttributeDenyList(keys ...string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if err := validatePatterns(keys); err != nil {
+			return err
+		}
+		t.AttributeDenyList = append(t.AttributeDenyList, keys...)
+		return nil
+	}
+}
+
+// WithHostTagAttributes converts the given resource and datapoint attribute keys to host tags
+// (see Dimensions.HostTags) instead of metric tags. Resource attributes are converted like in
+// attributes.TagsFromResourceAttributes.
+// Keys are either exact attribute keys or glob patterns following the path.Match syntax (e.g. "host.*").
+// Multiple calls to this option accumulate keys.
+func WithHostTagAttributes(keys ...string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if err := validatePatterns(keys); err != nil {
+			return err
+		}
+		t.HostTagAttributes = append(t.HostTagAttributes, keys...)
+		return nil
+	}
+}
+
+// WithGaugeToDistribution exports Gauge metrics whose Datadog metric name matches the given pattern
+// as distributions, with one distribution point per datapoint, instead of as gauges.
+// The pattern is either an exact metric name or a glob pattern following the path.Match syntax (e.g. "*.duration").
+// Multiple calls to this option accumulate patterns.
+func WithGaugeToDistribution(metricNamePattern string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if err := validatePatterns([]string{metricNamePattern}); err != nil {
+			return err
+		}
+		t.GaugeToDistributionPatterns = append(t.GaugeToDistributionPatterns, metricNamePattern)
+		return nil
+	}
+}
+
+// WithServiceCheckMapping reports a Datadog service check for each gauge datapoint matching one of the rules,
+// in addition to the gauge itself. When several rules match a datapoint, the first one is used.
+// Service checks are only reported to consumers implementing ServiceCheckConsumer, and are not passed to translation hooks.
+// Multiple calls to this option accumulate rules.
+func WithServiceCheckMapping(rules []ServiceCheckRule) TranslatorOption {
+	return func(t *translatorConfig) error {
+		for _, rule := range rules {
+			if err := rule.validate(); err != nil {
+				return err
+			}
+		}
+		t.ServiceCheckRules = append(t.ServiceCheckRules, rules...)
+		return nil
+	}
+}
+
+// WithMetricRenameRules renames the translated metrics, including the ones derived from histograms and summaries
+// (e.g. "http.duration.count"), whose Datadog metric name matches one of the rules. Rules are applied in order,
+// and the first matching rule wins. Renaming happens before translation hooks are applied.
+// Multiple calls to this option accumulate rules.
+func WithMetricRenameRules(rules []RenameRule) TranslatorOption {
+	return func(t *translatorConfig) error {
+		compiled := make([]RenameRule, 0, len(rules))
+		for _, rule := range rules {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid rename pattern %q: %w", rule.Pattern, err)
+			}
+			rule.re = re
+			compiled = append(compiled, rule)
+		}
+		t.MetricRenameRules = append(t.MetricRenameRules, compiled...)
+		return nil
+	}
+}
+
+// WithAttributeValueAllowList restricts the values of the given resource and datapoint attribute keys
+// to limit the cardinality of their tags: values not in the allowed set of their key are replaced by "other".
+// Attributes with a key not in the rules are unaffected.
+// Multiple calls to this option accumulate allowed values.
+func WithAttributeValueAllowList(rules map[string][]string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if t.AttributeValueAllowList == nil {
+			t.AttributeValueAllowList = make(map[string][]string, len(rules))
+		}
+		for key, values := range rules {
+			if key == "" {
+				return fmt.Errorf("attribute value allow list key must not be empty")
+			}
+			t.AttributeValueAllowList[key] = append(t.AttributeValueAllowList[key], values...)
+		}
+		return nil
+	}
+}
+
+// TagValueTransform transforms the value of a tag with the given key.
+type TagValueTransform func(key, value string) string
+
+// LowercaseTagValueTransform is a TagValueTransform that lowercases tag values.
+func LowercaseTagValueTransform(_, value string) string {
+	return strings.ToLower(value)
+}
+
+// WithTagValueTransform applies the given transform to the value of every tag coming from
+// resource attributes, instrumentation scope metadata and datapoint attributes.
+func WithTagValueTransform(transform TagValueTransform) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if transform == nil {
+			return fmt.Errorf("tag value transform must not be nil")
+		}
+		t.tagValueTransform = transform
+		return nil
+	}
+}
+
+// WithTranslationHook adds a hook that post-processes the dimensions of every translated metric
+// before it is consumed. Returning nil from the hook drops the metric.
+// Multiple calls to this option chain the hooks in the order they were added.
+func WithTranslationHook(hook TranslationHook) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if hook == nil {
+			return fmt.Errorf("translation hook must not be nil")
+		}
+		t.translationHooks = append(t.translationHooks, hook)
+		return nil
+	}
+}
+
+// WithAttributeMappingTable converts resource attributes to tags with the mappings of the given table,
+// in addition to the built-in ones. The table mappings take precedence over the built-in mappings.
+// See attributes.LoadAttributeMappingTable to load a table from a JSON configuration.
+func WithAttributeMappingTable(table *attributes.AttributeMappingTable) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if table == nil {
+			return fmt.Errorf("attribute mapping table must not be nil")
+		}
+		t.attributeMappingTable = table
+		return nil
+	}
+}
+
+// WithAttributeMerger merges the resource and instrumentation scope attributes into the attributes of
+// every datapoint with the given merger, so that they are all converted to datapoint tags. Key conflicts
+// between the levels are resolved following the precedence of the merger.
+// The tags built from resource attributes with the built-in mappings (e.g. env) are still added.
+// By default, only the datapoint attributes are converted to datapoint tags.
+func WithAttributeMerger(merger AttributeMerger) TranslatorOption {
+	return func(t *translatorConfig) error {
+		switch merger.precedence {
+		case MergePrecedenceDatapoint, MergePrecedenceResource:
+			t.attributeMerger = &merger
+			return nil
+		}
+		return fmt.Errorf("unknown merge precedence: %q", merger.precedence)
+	}
+}
+
+// WithLogSamplingInterval collapses identical log messages of the translator within the given interval:
+// the first message is logged, and the next one logged after the interval reports how many were suppressed
+// (e.g. "Unknown or unsupported metric type (suppressed 999 identical messages)").
+// By default, every message is logged.
+func WithLogSamplingInterval(interval time.Duration) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if interval <= 0 {
+			return fmt.Errorf("log sampling interval must be positive: %s", interval)
+		}
+		t.LogSamplingInterval = interval
+		return nil
+	}
+}
+
+// WithMetricTimestampLag drops, with a warning, the datapoints whose timestamp is older than maxAge,
+// e.g. when a producer replays a backlog. Datadog rejects points that are too old, so dropping them
+// in the translator makes the loss visible in the logs and in the DroppedStalePoints stat.
+// By default, datapoints are not checked.
+func WithMetricTimestampLag(maxAge time.Duration) TranslatorOption {
 	return func(t *translatorConfig) error {
-		t.Quantiles = true
+		if maxAge <= 0 {
+			return fmt.Errorf("metric timestamp lag must be positive: %s", maxAge)
+		}
+		t.MetricTimestampLag = maxAge
 		return nil
 	}
 }
 
+// WithNormalizeSumTemporality normalizes the temporality of Sum metrics before they are translated, so that
+// a counter is reported the same way whether its producer uses delta or cumulative temporality.
+// With pmetric.AggregationTemporalityCumulative, the points of delta sums are accumulated into a running
+// total per timeseries, which is then translated like a cumulative sum: monotonic sums go through the
+// same delta conversion, resets and value checks, and non-monotonic sums report their total instead of
+// the last change. Cumulative sums are unchanged.
+// pmetric.AggregationTemporalityDelta is the default behavior: cumulative sums are converted to deltas,
+// and delta sums are reported as is.
+func WithNormalizeSumTemporality(target pmetric.AggregationTemporality) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if target != pmetric.AggregationTemporalityCumulative && target != pmetric.AggregationTemporalityDelta {
+			return fmt.Errorf("unsupported sum temporality: %s", target)
+		}
+		t.NormalizeSumTemporality = target
+		return nil
+	}
+}
+
+// WithMaxTagCount sets the maximum number of tags a datapoint can have.
+// Tags exceeding this limit are dropped, keeping the first ones in alphabetical order.
+// By default, the number of tags is not limited.
+func WithMaxTagCount(n int) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if n <= 0 {
+			return fmt.Errorf("maximum tag count must be positive: %d", n)
+		}
+		t.MaxTagCount = n
+		return nil
+	}
+}
+
+// WithConstantTags adds the given `key:value` tags to every translated datapoint, after the
+// attribute-derived tags are deduplicated and truncated to the maximum tag count, if any.
+// Tags are lowercased; tags that do not follow the Datadog tag format are rejected.
+// Multiple calls to this option accumulate tags.
+func WithConstantTags(tags ...string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		for _, tag := range tags {
+			key, value, found := strings.Cut(tag, ":")
+			if !found {
+				return fmt.Errorf("constant tag %q must be of the form key:value", tag)
+			}
+			key, value, err := attributes.NormalizeTag(key, value)
+			if err != nil {
+				return fmt.Errorf("invalid constant tag: %w", err)
+			}
+			t.ConstantTags = append(t.ConstantTags, key+":"+value)
+		}
+		return nil
+	}
+}
+
+// WithTagKeyRenameRules renames the keys of the tags coming from resource attributes, instrumentation
+// scope metadata and datapoint attributes, as well as of the constant tags: rules map a tag key to its new key.
+// Renaming is the last step of tag generation, and every tag is renamed at most once.
+// Multiple calls to this option accumulate rules.
+func WithTagKeyRenameRules(rules map[string]string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if t.TagKeyRenameRules == nil {
+			t.TagKeyRenameRules = make(map[string]string, len(rules))
+		}
+		for key, newKey := range rules {
+			if key == "" || newKey == "" {
+				return fmt.Errorf("invalid tag key rename rule %q: %q", key, newKey)
+			}
+			t.TagKeyRenameRules[key] = newKey
+		}
+		return nil
+	}
+}
+
+// WithQuantiles enables quantiles exporting for summary metrics.
+// Deprecated: Use WithSummaryMode(SummaryModeGauges) instead.
+func WithQuantiles() TranslatorOption {
+	return WithSummaryMode(SummaryModeGauges)
+}
+
 // WithResourceAttributesAsTags sets resource attributes as tags.
 func WithResourceAttributesAsTags() TranslatorOption {
 	return func(t *translatorConfig) error {
@@ -84,6 +583,107 @@ func WithResourceAttributesAsTags() TranslatorOption {
 	}
 }
 
+// WithResourceAttributePrefix prepends the given prefix to the keys of the tags derived from
+// resource attributes (e.g. "resource.env:prod"), so that they can be told apart from the tags
+// derived from datapoint attributes with the same key. The tags of the Kubernetes pod labels
+// (see WithKubernetesPodLabelsAsTags) are prefixed as well. Host tags are not prefixed.
+// By default, no prefix is used.
+func WithResourceAttributePrefix(prefix string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if strings.Contains(prefix, ":") {
+			return fmt.Errorf("resource attribute prefix must not contain ':': %q", prefix)
+		}
+		t.ResourceAttributePrefix = prefix
+		return nil
+	}
+}
+
+// WithProcessMetadata reports the metadata derived from the process.* resource attributes
+// (see attributes.ProcessAttributesToHostMetadata) to consumers implementing HostMetadataConsumer.
+// The metadata is reported along with the metrics of every resource with a host source.
+func WithProcessMetadata() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.ProcessMetadata = true
+		return nil
+	}
+}
+
+// WithSDKMetadataAsTags adds the SDK metadata derived from the telemetry.sdk.* resource attributes
+// (see attributes.SDKMetadataFromAttributes) as otel.sdk.name, otel.sdk.language and otel.sdk.version tags.
+// By default, the SDK metadata is not added as tags.
+func WithSDKMetadataAsTags() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.SDKMetadataAsTags = true
+		return nil
+	}
+}
+
+// WithKubernetesPodLabelsAsTags adds the Kubernetes pod labels of a resource, reported as k8s.pod.labels.<label>
+// resource attributes, as tags: labelMapping maps a label name to the key of its tag.
+// Labels not in the mapping are ignored. Like the other tags derived from resource attributes,
+// the tag keys are prefixed by WithResourceAttributePrefix.
+// Multiple calls to this option accumulate mappings.
+func WithKubernetesPodLabelsAsTags(labelMapping map[string]string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if t.KubernetesPodLabelsAsTags == nil {
+			t.KubernetesPodLabelsAsTags = make(map[string]string, len(labelMapping))
+		}
+		for label, key := range labelMapping {
+			if label == "" || key == "" {
+				return fmt.Errorf("invalid pod label mapping %q: %q", label, key)
+			}
+			t.KubernetesPodLabelsAsTags[label] = key
+		}
+		return nil
+	}
+}
+
+// WithDryRun enables the dry run mode: metrics are translated as usual but nothing is reported to the
+// consumer given to MapMetrics, which may be nil. Instead, the translated metrics are counted in the
+// stats returned by MapMetrics, along with the dropped and converted ones.
+// This can be used to validate that a payload translates cleanly with a given configuration.
+// The state of the translator, such as the previous points of cumulative metrics, is still updated:
+// use a dedicated Translator for dry runs.
+func WithDryRun() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.DryRun = true
+		return nil
+	}
+}
+
+// WithTagSorting sorts the tags and host tags of the translated metrics lexicographically before they
+// are passed to the consumer, so that they are reported in a deterministic order. Tags added by
+// translation hooks are sorted as well.
+func WithTagSorting() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.TagSorting = true
+		return nil
+	}
+}
+
+// WithExemplarTranslation reports the exemplars with a trace context of gauge, histogram and
+// exponential histogram datapoints to consumers implementing ExemplarConsumer.
+// Exemplars are only reported along with gauges and distributions: histograms in counters
+// or nobuckets mode have no exemplars.
+func WithExemplarTranslation() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.ExemplarTranslation = true
+		return nil
+	}
+}
+
+// WithExemplarToAPMLink reports exemplars like WithExemplarTranslation, which it enables, and adds to
+// each exemplar the _dd.trace_id and _dd.span_id tags, which link it to its trace and span in Datadog APM.
+// The IDs are formatted as Datadog APM expects them: as decimal numbers, using the lower 64 bits of the
+// trace ID.
+func WithExemplarToAPMLink() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.ExemplarTranslation = true
+		t.ExemplarAPMLink = true
+		return nil
+	}
+}
+
 // WithInstrumentationLibraryMetadataAsTags sets instrumentation library metadata as tags.
 func WithInstrumentationLibraryMetadataAsTags() TranslatorOption {
 	return func(t *translatorConfig) error {
@@ -100,6 +700,62 @@ func WithInstrumentationScopeMetadataAsTags() TranslatorOption {
 	}
 }
 
+// WithScopeTagPrefix sets the prefix of the keys of the instrumentation scope metadata tags
+// added by WithInstrumentationScopeMetadataAsTags: the scope name and version are reported as
+// <prefix>name and <prefix>version tags (e.g. otel.scope.name and otel.scope.version with "otel.scope.").
+// By default, or with an empty prefix, the instrumentation_scope and instrumentation_scope_version keys are used.
+func WithScopeTagPrefix(prefix string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if strings.Contains(prefix, ":") {
+			return fmt.Errorf("scope tag prefix must not contain ':': %q", prefix)
+		}
+		t.ScopeTagPrefix = prefix
+		return nil
+	}
+}
+
+// WithScopeVersionTag sets whether the instrumentation scope version is reported as a tag
+// by WithInstrumentationScopeMetadataAsTags. Disabling it avoids a new timeseries for every
+// version of the instrumentation scope, while keeping the scope name tag. It is enabled by default.
+func WithScopeVersionTag(enabled bool) TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.ScopeVersionTag = enabled
+		return nil
+	}
+}
+
+var (
+	// metricNamePrefixRegexp matches prefixes that keep the metric name valid in Datadog:
+	// they must start with a letter and only contain alphanumerics, underscores and periods.
+	metricNamePrefixRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.]*$`)
+	// metricNameSuffixRegexp matches suffixes that keep the metric name valid in Datadog.
+	metricNameSuffixRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.]+$`)
+)
+
+// WithMetricNamePrefix sets a prefix that is added to the name of all translated metrics.
+// The prefix is added verbatim, so it should include a trailing separator (e.g. "myteam.").
+func WithMetricNamePrefix(prefix string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if !metricNamePrefixRegexp.MatchString(prefix) {
+			return fmt.Errorf("invalid metric name prefix: %q", prefix)
+		}
+		t.MetricNamePrefix = prefix
+		return nil
+	}
+}
+
+// WithMetricNameSuffix sets a suffix that is added to the name of all translated metrics.
+// The suffix is added verbatim, before any aggregation suffix such as ".count" or ".sum".
+func WithMetricNameSuffix(suffix string) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if !metricNameSuffixRegexp.MatchString(suffix) {
+			return fmt.Errorf("invalid metric name suffix: %q", suffix)
+		}
+		t.MetricNameSuffix = suffix
+		return nil
+	}
+}
+
 // HistogramMode is an export mode for OTLP Histogram metrics.
 type HistogramMode string
 
@@ -109,6 +765,7 @@ const (
 	// HistogramModeCounters exports buckets as Datadog counts.
 	HistogramModeCounters HistogramMode = "counters"
 	// HistogramModeDistributions exports buckets as Datadog distributions.
+	// Distributions are passed to the SketchConsumer as quantile.Sketch, which can be serialized with SketchPayload.
 	HistogramModeDistributions HistogramMode = "distributions"
 )
 
@@ -127,6 +784,33 @@ func WithHistogramMode(mode HistogramMode) TranslatorOption {
 	}
 }
 
+// WithHistogramModePerMetric sets the histogram mode of the histograms whose Datadog metric name matches
+// one of the keys of rules, overriding the mode set by WithHistogramMode for them.
+// Keys are either exact metric names or glob patterns following the path.Match syntax (e.g. "*.duration").
+// An exact name takes precedence over patterns; among matching patterns, the longest one wins.
+// Multiple calls to this option accumulate rules.
+func WithHistogramModePerMetric(rules map[string]HistogramMode) TranslatorOption {
+	return func(t *translatorConfig) error {
+		for pattern, mode := range rules {
+			if err := validatePatterns([]string{pattern}); err != nil {
+				return err
+			}
+			switch mode {
+			case HistogramModeNoBuckets, HistogramModeCounters, HistogramModeDistributions:
+			default:
+				return fmt.Errorf("unknown histogram mode for %q: %q", pattern, mode)
+			}
+		}
+		if t.HistogramModeRules == nil {
+			t.HistogramModeRules = make(map[string]HistogramMode, len(rules))
+		}
+		for pattern, mode := range rules {
+			t.HistogramModeRules[pattern] = mode
+		}
+		return nil
+	}
+}
+
 // WithCountSumMetrics exports .count and .sum histogram metrics.
 // Deprecated: Use WithHistogramAggregations instead.
 func WithCountSumMetrics() TranslatorOption {
@@ -134,9 +818,78 @@ func WithCountSumMetrics() TranslatorOption {
 }
 
 // WithHistogramAggregations exports .count, .sum, .min and .max histogram metrics when available.
+// It is equivalent to setting both WithHistogramCountSum and WithHistogramMinMax.
 func WithHistogramAggregations() TranslatorOption {
 	return func(t *translatorConfig) error {
-		t.SendHistogramAggregations = true
+		t.SendHistogramCountSum = true
+		t.SendHistogramMinMax = true
+		return nil
+	}
+}
+
+// WithHistogramCountSum exports .count and .sum histogram metrics.
+func WithHistogramCountSum() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.SendHistogramCountSum = true
+		return nil
+	}
+}
+
+// WithHistogramMinMax exports .min and .max histogram metrics when available.
+func WithHistogramMinMax() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.SendHistogramMinMax = true
+		return nil
+	}
+}
+
+// WithHistogramExcludeInfBucket skips the +Inf bucket when exporting histogram buckets as counts.
+// The +Inf bucket count is redundant with the .count metric, so this option requires histogram count and sum exporting
+// (see WithHistogramCountSum). It requires HistogramModeCounters.
+func WithHistogramExcludeInfBucket() TranslatorOption {
+	return func(t *translatorConfig) error {
+		t.HistogramExcludeInfBucket = true
+		return nil
+	}
+}
+
+// WithHistogramBucketExclusion skips the buckets with the given upper bounds when exporting histogram buckets as counts.
+// It requires HistogramModeCounters. Multiple calls to this option accumulate bounds.
+func WithHistogramBucketExclusion(upperBounds ...float64) TranslatorOption {
+	return func(t *translatorConfig) error {
+		for _, bound := range upperBounds {
+			if math.IsNaN(bound) {
+				return fmt.Errorf("invalid bucket upper bound: %v", bound)
+			}
+		}
+		t.HistogramExcludedBucketBounds = append(t.HistogramExcludedBucketBounds, upperBounds...)
+		return nil
+	}
+}
+
+// SummaryMode is an export mode for OTLP Summary metrics.
+type SummaryMode string
+
+const (
+	// SummaryModeSkip skips summary metrics.
+	SummaryModeSkip SummaryMode = "skip"
+	// SummaryModeNoBuckets exports the .count and .sum summary metrics.
+	SummaryModeNoBuckets SummaryMode = "nobuckets"
+	// SummaryModeGauges exports the .count and .sum summary metrics, as well as
+	// one .quantile gauge per quantile, tagged by quantile.
+	SummaryModeGauges SummaryMode = "gauges"
+)
+
+// WithSummaryMode sets the summaries mode.
+// The default mode is SummaryModeNoBuckets.
+func WithSummaryMode(mode SummaryMode) TranslatorOption {
+	return func(t *translatorConfig) error {
+		switch mode {
+		case SummaryModeSkip, SummaryModeNoBuckets, SummaryModeGauges:
+			t.SummaryMode = mode
+		default:
+			return fmt.Errorf("unknown summary mode: %q", mode)
+		}
 		return nil
 	}
 }
@@ -170,3 +923,151 @@ func WithNumberMode(mode NumberMode) TranslatorOption {
 		return nil
 	}
 }
+
+// WithNumberModePerMetric sets the number mode of the metrics whose Datadog metric name matches
+// one of the keys of rules, overriding the mode set by WithNumberMode for them.
+// Keys are either exact metric names or glob patterns following the path.Match syntax (e.g. "process.*").
+// An exact name takes precedence over patterns; among matching patterns, the longest one wins.
+// Multiple calls to this option accumulate rules.
+func WithNumberModePerMetric(rules map[string]NumberMode) TranslatorOption {
+	return func(t *translatorConfig) error {
+		for pattern, mode := range rules {
+			if err := validatePatterns([]string{pattern}); err != nil {
+				return err
+			}
+			if mode != NumberModeCumulativeToDelta && mode != NumberModeRawValue {
+				return fmt.Errorf("unknown number mode for %q: %q", pattern, mode)
+			}
+		}
+		if t.NumberModeRules == nil {
+			t.NumberModeRules = make(map[string]NumberMode, len(rules))
+		}
+		for pattern, mode := range rules {
+			t.NumberModeRules[pattern] = mode
+		}
+		return nil
+	}
+}
+
+// StaleMode is the handling mode for datapoints with the FLAG_NO_RECORDED_VALUE flag,
+// which indicates a stale or absent measurement.
+type StaleMode string
+
+const (
+	// StaleModeDrop skips datapoints with no recorded value.
+	StaleModeDrop StaleMode = "drop"
+
+	// StaleModeEmitNaN reports datapoints of Gauge and Sum metrics with no recorded value as NaN.
+	// Datapoints of other metric types, and of gauges exported as distributions, are skipped.
+	StaleModeEmitNaN StaleMode = "emit_nan"
+)
+
+// WithStaleMetricHandling sets the handling mode for datapoints with no recorded value.
+// The default mode is StaleModeDrop.
+func WithStaleMetricHandling(mode StaleMode) TranslatorOption {
+	return func(t *translatorConfig) error {
+		switch mode {
+		case StaleModeDrop, StaleModeEmitNaN:
+			t.StaleMode = mode
+		default:
+			return fmt.Errorf("unknown stale mode: %q", mode)
+		}
+		return nil
+	}
+}
+
+// NegativeDeltaMode is the handling mode for the first point of a cumulative metric after a reset:
+// cumulative monotonic sums when NumberModeCumulativeToDelta is used, and the counts, sums and
+// buckets of cumulative histograms and summaries.
+type NegativeDeltaMode string
+
+const (
+	// NegativeDeltaModeDrop drops the first point after a reset.
+	NegativeDeltaModeDrop NegativeDeltaMode = "drop"
+
+	// NegativeDeltaModeReset reports a zero delta for the first point after a reset.
+	NegativeDeltaModeReset NegativeDeltaMode = "reset"
+)
+
+// WithNegativeDeltaHandling sets the handling mode for resets of cumulative monotonic sums, histograms and summaries.
+// The default mode is NegativeDeltaModeDrop. NegativeDeltaModeReset requires NumberModeCumulativeToDelta,
+// either globally or for some metrics (see WithNumberModePerMetric).
+func WithNegativeDeltaHandling(mode NegativeDeltaMode) TranslatorOption {
+	return func(t *translatorConfig) error {
+		switch mode {
+		case NegativeDeltaModeDrop, NegativeDeltaModeReset:
+			t.NegativeDeltaMode = mode
+		default:
+			return fmt.Errorf("unknown negative delta mode: %q", mode)
+		}
+		return nil
+	}
+}
+
+// ValueRange is the range of values accepted for datapoints, set by WithValueClamp or WithValueDropOutsideRange.
+type ValueRange struct {
+	// Min is the lower bound of the range.
+	Min float64
+	// Max is the upper bound of the range.
+	Max float64
+	// Drop indicates whether values outside of the range are dropped instead of clamped.
+	Drop bool
+}
+
+// WithValueClamp clamps the values of gauge and count datapoints, and of histogram bucket counts,
+// to the [min, max] range. A warning is logged whenever a value is clamped.
+// The range applies to the values before unit conversion and, for cumulative metrics, to the computed deltas.
+// It overrides WithValueDropOutsideRange.
+func WithValueClamp(min, max float64) TranslatorOption {
+	return withValueRange(min, max, false)
+}
+
+// WithValueDropOutsideRange drops the gauge and count datapoints, and histogram bucket counts,
+// whose value is outside of the [min, max] range.
+// The range applies to the values before unit conversion and, for cumulative metrics, to the computed deltas.
+// It overrides WithValueClamp.
+func WithValueDropOutsideRange(min, max float64) TranslatorOption {
+	return withValueRange(min, max, true)
+}
+
+func withValueRange(min, max float64, drop bool) TranslatorOption {
+	return func(t *translatorConfig) error {
+		if math.IsNaN(min) || math.IsNaN(max) || min > max {
+			return fmt.Errorf("invalid value range [%v, %v]", min, max)
+		}
+		t.ValueRange = &ValueRange{Min: min, Max: max, Drop: drop}
+		return nil
+	}
+}
+
+// InvalidCumulativeMode is the handling mode for the points of a cumulative monotonic metric whose value
+// decreased although their start timestamp is known and unchanged, when NumberModeCumulativeToDelta is used.
+// Unlike a reset, which comes with a new start timestamp, such a decrease is a data quality issue.
+// A warning is logged for every invalid point, whatever the mode.
+type InvalidCumulativeMode string
+
+const (
+	// InvalidCumulativeModeWarn handles invalid points as resets, according to the NegativeDeltaMode.
+	InvalidCumulativeModeWarn InvalidCumulativeMode = "warn"
+
+	// InvalidCumulativeModeWarnAndDrop drops invalid points: the next delta is computed from the last valid point.
+	InvalidCumulativeModeWarnAndDrop InvalidCumulativeMode = "warn_and_drop"
+
+	// InvalidCumulativeModeWarnAndReset handles invalid points as resets, reporting a zero delta
+	// whatever the NegativeDeltaMode.
+	InvalidCumulativeModeWarnAndReset InvalidCumulativeMode = "warn_and_reset"
+)
+
+// WithInvalidCumulativeHandling sets the handling mode for invalid points of cumulative monotonic metrics.
+// The default mode is InvalidCumulativeModeWarn.
+func WithInvalidCumulativeHandling(mode InvalidCumulativeMode) TranslatorOption {
+	return func(t *translatorConfig) error {
+		switch mode {
+		case InvalidCumulativeModeWarn, InvalidCumulativeModeWarnAndDrop, InvalidCumulativeModeWarnAndReset:
+			t.InvalidCumulativeMode = mode
+		default:
+			return fmt.Errorf("unknown invalid cumulative mode: %q", mode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,402 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMetricNamePrefixSuffixOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []TranslatorOption
+		prefix  string
+		suffix  string
+		err     string
+	}{
+		{
+			name:    "prefix",
+			options: []TranslatorOption{WithMetricNamePrefix("myteam.")},
+			prefix:  "myteam.",
+		},
+		{
+			name:    "suffix",
+			options: []TranslatorOption{WithMetricNameSuffix(".otel")},
+			suffix:  ".otel",
+		},
+		{
+			name:    "prefix and suffix",
+			options: []TranslatorOption{WithMetricNamePrefix("otel."), WithMetricNameSuffix("_v2")},
+			prefix:  "otel.",
+			suffix:  "_v2",
+		},
+		{
+			name:    "empty prefix",
+			options: []TranslatorOption{WithMetricNamePrefix("")},
+			err:     `invalid metric name prefix: ""`,
+		},
+		{
+			name:    "prefix not starting with a letter",
+			options: []TranslatorOption{WithMetricNamePrefix("1team.")},
+			err:     `invalid metric name prefix: "1team."`,
+		},
+		{
+			name:    "prefix with illegal characters",
+			options: []TranslatorOption{WithMetricNamePrefix("my-team/")},
+			err:     `invalid metric name prefix: "my-team/"`,
+		},
+		{
+			name:    "empty suffix",
+			options: []TranslatorOption{WithMetricNameSuffix("")},
+			err:     `invalid metric name suffix: ""`,
+		},
+		{
+			name:    "suffix with illegal characters",
+			options: []TranslatorOption{WithMetricNameSuffix(":v2")},
+			err:     `invalid metric name suffix: ":v2"`,
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), testInstance.options...)
+			if testInstance.err != "" {
+				assert.EqualError(t, err, testInstance.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testInstance.prefix, tr.cfg.MetricNamePrefix)
+			assert.Equal(t, testInstance.suffix, tr.cfg.MetricNameSuffix)
+		})
+	}
+}
+
+func TestWithSweepInterval(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       []TranslatorOption
+		sweepInterval int64
+		deltaTTL      int64
+		err           string
+	}{
+		{
+			name:          "default",
+			sweepInterval: 1800,
+			deltaTTL:      3600,
+		},
+		{
+			name:          "override default TTL",
+			options:       []TranslatorOption{WithSweepInterval(60)},
+			sweepInterval: 60,
+			deltaTTL:      3600,
+		},
+		{
+			name:          "override after TTL",
+			options:       []TranslatorOption{WithDeltaTTL(100), WithSweepInterval(10)},
+			sweepInterval: 10,
+			deltaTTL:      100,
+		},
+		{
+			name:          "equal to TTL",
+			options:       []TranslatorOption{WithDeltaTTL(100), WithSweepInterval(100)},
+			sweepInterval: 100,
+			deltaTTL:      100,
+		},
+		{
+			name:          "TTL resets override",
+			options:       []TranslatorOption{WithSweepInterval(10), WithDeltaTTL(100)},
+			sweepInterval: 50,
+			deltaTTL:      100,
+		},
+		{
+			name:    "zero",
+			options: []TranslatorOption{WithSweepInterval(0)},
+			err:     "sweep interval must be positive: 0",
+		},
+		{
+			name:    "greater than TTL",
+			options: []TranslatorOption{WithDeltaTTL(100), WithSweepInterval(101)},
+			err:     "sweep interval must not be greater than the time to live (100): 101",
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), testInstance.options...)
+			if testInstance.err != "" {
+				assert.EqualError(t, err, testInstance.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testInstance.sweepInterval, tr.Config().SweepInterval)
+			assert.Equal(t, testInstance.deltaTTL, tr.Config().DeltaTTL)
+		})
+	}
+}
+
+func TestBothMetadataAsTags(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(),
+		WithInstrumentationLibraryMetadataAsTags(),
+		WithInstrumentationScopeMetadataAsTags(),
+	)
+	assert.EqualError(t, err, "WithInstrumentationLibraryMetadataAsTags and WithInstrumentationScopeMetadataAsTags must not be enabled at the same time")
+}
+
+func TestTranslatorBuilder(t *testing.T) {
+	tr, errs := NewTranslatorBuilder(zap.NewNop()).
+		Add(WithHistogramMode(HistogramModeCounters)).
+		Add(WithMaxTagCount(10)).
+		Build()
+	require.Empty(t, errs)
+	assert.Equal(t, HistogramModeCounters, tr.Config().HistMode)
+	assert.Equal(t, 10, tr.Config().MaxTagCount)
+}
+
+func TestTranslatorBuilderErrors(t *testing.T) {
+	options := []TranslatorOption{
+		WithDeltaTTL(0),
+		WithHistogramMode(HistogramModeNoBuckets),
+		WithMaxTagCount(-1),
+		WithInstrumentationLibraryMetadataAsTags(),
+		WithInstrumentationScopeMetadataAsTags(),
+		WithMetricNamePrefix("1team."),
+	}
+
+	builder := NewTranslatorBuilder(zap.NewNop())
+	for _, opt := range options {
+		builder.Add(opt)
+	}
+	tr, errs := builder.Build()
+	assert.Nil(t, tr)
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		"time to live must be positive: 0",
+		"maximum tag count must be positive: -1",
+		`invalid metric name prefix: "1team."`,
+		errNoBucketsNoSumCount,
+		errBothMetadataAsTags,
+	}, messages)
+
+	// NewTranslator and ValidateOptions combine all the errors.
+	combined := strings.Join(messages, "; ")
+	_, err := NewTranslator(zap.NewNop(), options...)
+	assert.EqualError(t, err, combined)
+	assert.EqualError(t, ValidateOptions(options...), combined)
+}
+
+func TestValidateOptionsConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []TranslatorOption
+		err     string
+	}{
+		{
+			name:    "valid",
+			options: []TranslatorOption{WithHistogramMode(HistogramModeCounters), WithHistogramBucketExclusion(1)},
+		},
+		{
+			name:    "no buckets without count and sum",
+			options: []TranslatorOption{WithHistogramMode(HistogramModeNoBuckets)},
+			err:     errNoBucketsNoSumCount,
+		},
+		{
+			name:    "both metadata as tags",
+			options: []TranslatorOption{WithInstrumentationLibraryMetadataAsTags(), WithInstrumentationScopeMetadataAsTags()},
+			err:     errBothMetadataAsTags,
+		},
+		{
+			name:    "excluded +Inf bucket without count and sum",
+			options: []TranslatorOption{WithHistogramMode(HistogramModeCounters), WithHistogramExcludeInfBucket()},
+			err:     errExcludeInfNoSumCount,
+		},
+		{
+			name:    "bucket exclusion without counters",
+			options: []TranslatorOption{WithHistogramBucketExclusion(1)},
+			err:     errExclusionNoCounters,
+		},
+		{
+			name:    "reset without deltas",
+			options: []TranslatorOption{WithNumberMode(NumberModeRawValue), WithNegativeDeltaHandling(NegativeDeltaModeReset)},
+			err:     errResetModeNoDelta,
+		},
+		{
+			name: "reset with per-metric deltas",
+			options: []TranslatorOption{
+				WithNumberMode(NumberModeRawValue),
+				WithNumberModePerMetric(map[string]NumberMode{"process.*": NumberModeCumulativeToDelta}),
+				WithNegativeDeltaHandling(NegativeDeltaModeReset),
+			},
+		},
+		{
+			name: "several conflicts",
+			options: []TranslatorOption{
+				WithHistogramExcludeInfBucket(),
+				WithInstrumentationLibraryMetadataAsTags(),
+				WithInstrumentationScopeMetadataAsTags(),
+			},
+			err: strings.Join([]string{errBothMetadataAsTags, errExcludeInfNoSumCount, errExclusionNoCounters}, "; "),
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			err := ValidateOptions(testInstance.options...)
+			if testInstance.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, testInstance.err)
+			}
+		})
+	}
+}
+
+func TestHistogramBucketExclusionOptions(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithHistogramMode(HistogramModeCounters), WithHistogramExcludeInfBucket())
+	assert.EqualError(t, err, errExcludeInfNoSumCount)
+
+	_, err = NewTranslator(zap.NewNop(), WithHistogramBucketExclusion(1, math.NaN()))
+	assert.EqualError(t, err, "invalid bucket upper bound: NaN")
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithHistogramMode(HistogramModeCounters),
+		WithHistogramBucketExclusion(1),
+		WithHistogramBucketExclusion(2, math.Inf(1)),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, math.Inf(1)}, tr.Config().HistogramExcludedBucketBounds)
+}
+
+func TestValueRangeOptions(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithValueClamp(10, 0))
+	assert.EqualError(t, err, "invalid value range [10, 0]")
+
+	_, err = NewTranslator(zap.NewNop(), WithValueDropOutsideRange(math.NaN(), 0))
+	assert.EqualError(t, err, "invalid value range [NaN, 0]")
+
+	// The last option wins.
+	tr, err := NewTranslator(zap.NewNop(), WithValueDropOutsideRange(0, 1), WithValueClamp(0, math.Inf(1)))
+	require.NoError(t, err)
+	assert.Equal(t, &ValueRange{Min: 0, Max: math.Inf(1)}, tr.Config().ValueRange)
+}
+
+func TestTranslatorConfig(t *testing.T) {
+	filter, err := NewAllowListFilter("system.*")
+	require.NoError(t, err)
+	tr, err := NewTranslator(zap.NewNop(),
+		WithHistogramMode(HistogramModeCounters),
+		WithHistogramAggregations(),
+		WithNumberMode(NumberModeRawValue),
+		WithDeltaTTL(100),
+		WithMetricFilter(filter),
+		WithMaxTagCount(10),
+	)
+	require.NoError(t, err)
+
+	cfg := tr.Config()
+	assert.Equal(t, HistogramModeCounters, cfg.HistMode)
+	assert.True(t, cfg.SendHistogramCountSum)
+	assert.True(t, cfg.SendHistogramMinMax)
+	assert.False(t, cfg.SendMonotonic)
+	assert.Equal(t, SummaryModeNoBuckets, cfg.SummaryMode)
+	assert.Equal(t, int64(100), cfg.DeltaTTL)
+	assert.Equal(t, int64(50), cfg.SweepInterval)
+	assert.Equal(t, 10, cfg.MaxTagCount)
+	assert.Equal(t, filter, cfg.MetricFilter)
+	assert.IsType(t, &noSourceProvider{}, cfg.FallbackSourceProvider)
+	assert.IsType(t, &inMemoryDeltaStore{}, cfg.DeltaStore)
+}
+
+func TestTranslatorConfigIsCopy(t *testing.T) {
+	tr, err := NewTranslator(zap.NewNop(),
+		WithHistogramModePerMetric(map[string]HistogramMode{"latency.*": HistogramModeCounters}),
+		WithNumberModePerMetric(map[string]NumberMode{"queue.*": NumberModeRawValue}),
+		WithKubernetesPodLabelsAsTags(map[string]string{"app": "kube_app"}),
+		WithAttributeDenyList("user.id"),
+		WithAttributeValueAllowList(map[string][]string{"http.method": {"GET"}}),
+		WithHostTagAttributes("rack"),
+		WithGaugeToDistribution("latency.*"),
+		WithServiceCheckMapping([]ServiceCheckRule{{MetricName: "up", Min: 1, Max: 2, CheckName: "up", Status: ServiceCheckOK}}),
+		WithMetricRenameRules([]RenameRule{{Pattern: "^old$", Replacement: "new"}}),
+		WithHistogramBucketExclusion(10),
+		WithConstantTags("env:prod"),
+		WithTagKeyRenameRules(map[string]string{"old": "new"}),
+		WithValueClamp(0, 10),
+		WithDeltaTTLPerMetric(map[string]int64{"requests.*": 60}),
+		WithUnitNormalization(UnitConversionTable{"ms": {Scale: 0.001}}),
+		WithTranslationHook(func(dims *Dimensions) *Dimensions { return dims }),
+		WithAttributeMappingTable(&attributes.AttributeMappingTable{Mappings: []attributes.AttributeMapping{{OTLPKey: "team", DatadogTag: "team"}}}),
+		WithAttributeMerger(NewAttributeMerger(MergePrecedenceDatapoint)),
+	)
+	require.NoError(t, err)
+
+	expected := tr.Config()
+	cfg := tr.Config()
+	cfg.HistogramModeRules["other"] = HistogramModeNoBuckets
+	cfg.NumberModeRules["other"] = NumberModeCumulativeToDelta
+	cfg.KubernetesPodLabelsAsTags["other"] = "other"
+	cfg.AttributeDenyList[0] = "other"
+	cfg.AttributeValueAllowList["http.method"][0] = "POST"
+	cfg.HostTagAttributes[0] = "other"
+	cfg.GaugeToDistributionPatterns[0] = "other"
+	cfg.ServiceCheckRules[0].CheckName = "other"
+	cfg.MetricRenameRules[0].Replacement = "other"
+	cfg.HistogramExcludedBucketBounds[0] = 20
+	cfg.ConstantTags[0] = "env:dev"
+	cfg.TagKeyRenameRules["other"] = "other"
+	cfg.ValueRange.Max = 20
+	cfg.DeltaTTLRules["other"] = 1
+	cfg.UnitConversionTable["s"] = UnitConversion{Scale: 1000}
+	cfg.TranslationHooks[0] = nil
+	cfg.AttributeMappingTable.Mappings[0].DatadogTag = "other"
+	*cfg.AttributeMerger = NewAttributeMerger(MergePrecedenceResource)
+
+	// The translator configuration is unchanged.
+	actual := tr.Config()
+	assert.Equal(t, expected.HistogramModeRules, actual.HistogramModeRules)
+	assert.Equal(t, expected.NumberModeRules, actual.NumberModeRules)
+	assert.Equal(t, map[string]string{"app": "kube_app"}, actual.KubernetesPodLabelsAsTags)
+	assert.Equal(t, []string{"user.id"}, actual.AttributeDenyList)
+	assert.Equal(t, map[string][]string{"http.method": {"GET"}}, actual.AttributeValueAllowList)
+	assert.Equal(t, []string{"rack"}, actual.HostTagAttributes)
+	assert.Equal(t, []string{"latency.*"}, actual.GaugeToDistributionPatterns)
+	assert.Equal(t, "up", actual.ServiceCheckRules[0].CheckName)
+	assert.Equal(t, "new", actual.MetricRenameRules[0].Replacement)
+	assert.Equal(t, []float64{10}, actual.HistogramExcludedBucketBounds)
+	assert.Equal(t, expected.ConstantTags, actual.ConstantTags)
+	assert.Equal(t, expected.TagKeyRenameRules, actual.TagKeyRenameRules)
+	assert.Equal(t, &ValueRange{Min: 0, Max: 10}, actual.ValueRange)
+	assert.Equal(t, expected.DeltaTTLRules, actual.DeltaTTLRules)
+	assert.Equal(t, UnitConversionTable{"ms": {Scale: 0.001}}, actual.UnitConversionTable)
+	assert.NotNil(t, actual.TranslationHooks[0])
+	assert.Equal(t, "team", actual.AttributeMappingTable.Mappings[0].DatadogTag)
+	assert.Equal(t, MergePrecedenceDatapoint, actual.AttributeMerger.Precedence())
+}
+
+// TestTranslatorConfigFields tests that TranslatorConfig fields match those of translatorConfig.
+func TestTranslatorConfigFields(t *testing.T) {
+	fieldNames := func(typ reflect.Type) []string {
+		var fields []string
+		for i := 0; i < typ.NumField(); i++ {
+			fields = append(fields, strings.ToLower(typ.Field(i).Name))
+		}
+		return fields
+	}
+
+	assert.ElementsMatch(t, fieldNames(reflect.TypeOf(TranslatorConfig{})), fieldNames(reflect.TypeOf(translatorConfig{})),
+		"The fields on TranslatorConfig and translatorConfig are out of sync. Ensure that they have the exact same fields.")
+}
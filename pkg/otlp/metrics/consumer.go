@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes"
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
 )
 
@@ -73,6 +74,21 @@ type TimeSeriesConsumer interface {
 	)
 }
 
+// TimeSeriesIntervalConsumer is a timeseries consumer that also consumes the interval of count metrics.
+// It is an optional interface that can be implemented by a TimeSeriesConsumer.
+type TimeSeriesIntervalConsumer interface {
+	// ConsumeTimeSeriesWithInterval consumes a timeseries-style metric along with its interval,
+	// the length in seconds of the window it was aggregated over. A zero interval means that it is unknown.
+	ConsumeTimeSeriesWithInterval(
+		ctx context.Context,
+		dimensions *Dimensions,
+		typ DataType,
+		timestamp uint64,
+		interval int64,
+		value float64,
+	)
+}
+
 // SketchConsumer is a pkg/quantile sketch consumer.
 type SketchConsumer interface {
 	// ConsumeSketch consumes a pkg/quantile-style sketch.
@@ -85,6 +101,8 @@ type SketchConsumer interface {
 }
 
 // Consumer is a metrics consumer.
+// The Translator does not build any payload itself: it reports every translated gauge, count
+// and sketch to a Consumer, which decides on the output format (e.g. a Datadog API payload).
 type Consumer interface {
 	TimeSeriesConsumer
 	SketchConsumer
@@ -113,3 +131,50 @@ type TagsConsumer interface {
 	// ConsumeTag consumes a tag
 	ConsumeTag(tag string)
 }
+
+// ExemplarConsumer is an exemplar consumer.
+// It is an optional interface that can be implemented by a Consumer.
+type ExemplarConsumer interface {
+	// ConsumeExemplars consumes the exemplars of a gauge or distribution datapoint,
+	// right after the datapoint itself. The dimensions and timestamp are those of the datapoint.
+	ConsumeExemplars(
+		ctx context.Context,
+		dimensions *Dimensions,
+		timestamp uint64,
+		exemplars []Exemplar,
+	)
+}
+
+// HostMetadataConsumer is a host metadata consumer.
+// It is an optional interface that can be implemented by a Consumer.
+type HostMetadataConsumer interface {
+	// ConsumeHostMetadata consumes the system metadata of a host.
+	ConsumeHostMetadata(host string, metadata attributes.HostMetadata)
+}
+
+// ServiceCheckStatus is the status of a Datadog service check.
+type ServiceCheckStatus int
+
+const (
+	// ServiceCheckOK is the OK service check status.
+	ServiceCheckOK ServiceCheckStatus = iota
+	// ServiceCheckWarning is the WARNING service check status.
+	ServiceCheckWarning
+	// ServiceCheckCritical is the CRITICAL service check status.
+	ServiceCheckCritical
+	// ServiceCheckUnknown is the UNKNOWN service check status.
+	ServiceCheckUnknown
+)
+
+// ServiceCheckConsumer is a Datadog service check consumer.
+// It is an optional interface that can be implemented by a Consumer.
+type ServiceCheckConsumer interface {
+	// ConsumeServiceCheck consumes a service check.
+	// The name of the dimensions is the service check name.
+	ConsumeServiceCheck(
+		ctx context.Context,
+		dimensions *Dimensions,
+		timestamp uint64,
+		status ServiceCheckStatus,
+	)
+}
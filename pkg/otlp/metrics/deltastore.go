@@ -0,0 +1,255 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
+)
+
+// DeltaStore stores the last known values of cumulative timeseries, which are used to compute deltas.
+// Implementations backed by an external storage (e.g. Redis) allow these values to survive translator
+// restarts, avoiding artificially large deltas on the first points after a restart.
+//
+// Implementations must be safe for concurrent use and are responsible for expiring stale entries.
+type DeltaStore interface {
+	// Get returns the value and timestamp stored for the given key, if any.
+	Get(key string) (value float64, timestamp int64, found bool)
+	// Set stores a value and timestamp for the given key, replacing any existing entry.
+	Set(key string, value float64, timestamp int64)
+}
+
+// TTLDeltaStore is a DeltaStore that supports per-entry time to live.
+// It is an optional interface that can be implemented by a DeltaStore, and is used
+// for the timeseries matched by WithDeltaTTLPerMetric.
+type TTLDeltaStore interface {
+	// SetWithTTL works like Set, but the entry expires after ttl seconds.
+	SetWithTTL(key string, value float64, timestamp int64, ttl int64)
+}
+
+var _ DeltaStore = (*inMemoryDeltaStore)(nil)
+var _ TTLDeltaStore = (*inMemoryDeltaStore)(nil)
+var _ evictionCounter = (*inMemoryDeltaStore)(nil)
+var _ resetter = (*inMemoryDeltaStore)(nil)
+
+// inMemoryDeltaStore is the default DeltaStore. Entries expire after the configured TTL.
+type inMemoryDeltaStore struct {
+	cache *gocache.Cache
+	// writeMu is held for reading by writers and for writing by reset,
+	// so that no entry is written while the cache is being cleared.
+	writeMu sync.RWMutex
+	// evictions is the number of expired or evicted timeseries removed since the last call to takeEvictions.
+	evictions int64
+	// hits, misses and sweepEvictions are the cache statistics since the last call to takeCacheStats.
+	hits           int64
+	misses         int64
+	sweepEvictions int64
+
+	// maxSize is the maximum number of entries, or zero if unbounded.
+	// When it is exceeded, the least recently used timeseries are evicted.
+	maxSize int
+	logger  *zap.Logger
+	mu      sync.Mutex
+	// size is the number of entries of the timeseries in recent.
+	size int
+	// recent holds the timeseries, from the most to the least recently used.
+	// A timeseries and its start timestamp are evicted together, since one is meaningless without the other.
+	recent   *list.List
+	elements map[string]*list.Element
+}
+
+// recentSeries is an element of the recently used timeseries of an inMemoryDeltaStore.
+type recentSeries struct {
+	// key is the key of the timeseries.
+	key string
+	// keys are the keys of the entries of the timeseries.
+	keys map[string]struct{}
+}
+
+// seriesKey returns the key of the timeseries an entry belongs to.
+func seriesKey(key string) string {
+	return strings.TrimSuffix(key, startTsKeySuffix)
+}
+
+// storedPoint is an entry of an inMemoryDeltaStore.
+type storedPoint struct {
+	value     float64
+	timestamp int64
+}
+
+// NewInMemoryDeltaStore creates an in-memory DeltaStore whose entries expire after deltaTTL seconds.
+// Expired entries are removed every sweepInterval seconds.
+func NewInMemoryDeltaStore(sweepInterval int64, deltaTTL int64) DeltaStore {
+	return newInMemoryDeltaStore(sweepInterval, deltaTTL, 0, zap.NewNop())
+}
+
+// newInMemoryDeltaStore creates an in-memory DeltaStore holding at most maxSize entries.
+// A zero maxSize means that the number of entries is unbounded.
+func newInMemoryDeltaStore(sweepInterval int64, deltaTTL int64, maxSize int, logger *zap.Logger) *inMemoryDeltaStore {
+	cache := gocache.New(time.Duration(deltaTTL)*time.Second, time.Duration(sweepInterval)*time.Second)
+	store := &inMemoryDeltaStore{cache: cache, maxSize: maxSize, logger: logger}
+	if maxSize > 0 {
+		store.recent = list.New()
+		store.elements = make(map[string]*list.Element)
+	}
+	cache.OnEvicted(func(key string, _ interface{}) {
+		// Every timeseries has a second entry for its start timestamp.
+		if !strings.HasSuffix(key, startTsKeySuffix) {
+			atomic.AddInt64(&store.evictions, 1)
+			atomic.AddInt64(&store.sweepEvictions, 1)
+		}
+		store.forget(key)
+	})
+	return store
+}
+
+// Get implements the DeltaStore interface.
+func (s *inMemoryDeltaStore) Get(key string) (float64, int64, bool) {
+	c, found := s.cache.Get(key)
+	if !strings.HasSuffix(key, startTsKeySuffix) {
+		if found {
+			atomic.AddInt64(&s.hits, 1)
+		} else {
+			atomic.AddInt64(&s.misses, 1)
+		}
+	}
+	if found {
+		s.touch(key)
+		point := c.(storedPoint)
+		return point.value, point.timestamp, true
+	}
+	return 0, 0, false
+}
+
+// Set implements the DeltaStore interface.
+func (s *inMemoryDeltaStore) Set(key string, value float64, timestamp int64) {
+	s.writeMu.RLock()
+	defer s.writeMu.RUnlock()
+	s.cache.Set(key, storedPoint{value: value, timestamp: timestamp}, gocache.DefaultExpiration)
+	s.touch(key)
+}
+
+// SetWithTTL implements the TTLDeltaStore interface.
+func (s *inMemoryDeltaStore) SetWithTTL(key string, value float64, timestamp int64, ttl int64) {
+	s.writeMu.RLock()
+	defer s.writeMu.RUnlock()
+	s.cache.Set(key, storedPoint{value: value, timestamp: timestamp}, time.Duration(ttl)*time.Second)
+	s.touch(key)
+}
+
+// touch marks the timeseries of an entry as the most recently used, and evicts the least recently used
+// timeseries, with all their entries, if the maximum size is exceeded.
+func (s *inMemoryDeltaStore) touch(key string) {
+	if s.maxSize == 0 {
+		return
+	}
+	var evicted []string
+	s.mu.Lock()
+	series := seriesKey(key)
+	elem, ok := s.elements[series]
+	if ok {
+		s.recent.MoveToFront(elem)
+	} else {
+		elem = s.recent.PushFront(&recentSeries{key: series, keys: make(map[string]struct{}, 2)})
+		s.elements[series] = elem
+	}
+	if keys := elem.Value.(*recentSeries).keys; !hasKey(keys, key) {
+		keys[key] = struct{}{}
+		s.size++
+	}
+	// The most recently used timeseries is never evicted.
+	for s.size > s.maxSize && s.recent.Len() > 1 {
+		oldest := s.recent.Remove(s.recent.Back()).(*recentSeries)
+		delete(s.elements, oldest.key)
+		s.size -= len(oldest.keys)
+		for oldestKey := range oldest.keys {
+			evicted = append(evicted, oldestKey)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(evicted) == 0 {
+		return
+	}
+	s.logger.Warn("Delta cache is full, evicting least recently used entries",
+		zap.Int("max_size", s.maxSize),
+		zap.Int("evicted", len(evicted)),
+	)
+	// Deleting an entry calls the eviction callback, which must not be called with the lock held.
+	for _, oldest := range evicted {
+		s.cache.Delete(oldest)
+	}
+}
+
+// hasKey reports whether key is in keys.
+func hasKey(keys map[string]struct{}, key string) bool {
+	_, ok := keys[key]
+	return ok
+}
+
+// forget removes an entry removed from the cache from the recently used timeseries.
+func (s *inMemoryDeltaStore) forget(key string) {
+	if s.maxSize == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series := seriesKey(key)
+	elem, ok := s.elements[series]
+	if !ok {
+		return
+	}
+	keys := elem.Value.(*recentSeries).keys
+	if !hasKey(keys, key) {
+		return
+	}
+	delete(keys, key)
+	s.size--
+	if len(keys) == 0 {
+		s.recent.Remove(elem)
+		delete(s.elements, series)
+	}
+}
+
+// takeEvictions implements the evictionCounter interface.
+func (s *inMemoryDeltaStore) takeEvictions() int {
+	return int(atomic.SwapInt64(&s.evictions, 0))
+}
+
+// reset implements the resetter interface.
+// Only timeseries keys are counted: start timestamps and the running totals of WithNormalizeSumTemporality are not.
+func (s *inMemoryDeltaStore) reset() int {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	n := 0
+	for key := range s.cache.Items() {
+		if !strings.HasSuffix(key, startTsKeySuffix) && !strings.HasSuffix(key, accumulatedKeySuffix) {
+			n++
+		}
+	}
+	// Flushing the cache doesn't call the eviction callback.
+	s.cache.Flush()
+	if s.maxSize > 0 {
+		s.mu.Lock()
+		s.recent.Init()
+		s.elements = make(map[string]*list.Element)
+		s.size = 0
+		s.mu.Unlock()
+	}
+
+	atomic.StoreInt64(&s.evictions, 0)
+	atomic.StoreInt64(&s.hits, 0)
+	atomic.StoreInt64(&s.misses, 0)
+	atomic.StoreInt64(&s.sweepEvictions, 0)
+	return n
+}
@@ -0,0 +1,279 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ DeltaStore = (*mapDeltaStore)(nil)
+
+// mapDeltaStore is a reference DeltaStore implementation. A store backed by an external storage
+// (e.g. Redis) would read and write entries from it instead of a map, and set their expiration.
+type mapDeltaStore struct {
+	mu     sync.Mutex
+	points map[string]storedPoint
+}
+
+func newMapDeltaStore() *mapDeltaStore {
+	return &mapDeltaStore{points: make(map[string]storedPoint)}
+}
+
+func (s *mapDeltaStore) Get(key string) (float64, int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	point, found := s.points[key]
+	return point.value, point.timestamp, found
+}
+
+func (s *mapDeltaStore) Set(key string, value float64, timestamp int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.points[key] = storedPoint{value: value, timestamp: timestamp}
+}
+
+func ExampleWithDeltaStore() {
+	tr, err := NewTranslator(zap.NewNop(), WithDeltaStore(newMapDeltaStore()))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%T\n", tr.Config().DeltaStore)
+	// Output: *metrics.mapDeltaStore
+}
+
+func TestInMemoryDeltaStore(t *testing.T) {
+	store := NewInMemoryDeltaStore(1800, 3600)
+
+	_, _, found := store.Get("key")
+	assert.False(t, found)
+
+	store.Set("key", 1.5, 10)
+	value, ts, found := store.Get("key")
+	assert.True(t, found)
+	assert.Equal(t, 1.5, value)
+	assert.Equal(t, int64(10), ts)
+
+	store.Set("key", 2.5, 20)
+	value, ts, found = store.Get("key")
+	assert.True(t, found)
+	assert.Equal(t, 2.5, value)
+	assert.Equal(t, int64(20), ts)
+}
+
+func TestInMemoryDeltaStoreSetWithTTL(t *testing.T) {
+	store := NewInMemoryDeltaStore(1800, 3600)
+	before := time.Now()
+	store.Set("default", 1, 10)
+	store.(TTLDeltaStore).SetWithTTL("short", 2, 20, 60)
+
+	items := store.(*inMemoryDeltaStore).cache.Items()
+	assert.WithinDuration(t, before.Add(time.Hour), time.Unix(0, items["default"].Expiration), time.Minute)
+	assert.WithinDuration(t, before.Add(time.Minute), time.Unix(0, items["short"].Expiration), 30*time.Second)
+	value, ts, found := store.Get("short")
+	assert.True(t, found)
+	assert.Equal(t, 2.0, value)
+	assert.Equal(t, int64(20), ts)
+}
+
+// ttlDeltaStore is a mapDeltaStore that records the TTL of its entries.
+type ttlDeltaStore struct {
+	*mapDeltaStore
+	ttls map[string]int64
+}
+
+func (s *ttlDeltaStore) SetWithTTL(key string, value float64, timestamp int64, ttl int64) {
+	s.Set(key, value, timestamp)
+	s.ttls[key] = ttl
+}
+
+func TestDeltaTTLPerMetric(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"http.server.requests", "http.server.errors", "db.queries"} {
+		met := metrics.AppendEmpty()
+		met.SetName(name)
+		sum := met.SetEmptySum()
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		sum.SetIsMonotonic(true)
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(seconds(1))
+		dp.SetTimestamp(seconds(2))
+		dp.SetIntValue(10)
+	}
+
+	store := &ttlDeltaStore{mapDeltaStore: newMapDeltaStore(), ttls: make(map[string]int64)}
+	tr, err := NewTranslator(zap.NewNop(),
+		WithDeltaStore(store),
+		WithDeltaTTL(7200),
+		WithDeltaTTLPerMetric(map[string]int64{"http.server.*": 60}),
+		WithDeltaTTLPerMetric(map[string]int64{"http.server.errors": 600}),
+	)
+	require.NoError(t, err)
+	_, err = tr.MapMetrics(context.Background(), md, &mockFullConsumer{})
+	require.NoError(t, err)
+
+	ttls := make(map[string]int64)
+	for key, ttl := range store.ttls {
+		for _, dim := range strings.Split(key, dimensionSeparator) {
+			if strings.HasPrefix(dim, "name:") {
+				ttls[strings.TrimPrefix(dim, "name:")] = ttl
+			}
+		}
+	}
+	// db.queries does not match any rule, and uses the global TTL set on the store.
+	assert.Equal(t, map[string]int64{"http.server.requests": 60, "http.server.errors": 600}, ttls)
+	assert.Len(t, store.points, 6)
+}
+
+func TestWithDeltaTTLPerMetricInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithDeltaTTLPerMetric(map[string]int64{"http.*": 0}))
+	assert.EqualError(t, err, `time to live for "http.*" must be positive: 0`)
+
+	_, err = NewTranslator(zap.NewNop(), WithDeltaTTLPerMetric(map[string]int64{"http.[": 60}))
+	assert.ErrorContains(t, err, `invalid pattern "http.["`)
+}
+
+func TestWithDeltaStoreNil(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithDeltaStore(nil))
+	assert.EqualError(t, err, "delta store must not be nil")
+}
+
+func TestMapMetricsDeltaStoreSurvivesRestart(t *testing.T) {
+	newMetrics := func(startTs, ts int, val int64) pmetric.Metrics {
+		md := pmetric.NewMetrics()
+		met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		met.SetName("int.cumulative.monotonic.sum")
+		sum := met.SetEmptySum()
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		sum.SetIsMonotonic(true)
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(seconds(startTs))
+		dp.SetTimestamp(seconds(ts))
+		dp.SetIntValue(val)
+		return md
+	}
+
+	store := newMapDeltaStore()
+	ctx := context.Background()
+
+	tr, err := NewTranslator(zap.NewNop(), WithDeltaStore(store))
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(ctx, newMetrics(1, 2, 10), consumer)
+	require.NoError(t, err)
+	assert.Empty(t, consumer.metrics)
+
+	// Simulate a restart: a new translator with the same store computes deltas from the stored values.
+	tr, err = NewTranslator(zap.NewNop(), WithDeltaStore(store))
+	require.NoError(t, err)
+	consumer = &mockFullConsumer{}
+	_, err = tr.MapMetrics(ctx, newMetrics(1, 3, 15), consumer)
+	require.NoError(t, err)
+	require.Len(t, consumer.metrics, 1)
+	assert.Equal(t, 5.0, consumer.metrics[0].value)
+
+	// A different start timestamp is still detected as a new timeseries.
+	consumer = &mockFullConsumer{}
+	_, err = tr.MapMetrics(ctx, newMetrics(4, 5, 20), consumer)
+	require.NoError(t, err)
+	assert.Empty(t, consumer.metrics)
+}
+
+func TestInMemoryDeltaStoreMaxSize(t *testing.T) {
+	core, observed := observer.New(zapcore.DebugLevel)
+	store := newInMemoryDeltaStore(1800, 3600, 2, zap.New(core))
+
+	store.Set("a", 1, 10)
+	store.Set("b", 2, 10)
+	// Reading "a" makes "b" the least recently used entry.
+	_, _, found := store.Get("a")
+	require.True(t, found)
+	store.Set("c", 3, 10)
+
+	_, _, found = store.Get("b")
+	assert.False(t, found)
+	_, _, found = store.Get("a")
+	assert.True(t, found)
+	_, _, found = store.Get("c")
+	assert.True(t, found)
+	assert.Equal(t, 2, store.cache.ItemCount())
+	assert.Equal(t, 1, store.takeEvictions())
+	assert.Equal(t, 1, observed.FilterMessage("Delta cache is full, evicting least recently used entries").Len())
+}
+
+func TestInMemoryDeltaStoreMaxSizeEvictsTimeseries(t *testing.T) {
+	store := newInMemoryDeltaStore(1800, 3600, 3, zap.NewNop())
+	cache := newTTLCacheWithStore(store)
+	dimsA := &Dimensions{name: "a"}
+	dimsB := &Dimensions{name: "b"}
+
+	_, ok, reset, _, _ := cache.MonotonicDiffWithReset(dimsA, 1, 2, 10, false)
+	assert.False(t, ok, "expected no diff: first point")
+	assert.False(t, reset)
+	// Storing "b" exceeds the maximum size: "a" is evicted along with its start timestamp.
+	_, ok, reset, _, _ = cache.MonotonicDiffWithReset(dimsB, 1, 2, 10, false)
+	assert.False(t, ok, "expected no diff: first point")
+	assert.False(t, reset)
+	assert.Equal(t, 2, store.cache.ItemCount())
+	_, found := store.cache.Get(dimsA.String() + startTsKeySuffix)
+	assert.False(t, found)
+
+	// The next point of "a" is a first point rather than a reset.
+	_, ok, reset, _, _ = cache.MonotonicDiffWithReset(dimsA, 1, 3, 15, false)
+	assert.False(t, ok, "expected no diff: first point after eviction")
+	assert.False(t, reset, "expected no reset: the start timestamp was evicted with the value")
+	assert.Equal(t, 2, store.cache.ItemCount())
+	assert.Equal(t, 2, store.size)
+	assert.Equal(t, 1, store.recent.Len())
+	assert.Equal(t, 2, store.takeEvictions())
+}
+
+func TestMapMetricsDeltaCacheMaxSize(t *testing.T) {
+	md := pmetric.NewMetrics()
+	met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("requests")
+	met.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	met.Sum().SetIsMonotonic(true)
+	for i := 0; i < 100; i++ {
+		dp := met.Sum().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetIntValue(1)
+		dp.Attributes().PutInt("request_id", int64(i))
+	}
+
+	tr, err := NewTranslator(zap.NewNop(), WithDeltaCacheMaxSize(10))
+	require.NoError(t, err)
+	assert.Equal(t, 10, tr.Config().DeltaCacheMaxSize)
+	metadata, err := tr.MapMetrics(context.Background(), md, &mockFullConsumer{})
+	require.NoError(t, err)
+
+	// Each timeseries uses two entries: only the last 5 timeseries are kept.
+	store := tr.prevPts.store.(*inMemoryDeltaStore)
+	assert.Equal(t, 10, store.cache.ItemCount())
+	assert.Equal(t, 10, store.size)
+	assert.Equal(t, 5, store.recent.Len())
+	assert.Equal(t, 95, metadata.Stats.CacheEvictions)
+}
+
+func TestWithDeltaCacheMaxSizeInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithDeltaCacheMaxSize(0))
+	assert.EqualError(t, err, "delta cache max size must be positive: 0")
+
+	_, err = NewTranslator(zap.NewNop(), WithDeltaCacheMaxSize(10), WithDeltaStore(newMapDeltaStore()))
+	assert.EqualError(t, err, errMaxSizeDeltaStore)
+}
@@ -35,7 +35,11 @@ type Dimensions struct {
 	name     string
 	tags     []string
 	host     string
+	hostTags []string
 	originID string
+	// scopeVersion is the version of the instrumentation scope. It is part of the identifier of the
+	// timeseries, so that cumulative metrics are handled as new timeseries when the SDK is reconfigured.
+	scopeVersion string
 }
 
 // Name of the metric.
@@ -53,11 +57,21 @@ func (d *Dimensions) Host() string {
 	return d.host
 }
 
+// HostTags of the metric (read-only). Host tags are associated to the host rather than to the metric datapoint.
+func (d *Dimensions) HostTags() []string {
+	return d.hostTags
+}
+
 // OriginID of the metric (may be empty).
 func (d *Dimensions) OriginID() string {
 	return d.originID
 }
 
+// ScopeVersion of the instrumentation scope of the metric (may be empty).
+func (d *Dimensions) ScopeVersion() string {
+	return d.scopeVersion
+}
+
 // getTags maps an attributeMap into a slice of Datadog tags
 func getTags(labels pcommon.Map) []string {
 	tags := make([]string, 0, labels.Len())
@@ -76,10 +90,27 @@ func (d *Dimensions) AddTags(tags ...string) *Dimensions {
 	newTags = append(newTags, tags...)
 	newTags = append(newTags, d.tags...)
 	return &Dimensions{
-		name:     d.name,
-		tags:     newTags,
-		host:     d.host,
-		originID: d.originID,
+		name:         d.name,
+		tags:         newTags,
+		host:         d.host,
+		hostTags:     d.hostTags,
+		originID:     d.originID,
+		scopeVersion: d.scopeVersion,
+	}
+}
+
+// addHostTags creates a new dimensions struct with additional host tags.
+func (d *Dimensions) addHostTags(hostTags ...string) *Dimensions {
+	newHostTags := make([]string, 0, len(hostTags)+len(d.hostTags))
+	newHostTags = append(newHostTags, d.hostTags...)
+	newHostTags = append(newHostTags, hostTags...)
+	return &Dimensions{
+		name:         d.name,
+		tags:         d.tags,
+		host:         d.host,
+		hostTags:     newHostTags,
+		originID:     d.originID,
+		scopeVersion: d.scopeVersion,
 	}
 }
 
@@ -88,13 +119,27 @@ func (d *Dimensions) WithAttributeMap(labels pcommon.Map) *Dimensions {
 	return d.AddTags(getTags(labels)...)
 }
 
+// WithName creates a new dimensions struct with the given name.
+func (d *Dimensions) WithName(name string) *Dimensions {
+	return &Dimensions{
+		name:         name,
+		host:         d.host,
+		tags:         d.tags,
+		hostTags:     d.hostTags,
+		originID:     d.originID,
+		scopeVersion: d.scopeVersion,
+	}
+}
+
 // WithSuffix creates a new dimensions struct with an extra name suffix.
 func (d *Dimensions) WithSuffix(suffix string) *Dimensions {
 	return &Dimensions{
-		name:     fmt.Sprintf("%s.%s", d.name, suffix),
-		host:     d.host,
-		tags:     d.tags,
-		originID: d.originID,
+		name:         fmt.Sprintf("%s.%s", d.name, suffix),
+		host:         d.host,
+		tags:         d.tags,
+		hostTags:     d.hostTags,
+		originID:     d.originID,
+		scopeVersion: d.scopeVersion,
 	}
 }
 
@@ -114,9 +159,15 @@ func (d *Dimensions) String() string {
 	dimensions := make([]string, len(d.tags))
 	copy(dimensions, d.tags)
 
+	for _, hostTag := range d.hostTags {
+		dimensions = append(dimensions, fmt.Sprintf("hostTag:%s", hostTag))
+	}
 	dimensions = append(dimensions, fmt.Sprintf("name:%s", d.name))
 	dimensions = append(dimensions, fmt.Sprintf("host:%s", d.host))
 	dimensions = append(dimensions, fmt.Sprintf("originID:%s", d.originID))
+	if d.scopeVersion != "" {
+		dimensions = append(dimensions, fmt.Sprintf("scopeVersion:%s", d.scopeVersion))
+	}
 	sort.Strings(dimensions)
 
 	for _, dim := range dimensions {
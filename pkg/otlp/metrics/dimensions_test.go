@@ -54,6 +54,12 @@ func TestMetricDimensionsString(t *testing.T) {
 	assert.NotEqual(t, someTags, diffTags)
 	assert.Equal(t, someTags, sameTags)
 	assert.NotEqual(t, someTags, diffHost)
+
+	withHostTags := Dimensions{name: metricName, tags: []string{"key1:val1"}, hostTags: []string{"key2:val2"}}
+	assert.NotEqual(t, someTags, withHostTags.String())
+
+	withScopeVersion := Dimensions{name: metricName, tags: []string{"key1:val1", "key2:val2"}, host: hostOne, scopeVersion: "1.0.0"}
+	assert.NotEqual(t, someTags, withScopeVersion.String())
 }
 
 func TestMetricDimensionsStringNoTagsChange(t *testing.T) {
@@ -102,10 +108,12 @@ func TestAddTags(t *testing.T) {
 
 func TestAllFieldsAreCopied(t *testing.T) {
 	dims := &Dimensions{
-		name:     "example.name",
-		host:     "hostname",
-		tags:     []string{"tagOne:a", "tagTwo:b"},
-		originID: "origin_id",
+		name:         "example.name",
+		host:         "hostname",
+		tags:         []string{"tagOne:a", "tagTwo:b"},
+		hostTags:     []string{"hostTag:a"},
+		originID:     "origin_id",
+		scopeVersion: "1.0.0",
 	}
 
 	attributes := pcommon.NewMap()
@@ -115,10 +123,13 @@ func TestAllFieldsAreCopied(t *testing.T) {
 	newDims := dims.
 		AddTags("tagThree:c").
 		WithSuffix("suffix").
-		WithAttributeMap(attributes)
+		WithAttributeMap(attributes).
+		addHostTags("hostTag:b")
 
 	assert.Equal(t, "example.name.suffix", newDims.Name())
 	assert.Equal(t, "hostname", newDims.Host())
 	assert.ElementsMatch(t, []string{"tagOne:a", "tagTwo:b", "tagThree:c", "tagFour:d"}, newDims.Tags())
+	assert.ElementsMatch(t, []string{"hostTag:a", "hostTag:b"}, newDims.HostTags())
 	assert.Equal(t, "origin_id", newDims.OriginID())
+	assert.Equal(t, "1.0.0", newDims.ScopeVersion())
 }
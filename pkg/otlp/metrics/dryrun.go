@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+)
+
+var _ Consumer = (*dryRunConsumer)(nil)
+var _ TimeSeriesIntervalConsumer = (*dryRunConsumer)(nil)
+var _ ServiceCheckConsumer = (*dryRunConsumer)(nil)
+var _ ExemplarConsumer = (*dryRunConsumer)(nil)
+
+// dryRunConsumer is the Consumer used in dry run mode.
+// It discards the translated metrics and only counts them in the stats of the translation.
+type dryRunConsumer struct {
+	stats *TranslatorStats
+}
+
+// ConsumeTimeSeries implements the TimeSeriesConsumer interface.
+func (c *dryRunConsumer) ConsumeTimeSeries(_ context.Context, _ *Dimensions, _ DataType, _ uint64, _ float64) {
+	c.stats.TimeSeries++
+}
+
+// ConsumeTimeSeriesWithInterval implements the TimeSeriesIntervalConsumer interface.
+func (c *dryRunConsumer) ConsumeTimeSeriesWithInterval(_ context.Context, _ *Dimensions, _ DataType, _ uint64, _ int64, _ float64) {
+	c.stats.TimeSeries++
+}
+
+// ConsumeSketch implements the SketchConsumer interface.
+func (c *dryRunConsumer) ConsumeSketch(_ context.Context, _ *Dimensions, _ uint64, _ *quantile.Sketch) {
+	c.stats.Sketches++
+}
+
+// ConsumeServiceCheck implements the ServiceCheckConsumer interface.
+func (c *dryRunConsumer) ConsumeServiceCheck(_ context.Context, _ *Dimensions, _ uint64, _ ServiceCheckStatus) {
+	c.stats.ServiceChecks++
+}
+
+// ConsumeExemplars implements the ExemplarConsumer interface.
+func (c *dryRunConsumer) ConsumeExemplars(_ context.Context, _ *Dimensions, _ uint64, exemplars []Exemplar) {
+	c.stats.Exemplars += len(exemplars)
+}
+
+// ConsumeAPMStats implements the APMStatsConsumer interface.
+func (c *dryRunConsumer) ConsumeAPMStats(_ *pb.ClientStatsPayload) {
+	c.stats.APMStats++
+}
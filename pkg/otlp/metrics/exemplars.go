@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"encoding/binary"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Exemplar is a sample measurement of a translated metric, linked to the trace it was recorded in.
+type Exemplar struct {
+	// TraceID is the ID of the trace the measurement was recorded in.
+	TraceID pcommon.TraceID
+	// SpanID is the ID of the span the measurement was recorded in.
+	SpanID pcommon.SpanID
+	// Timestamp is the time of the measurement, in nanoseconds since epoch.
+	Timestamp uint64
+	// Value is the measured value.
+	Value float64
+	// Tags are the attributes of the measurement that are not attributes of the datapoint.
+	Tags []string
+}
+
+const (
+	// apmTraceIDTag and apmSpanIDTag are the tags linking an exemplar to a Datadog APM trace and span.
+	apmTraceIDTag = "_dd.trace_id"
+	apmSpanIDTag  = "_dd.span_id"
+)
+
+// apmLinkTags returns the tags linking an exemplar to its Datadog APM trace and span.
+// Datadog APM identifies traces by the lower 64 bits of the OpenTelemetry trace ID.
+func apmLinkTags(traceID pcommon.TraceID, spanID pcommon.SpanID) []string {
+	tags := []string{apmTraceIDTag + ":" + strconv.FormatUint(binary.BigEndian.Uint64(traceID[8:]), 10)}
+	if !spanID.IsEmpty() {
+		tags = append(tags, apmSpanIDTag+":"+strconv.FormatUint(binary.BigEndian.Uint64(spanID[:]), 10))
+	}
+	return tags
+}
+
+// exemplars returns the exemplars of a datapoint that have a trace context.
+// Values are multiplied by the given scale. If apmLink is set, the tags linking the exemplars
+// to Datadog APM are added.
+func exemplars(slice pmetric.ExemplarSlice, scale float64, apmLink bool) []Exemplar {
+	var exs []Exemplar
+	for i := 0; i < slice.Len(); i++ {
+		e := slice.At(i)
+		if e.TraceID().IsEmpty() {
+			continue
+		}
+		var val float64
+		switch e.ValueType() {
+		case pmetric.ExemplarValueTypeDouble:
+			val = e.DoubleValue()
+		case pmetric.ExemplarValueTypeInt:
+			val = float64(e.IntValue())
+		}
+		tags := getTags(e.FilteredAttributes())
+		if apmLink {
+			tags = append(tags, apmLinkTags(e.TraceID(), e.SpanID())...)
+		}
+		exs = append(exs, Exemplar{
+			TraceID:   e.TraceID(),
+			SpanID:    e.SpanID(),
+			Timestamp: uint64(e.Timestamp()),
+			Value:     val * scale,
+			Tags:      tags,
+		})
+	}
+	return exs
+}
+
+// mapExemplars passes the exemplars of a datapoint with a trace context to consumers implementing
+// ExemplarConsumer, if exemplar translation is enabled.
+func (t *Translator) mapExemplars(
+	ctx context.Context,
+	consumer interface{},
+	dims *Dimensions,
+	timestamp uint64,
+	slice pmetric.ExemplarSlice,
+	scale float64,
+) {
+	if !t.cfg.ExemplarTranslation || slice.Len() == 0 {
+		return
+	}
+	if _, ok := consumer.(ExemplarConsumer); !ok {
+		return
+	}
+	if exs := exemplars(slice, scale, t.cfg.ExemplarAPMLink); len(exs) > 0 {
+		consumeExemplars(ctx, consumer, dims, timestamp, exs)
+	}
+}
+
+// consumeExemplars passes exemplars to the consumer if it implements ExemplarConsumer.
+func consumeExemplars(
+	ctx context.Context,
+	consumer interface{},
+	dims *Dimensions,
+	timestamp uint64,
+	exemplars []Exemplar,
+) {
+	if c, ok := consumer.(ExemplarConsumer); ok {
+		c.ConsumeExemplars(ctx, dims, timestamp, exemplars)
+	}
+}
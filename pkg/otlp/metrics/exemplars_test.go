@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+type exemplarsRecord struct {
+	dims      *Dimensions
+	exemplars []Exemplar
+}
+
+type mockExemplarConsumer struct {
+	mockFullConsumer
+	exemplars []exemplarsRecord
+}
+
+func (c *mockExemplarConsumer) ConsumeExemplars(_ context.Context, dims *Dimensions, _ uint64, exemplars []Exemplar) {
+	c.exemplars = append(c.exemplars, exemplarsRecord{dims: dims, exemplars: exemplars})
+}
+
+func TestMapMetricsExemplarsConsumerWrappers(t *testing.T) {
+	md := pmetric.NewMetrics()
+	met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("request.duration")
+	met.SetUnit("ms")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(2))
+	dp.SetDoubleValue(1500)
+	ex := dp.Exemplars().AppendEmpty()
+	ex.SetTimestamp(seconds(1))
+	ex.SetIntValue(1500)
+	ex.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	ex.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithExemplarTranslation(),
+		WithUnitNormalization(UnitConversionTable{"ms": {Scale: 0.001}}),
+		WithMetricRenameRules([]RenameRule{{Pattern: `^request\.duration$`, Replacement: "request.latency"}}),
+		WithTranslationHook(func(dims *Dimensions) *Dimensions { return dims.AddTags("team:payments") }),
+	)
+	require.NoError(t, err)
+	consumer := &mockExemplarConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	// Exemplars go through the same renaming, hooks and unit conversion as their datapoint.
+	require.Len(t, consumer.metrics, 1)
+	require.Len(t, consumer.exemplars, 1)
+	assert.Equal(t, consumer.metrics[0].name, consumer.exemplars[0].dims.name)
+	assert.Equal(t, consumer.metrics[0].tags, consumer.exemplars[0].dims.tags)
+	assert.Equal(t, []Exemplar{{
+		TraceID:   pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:    pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		Timestamp: uint64(seconds(1)),
+		Value:     1.5,
+		Tags:      []string{},
+	}}, consumer.exemplars[0].exemplars)
+}
+
+func TestMapMetricsExemplarToAPMLink(t *testing.T) {
+	md := pmetric.NewMetrics()
+	met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("request.duration")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(2))
+	dp.SetDoubleValue(1)
+	ex := dp.Exemplars().AppendEmpty()
+	ex.SetTimestamp(seconds(1))
+	ex.SetDoubleValue(1)
+	ex.FilteredAttributes().PutStr("user", "alice")
+	ex.SetTraceID(pcommon.TraceID{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1, 0})
+	ex.SetSpanID(pcommon.SpanID{0, 0, 0, 0, 0, 0, 0, 42})
+	// Exemplars without a span ID are only linked to their trace.
+	ex = dp.Exemplars().AppendEmpty()
+	ex.SetTimestamp(seconds(1))
+	ex.SetDoubleValue(2)
+	ex.SetTraceID(pcommon.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 7})
+
+	tr, err := NewTranslator(zap.NewNop(), WithExemplarToAPMLink())
+	require.NoError(t, err)
+	assert.True(t, tr.Config().ExemplarTranslation)
+	assert.True(t, tr.Config().ExemplarAPMLink)
+	consumer := &mockExemplarConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	// The metric tags are unchanged: the links are only set on the exemplars.
+	require.Len(t, consumer.metrics, 1)
+	assert.Empty(t, consumer.metrics[0].tags)
+	require.Len(t, consumer.exemplars, 1)
+	exs := consumer.exemplars[0].exemplars
+	require.Len(t, exs, 2)
+	assert.Equal(t, []string{"user:alice", "_dd.trace_id:256", "_dd.span_id:42"}, exs[0].Tags)
+	assert.Equal(t, []string{"_dd.trace_id:7"}, exs[1].Tags)
+}
@@ -93,14 +93,21 @@ func (t *Translator) mapExponentialHistogramMetrics(
 		p := slice.At(i)
 		startTs := uint64(p.StartTimestamp())
 		ts := uint64(p.Timestamp())
-		pointDims := dims.WithAttributeMap(p.Attributes())
+		pointDims := t.pointDimensions(ctx, dims, p.Attributes())
+		if t.isStale(ctx, pointDims.name, ts) {
+			continue
+		}
+		if p.Flags().NoRecordedValue() {
+			t.logger.Debug(noRecordedValueMessage, zap.String(metricName, pointDims.name))
+			continue
+		}
 
 		histInfo := histogramInfo{ok: true}
 
 		countDims := pointDims.WithSuffix("count")
 		if delta {
 			histInfo.count = p.Count()
-		} else if dx, ok := t.prevPts.Diff(countDims, startTs, ts, float64(p.Count())); ok {
+		} else if dx, ok := t.cumulativeDiff(countDims, false, startTs, ts, float64(p.Count())); ok {
 			histInfo.count = uint64(dx)
 		} else { // not ok
 			histInfo.ok = false
@@ -110,7 +117,7 @@ func (t *Translator) mapExponentialHistogramMetrics(
 		if !t.isSkippable(sumDims.name, p.Sum()) {
 			if delta {
 				histInfo.sum = p.Sum()
-			} else if dx, ok := t.prevPts.Diff(sumDims, startTs, ts, p.Sum()); ok {
+			} else if dx, ok := t.cumulativeDiff(sumDims, false, startTs, ts, p.Sum()); ok {
 				histInfo.sum = dx
 			} else { // not ok
 				histInfo.ok = false
@@ -119,12 +126,14 @@ func (t *Translator) mapExponentialHistogramMetrics(
 			histInfo.ok = false
 		}
 
-		if t.cfg.SendHistogramAggregations && histInfo.ok {
+		if histInfo.ok {
 			// We only send the sum and count if both values were ok.
-			consumer.ConsumeTimeSeries(ctx, countDims, Count, ts, float64(histInfo.count))
-			consumer.ConsumeTimeSeries(ctx, sumDims, Count, ts, histInfo.sum)
+			if t.cfg.SendHistogramCountSum {
+				consumer.ConsumeTimeSeries(ctx, countDims, Count, ts, float64(histInfo.count))
+				consumer.ConsumeTimeSeries(ctx, sumDims, Count, ts, histInfo.sum)
+			}
 
-			if delta {
+			if t.cfg.SendHistogramMinMax && delta {
 				if p.HasMin() {
 					minDims := pointDims.WithSuffix("min")
 					consumer.ConsumeTimeSeries(ctx, minDims, Gauge, ts, p.Min())
@@ -167,5 +176,6 @@ func (t *Translator) mapExponentialHistogramMetrics(
 		}
 
 		consumer.ConsumeSketch(ctx, pointDims, ts, agentSketch)
+		t.mapExemplars(ctx, consumer, pointDims, ts, p.Exemplars(), 1)
 	}
 }
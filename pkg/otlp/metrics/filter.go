@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"fmt"
+	"path"
+)
+
+// MetricFilter decides which OTLP metrics are translated.
+type MetricFilter interface {
+	// Include returns true if the metric with the given OTLP name must be translated.
+	Include(metricName string) bool
+}
+
+var (
+	_ MetricFilter = (*AllowListFilter)(nil)
+	_ MetricFilter = (*DenyListFilter)(nil)
+)
+
+// AllowListFilter is a MetricFilter that only includes metrics matching one of its patterns.
+type AllowListFilter struct {
+	patterns []string
+}
+
+// NewAllowListFilter creates a filter that only includes metrics matching one of the given patterns.
+// Patterns are either exact metric names or glob patterns following the path.Match syntax (e.g. "system.cpu.*").
+func NewAllowListFilter(patterns ...string) (*AllowListFilter, error) {
+	if err := validatePatterns(patterns); err != nil {
+		return nil, err
+	}
+	return &AllowListFilter{patterns: patterns}, nil
+}
+
+// Include implements the MetricFilter interface.
+func (f *AllowListFilter) Include(metricName string) bool {
+	return matchesAnyPattern(f.patterns, metricName)
+}
+
+// DenyListFilter is a MetricFilter that excludes metrics matching one of its patterns.
+type DenyListFilter struct {
+	patterns []string
+}
+
+// NewDenyListFilter creates a filter that excludes metrics matching one of the given patterns.
+// Patterns are either exact metric names or glob patterns following the path.Match syntax (e.g. "system.cpu.*").
+func NewDenyListFilter(patterns ...string) (*DenyListFilter, error) {
+	if err := validatePatterns(patterns); err != nil {
+		return nil, err
+	}
+	return &DenyListFilter{patterns: patterns}, nil
+}
+
+// Include implements the MetricFilter interface.
+func (f *DenyListFilter) Include(metricName string) bool {
+	return !matchesAnyPattern(f.patterns, metricName)
+}
+
+// validatePatterns checks that all patterns are valid glob patterns.
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesPattern checks if a name matches a pattern, either exactly or as a glob pattern.
+// Patterns are assumed to have been validated by validatePatterns.
+func matchesPattern(pattern, name string) bool {
+	if pattern == name {
+		return true
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// matchesAnyPattern checks if a name matches any of the given patterns.
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchesPattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupPatternRule returns the value of the rule for the given name. Rule keys are either exact names
+// or glob patterns: an exact name takes precedence over patterns, and among matching patterns, the longest one wins.
+func lookupPatternRule[V any](rules map[string]V, name string) (V, bool) {
+	if value, ok := rules[name]; ok {
+		return value, true
+	}
+	var best string
+	found := false
+	for pattern := range rules {
+		if !matchesPattern(pattern, name) {
+			continue
+		}
+		if !found || len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best, found = pattern, true
+		}
+	}
+	if !found {
+		var zero V
+		return zero, false
+	}
+	return rules[best], true
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMetricFilters(t *testing.T) {
+	allow, err := NewAllowListFilter("system.cpu.time", "process.*")
+	require.NoError(t, err)
+	deny, err := NewDenyListFilter("system.cpu.time", "process.*")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		allowed bool
+	}{
+		{name: "system.cpu.time", allowed: true},
+		{name: "system.cpu.utilization", allowed: false},
+		{name: "process.memory.usage", allowed: true},
+		{name: "processes.count", allowed: false},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			assert.Equal(t, testInstance.allowed, allow.Include(testInstance.name))
+			assert.Equal(t, !testInstance.allowed, deny.Include(testInstance.name))
+		})
+	}
+}
+
+func TestMetricFiltersInvalidPattern(t *testing.T) {
+	_, err := NewAllowListFilter("system.[cpu")
+	assert.EqualError(t, err, `invalid pattern "system.[cpu": syntax error in pattern`)
+	_, err = NewDenyListFilter("system.[cpu")
+	assert.EqualError(t, err, `invalid pattern "system.[cpu": syntax error in pattern`)
+
+	_, err = NewTranslator(zap.NewNop(), WithMetricFilter(nil))
+	assert.EqualError(t, err, "metric filter must not be nil")
+}
+
+func TestMapMetricsWithFilter(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"system.cpu.time", "system.memory.usage", "process.cpu.time"} {
+		met := metricsArray.AppendEmpty()
+		met.SetName(name)
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(1)
+	}
+
+	filter, err := NewDenyListFilter("system.*")
+	require.NoError(t, err)
+	core, observed := observer.New(zapcore.DebugLevel)
+	tr, err := NewTranslator(zap.New(core), WithMetricFilter(filter))
+	require.NoError(t, err)
+
+	consumer := &mockFullConsumer{}
+	metadata, err := tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	require.Len(t, consumer.metrics, 1)
+	assert.Equal(t, "process.cpu.time", consumer.metrics[0].name)
+	assert.Equal(t, 2, observed.FilterMessage("Metric excluded by filter").Len())
+	// Excluded metrics are also counted as unsupported, to help debugging misconfigured filters.
+	assert.Equal(t, 2, metadata.Stats.DroppedMetrics)
+	assert.Equal(t, 2, metadata.Stats.UnsupportedMetricTypes)
+}
+
+func TestLookupPatternRule(t *testing.T) {
+	rules := map[string]HistogramMode{
+		"http.*":               HistogramModeCounters,
+		"http.server.*":        HistogramModeNoBuckets,
+		"*.duration":           HistogramModeDistributions,
+		"http.server.duration": HistogramModeCounters,
+	}
+
+	tests := []struct {
+		name     string
+		expected HistogramMode
+		found    bool
+	}{
+		{name: "http.server.duration", expected: HistogramModeCounters, found: true},
+		{name: "http.server.request.size", expected: HistogramModeNoBuckets, found: true},
+		{name: "http.client.duration", expected: HistogramModeDistributions, found: true},
+		{name: "http.client.request.size", expected: HistogramModeCounters, found: true},
+		{name: "db.duration", expected: HistogramModeDistributions, found: true},
+		{name: "db.size"},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			mode, found := lookupPatternRule(rules, testInstance.name)
+			assert.Equal(t, testInstance.found, found)
+			assert.Equal(t, testInstance.expected, mode)
+		})
+	}
+}
@@ -6,6 +6,7 @@
 package metrics
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,6 +67,85 @@ func TestDeltaHistogramTranslatorOptions(t *testing.T) {
 				WithHistogramAggregations(),
 			},
 		},
+		{
+			name:     "buckets-count-sum-only",
+			otlpfile: "testdata/otlpdata/histogram/simple-delta.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-delta_counters-countsum.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramCountSum(),
+			},
+		},
+		{
+			name:     "buckets-min-max-only",
+			otlpfile: "testdata/otlpdata/histogram/simple-delta.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-delta_counters-minmax.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramMinMax(),
+			},
+		},
+		{
+			name:     "buckets-count-sum-min-max",
+			otlpfile: "testdata/otlpdata/histogram/simple-delta.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-delta_counters-cs.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramCountSum(),
+				WithHistogramMinMax(),
+			},
+		},
+		{
+			name:     "buckets-count-sum-exclude-inf",
+			otlpfile: "testdata/otlpdata/histogram/simple-delta.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-delta_counters-cs-noinf.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramAggregations(),
+				WithHistogramExcludeInfBucket(),
+			},
+		},
+		{
+			name:     "buckets-exclude-bounds",
+			otlpfile: "testdata/otlpdata/histogram/simple-delta.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-delta_counters-nocs-excluded.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramBucketExclusion(0),
+			},
+		},
+		{
+			name:     "count-sum-only",
+			otlpfile: "testdata/otlpdata/histogram/simple-delta.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-delta_nobuckets-countsum.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeNoBuckets),
+				WithHistogramCountSum(),
+			},
+		},
+		{
+			name:     "per-metric-modes",
+			otlpfile: "testdata/otlpdata/histogram/latency-size.json",
+			ddogfile: "testdata/datadogdata/histogram/latency-size_per-metric.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramModePerMetric(map[string]HistogramMode{"*.duration": HistogramModeDistributions}),
+			},
+		},
+		{
+			name: "per-metric-no-buckets-no-count-sum",
+			options: []TranslatorOption{
+				WithHistogramModePerMetric(map[string]HistogramMode{"*.size": HistogramModeNoBuckets}),
+			},
+			err: errNoBucketsNoSumCount,
+		},
+		{
+			name: "per-metric-unknown-mode",
+			options: []TranslatorOption{
+				WithHistogramModePerMetric(map[string]HistogramMode{"*.size": "buckets"}),
+			},
+			err: `unknown histogram mode for "*.size": "buckets"`,
+		},
 		{
 			name: "no-count-sum-no-buckets",
 			options: []TranslatorOption{
@@ -73,6 +153,14 @@ func TestDeltaHistogramTranslatorOptions(t *testing.T) {
 			},
 			err: errNoBucketsNoSumCount,
 		},
+		{
+			name: "min-max-no-buckets",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeNoBuckets),
+				WithHistogramMinMax(),
+			},
+			err: errNoBucketsNoSumCount,
+		},
 	}
 
 	for _, testinstance := range tests {
@@ -138,6 +226,43 @@ func TestCumulativeHistogramTranslatorOptions(t *testing.T) {
 				WithHistogramAggregations(),
 			},
 		},
+		{
+			name:     "count-sum-only",
+			otlpfile: "testdata/otlpdata/histogram/simple-cumulative.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-cumulative_nobuckets-cs.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeNoBuckets),
+				WithHistogramCountSum(),
+			},
+		},
+		{
+			name:     "reset-distributions-count-sum",
+			otlpfile: "testdata/otlpdata/histogram/simple-cumulative-reset.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-cumulative-reset_dist-cs.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeDistributions),
+				WithHistogramAggregations(),
+			},
+		},
+		{
+			name:     "reset-buckets-count-sum",
+			otlpfile: "testdata/otlpdata/histogram/simple-cumulative-reset.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-cumulative-reset_counters-cs.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramAggregations(),
+			},
+		},
+		{
+			// min and max are not reported for cumulative histograms.
+			name:     "buckets-min-max-only",
+			otlpfile: "testdata/otlpdata/histogram/simple-cumulative.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-cumulative_counters-nocs.json",
+			options: []TranslatorOption{
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramMinMax(),
+			},
+		},
 	}
 
 	for _, testinstance := range tests {
@@ -185,6 +310,26 @@ func TestExponentialHistogramTranslatorOptions(t *testing.T) {
 			expectedUnknownMetricType:                 1,
 			expectedUnsupportedAggregationTemporality: 1,
 		},
+		{
+			name:     "count-sum-only",
+			otlpfile: "testdata/otlpdata/histogram/simple-exponential.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-exponential_countsum.json",
+			options: []TranslatorOption{
+				WithHistogramCountSum(),
+			},
+			expectedUnknownMetricType:                 1,
+			expectedUnsupportedAggregationTemporality: 1,
+		},
+		{
+			name:     "min-max-only",
+			otlpfile: "testdata/otlpdata/histogram/simple-exponential.json",
+			ddogfile: "testdata/datadogdata/histogram/simple-exponential_minmax.json",
+			options: []TranslatorOption{
+				WithHistogramMinMax(),
+			},
+			expectedUnknownMetricType:                 1,
+			expectedUnsupportedAggregationTemporality: 1,
+		},
 		{
 			name:     "instrumentation-library-metadata-as-tags",
 			otlpfile: "testdata/otlpdata/histogram/simple-exponential.json",
@@ -246,7 +391,6 @@ func TestExponentialHistogramTranslatorOptions(t *testing.T) {
 			options: []TranslatorOption{
 				WithHistogramAggregations(),
 				WithResourceAttributesAsTags(),
-				WithInstrumentationLibraryMetadataAsTags(),
 				WithInstrumentationScopeMetadataAsTags(),
 			},
 			expectedUnknownMetricType:                 1,
@@ -266,3 +410,28 @@ func TestExponentialHistogramTranslatorOptions(t *testing.T) {
 		})
 	}
 }
+
+// TestHistogramAggregationsTimestamp checks that the .count, .sum, .min and .max metrics
+// carry the timestamp of the OTLP datapoint, so that translation output is deterministic.
+func TestHistogramAggregationsTimestamp(t *testing.T) {
+	md := createTestHistogramMetric("http.server.duration")
+	ts := uint64(md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints().At(0).Timestamp())
+
+	tr, err := NewTranslator(zap.NewNop(), WithHistogramMode(HistogramModeNoBuckets), WithHistogramAggregations())
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	var names []string
+	for _, m := range consumer.metrics {
+		names = append(names, m.name)
+		assert.Equal(t, ts, m.timestamp, "timestamp of %q", m.name)
+	}
+	assert.ElementsMatch(t, []string{
+		"http.server.duration.count",
+		"http.server.duration.sum",
+		"http.server.duration.min",
+		"http.server.duration.max",
+	}, names)
+}
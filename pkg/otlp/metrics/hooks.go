@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+)
+
+// TranslationHook post-processes the dimensions of a translated metric before it is consumed.
+// It returns the dimensions to use (e.g. built with AddTags or WithName), or nil to drop the metric.
+type TranslationHook func(dims *Dimensions) *Dimensions
+
+var _ Consumer = (*hookConsumer)(nil)
+var _ TimeSeriesIntervalConsumer = (*hookConsumer)(nil)
+var _ ExemplarConsumer = (*hookConsumer)(nil)
+
+// hookConsumer is a Consumer that applies translation hooks before passing metrics to another consumer.
+type hookConsumer struct {
+	Consumer
+	hooks []TranslationHook
+}
+
+// apply chains the hooks in order. It returns nil if the metric must be dropped.
+func (c *hookConsumer) apply(dims *Dimensions) *Dimensions {
+	for _, hook := range c.hooks {
+		if dims = hook(dims); dims == nil {
+			return nil
+		}
+	}
+	return dims
+}
+
+// ConsumeTimeSeries implements the TimeSeriesConsumer interface.
+func (c *hookConsumer) ConsumeTimeSeries(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	value float64,
+) {
+	if dims := c.apply(dimensions); dims != nil {
+		c.Consumer.ConsumeTimeSeries(ctx, dims, typ, timestamp, value)
+	}
+}
+
+// ConsumeTimeSeriesWithInterval implements the TimeSeriesIntervalConsumer interface.
+func (c *hookConsumer) ConsumeTimeSeriesWithInterval(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	interval int64,
+	value float64,
+) {
+	if dims := c.apply(dimensions); dims != nil {
+		consumeTimeSeriesWithInterval(ctx, c.Consumer, dims, typ, timestamp, interval, value)
+	}
+}
+
+// ConsumeSketch implements the SketchConsumer interface.
+func (c *hookConsumer) ConsumeSketch(
+	ctx context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	sketch *quantile.Sketch,
+) {
+	if dims := c.apply(dimensions); dims != nil {
+		c.Consumer.ConsumeSketch(ctx, dims, timestamp, sketch)
+	}
+}
+
+// ConsumeExemplars implements the ExemplarConsumer interface.
+func (c *hookConsumer) ConsumeExemplars(
+	ctx context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	exemplars []Exemplar,
+) {
+	if dims := c.apply(dimensions); dims != nil {
+		consumeExemplars(ctx, c.Consumer, dims, timestamp, exemplars)
+	}
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestMapMetricsTranslationHooks(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"app.requests_total", "app.debug.queue_size"} {
+		met := metrics.AppendEmpty()
+		met.SetName(name)
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(1)
+	}
+	hist := metrics.AppendEmpty()
+	hist.SetName("app.latency")
+	hist.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	hdp := hist.Histogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(seconds(1))
+	hdp.SetCount(1)
+	hdp.BucketCounts().FromRaw([]uint64{1})
+
+	debugMetric := regexp.MustCompile(`\.debug\.`)
+	totalSuffix := regexp.MustCompile(`_total$`)
+	var calls []string
+	tr, err := NewTranslator(zap.NewNop(),
+		WithTranslationHook(func(dims *Dimensions) *Dimensions {
+			calls = append(calls, "drop")
+			if debugMetric.MatchString(dims.Name()) {
+				return nil
+			}
+			return dims
+		}),
+		WithTranslationHook(func(dims *Dimensions) *Dimensions {
+			calls = append(calls, "rename")
+			return dims.WithName(totalSuffix.ReplaceAllString(dims.Name(), ".count"))
+		}),
+		WithTranslationHook(func(dims *Dimensions) *Dimensions {
+			calls = append(calls, "tag")
+			return dims.AddTags("team:payments")
+		}),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	// The hooks are chained in order, and the chain stops when a metric is dropped.
+	assert.Equal(t, []string{"drop", "rename", "tag", "drop", "drop", "rename", "tag"}, calls)
+
+	require.Len(t, consumer.metrics, 1)
+	assert.Equal(t, "app.requests.count", consumer.metrics[0].name)
+	assert.Equal(t, []string{"team:payments"}, consumer.metrics[0].tags)
+
+	require.Len(t, consumer.sketches, 1)
+	assert.Equal(t, "app.latency", consumer.sketches[0].name)
+	assert.Equal(t, []string{"team:payments"}, consumer.sketches[0].tags)
+}
+
+func TestWithTranslationHookNil(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithTranslationHook(nil))
+	assert.EqualError(t, err, "translation hook must not be nil")
+}
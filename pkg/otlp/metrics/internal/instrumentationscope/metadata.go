@@ -33,3 +33,28 @@ func TagsFromInstrumentationScopeMetadata(il pcommon.InstrumentationScope) []str
 		utils.FormatKeyValueTag(instrumentationScopeVersionTag, il.Version()),
 	}
 }
+
+// TagsFromInstrumentationScopeMetadataWithPrefix works like TagsFromInstrumentationScopeMetadata, but
+// uses the given prefix followed by "name" and "version" as tag keys. An empty prefix uses the default keys.
+func TagsFromInstrumentationScopeMetadataWithPrefix(il pcommon.InstrumentationScope, prefix string) []string {
+	nameKey, versionKey := tagKeys(prefix)
+	return []string{
+		utils.FormatKeyValueTag(nameKey, il.Name()),
+		utils.FormatKeyValueTag(versionKey, il.Version()),
+	}
+}
+
+// TagsFromInstrumentationScopeNameWithPrefix works like TagsFromInstrumentationScopeMetadataWithPrefix,
+// but only converts the name of the instrumentation scope.
+func TagsFromInstrumentationScopeNameWithPrefix(il pcommon.InstrumentationScope, prefix string) []string {
+	nameKey, _ := tagKeys(prefix)
+	return []string{utils.FormatKeyValueTag(nameKey, il.Name())}
+}
+
+// tagKeys returns the keys of the scope name and version tags for the given prefix.
+func tagKeys(prefix string) (nameKey string, versionKey string) {
+	if prefix == "" {
+		return instrumentationScopeTag, instrumentationScopeVersionTag
+	}
+	return prefix + "name", prefix + "version"
+}
@@ -43,3 +43,22 @@ func TestTagsFromInstrumentationScopeMetadata(t *testing.T) {
 		assert.ElementsMatch(t, testInstance.expectedTags, tags)
 	}
 }
+
+func TestTagsFromInstrumentationScopeMetadataWithPrefix(t *testing.T) {
+	il := pcommon.NewInstrumentationScope()
+	il.SetName("test-il")
+
+	assert.ElementsMatch(t, []string{"otel.scope.name:test-il", "otel.scope.version:n/a"},
+		TagsFromInstrumentationScopeMetadataWithPrefix(il, "otel.scope."))
+	assert.ElementsMatch(t, TagsFromInstrumentationScopeMetadata(il),
+		TagsFromInstrumentationScopeMetadataWithPrefix(il, ""))
+}
+
+func TestTagsFromInstrumentationScopeNameWithPrefix(t *testing.T) {
+	il := pcommon.NewInstrumentationScope()
+	il.SetName("test-il")
+	il.SetVersion("1.0.0")
+
+	assert.Equal(t, []string{"otel.scope.name:test-il"}, TagsFromInstrumentationScopeNameWithPrefix(il, "otel.scope."))
+	assert.Equal(t, []string{"instrumentation_scope:test-il"}, TagsFromInstrumentationScopeNameWithPrefix(il, ""))
+}
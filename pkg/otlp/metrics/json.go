@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+)
+
+// SeriesPayload is the payload of the Datadog v1 metrics API (POST /api/v1/series).
+type SeriesPayload struct {
+	Series []Serie `json:"series"`
+}
+
+// Serie is a timeseries of the Datadog v1 metrics API.
+type Serie struct {
+	Metric string `json:"metric"`
+	// Points are [timestamp in seconds, value] pairs.
+	Points [][2]float64 `json:"points"`
+	Type   DataType     `json:"type"`
+	// Interval is the length in seconds of the window counts were aggregated over, if known.
+	Interval int64    `json:"interval,omitempty"`
+	Host     string   `json:"host,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+var _ Consumer = (*seriesConsumer)(nil)
+var _ TimeSeriesIntervalConsumer = (*seriesConsumer)(nil)
+
+// seriesConsumer is a Consumer building a Datadog v1 metrics API payload.
+// Sketches and APM stats have no representation in the payload and are dropped.
+type seriesConsumer struct {
+	payload SeriesPayload
+}
+
+// ConsumeTimeSeries implements the TimeSeriesConsumer interface.
+func (c *seriesConsumer) ConsumeTimeSeries(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	value float64,
+) {
+	c.ConsumeTimeSeriesWithInterval(ctx, dimensions, typ, timestamp, 0, value)
+}
+
+// ConsumeTimeSeriesWithInterval implements the TimeSeriesIntervalConsumer interface.
+func (c *seriesConsumer) ConsumeTimeSeriesWithInterval(
+	_ context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	interval int64,
+	value float64,
+) {
+	c.payload.Series = append(c.payload.Series, Serie{
+		Metric:   dimensions.Name(),
+		Points:   [][2]float64{{float64(timestamp / 1e9), value}},
+		Type:     typ,
+		Interval: interval,
+		Host:     dimensions.Host(),
+		Tags:     dimensions.Tags(),
+	})
+}
+
+// ConsumeSketch implements the SketchConsumer interface.
+func (c *seriesConsumer) ConsumeSketch(_ context.Context, _ *Dimensions, _ uint64, _ *quantile.Sketch) {
+}
+
+// ConsumeAPMStats implements the APMStatsConsumer interface.
+func (c *seriesConsumer) ConsumeAPMStats(_ *pb.ClientStatsPayload) {}
+
+// MapMetricsToJSON translates the given metrics and marshals them as a Datadog v1 metrics API
+// payload, ready to be sent to the /api/v1/series endpoint.
+//
+// A new Translator is built with the given options on every call, so cumulative metrics reported
+// as deltas are dropped: use a Translator with a Consumer for long running translations.
+// Histograms are reported as counters by default, and distributions are dropped since the v1 API
+// does not support them.
+func MapMetricsToJSON(md pmetric.Metrics, opts ...TranslatorOption) ([]byte, error) {
+	series, err := mapSeries(md, opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(SeriesPayload{Series: series})
+}
+
+// MapMetricsFromJSON translates metrics encoded as OTLP JSON, as produced by pmetric.JSONMarshaler,
+// into Datadog v1 metrics API timeseries. The translation works like MapMetricsToJSON.
+//
+// The timeseries are returned as Serie values, the type used by MapMetricsToJSON and SplitMetricPayload,
+// rather than a dedicated type. Unlike a Translator with a Consumer, the output has no sketches:
+// histograms are reported as counters by default, and the sketches of distributions and exponential
+// histograms are dropped, as are APM stats.
+func MapMetricsFromJSON(payload []byte, opts ...TranslatorOption) ([]Serie, error) {
+	var unmarshaler pmetric.JSONUnmarshaler
+	md, err := unmarshaler.UnmarshalMetrics(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal OTLP JSON payload: %w", err)
+	}
+	return mapSeries(md, opts)
+}
+
+// mapSeries translates the given metrics into timeseries with a new Translator built with the given options.
+func mapSeries(md pmetric.Metrics, opts []TranslatorOption) ([]Serie, error) {
+	options := append([]TranslatorOption{WithHistogramMode(HistogramModeCounters)}, opts...)
+	translator, err := NewTranslator(zap.NewNop(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create translator: %w", err)
+	}
+	var consumer seriesConsumer
+	if _, err := translator.MapMetrics(context.Background(), md, &consumer); err != nil {
+		return nil, err
+	}
+	if consumer.payload.Series == nil {
+		return []Serie{}, nil
+	}
+	return consumer.payload.Series, nil
+}
+
+// MaxSeriesPerPayload is the maximum number of timeseries accepted by the Datadog v1 metrics API in a payload.
+const MaxSeriesPerPayload = 500
+
+// SplitMetricPayload splits timeseries into batches of at most maxPerBatch timeseries, to be sent as
+// separate payloads. If maxPerBatch is not positive, MaxSeriesPerPayload is used.
+//
+// The timeseries of a metric name are kept in the same batch when they fit in one: batches are filled
+// with whole metrics, in order of first appearance, and a new batch is started when the next metric
+// doesn't fit. The timeseries of a metric exceeding maxPerBatch are spread over consecutive batches.
+func SplitMetricPayload(series []Serie, maxPerBatch int) [][]Serie {
+	if maxPerBatch <= 0 {
+		maxPerBatch = MaxSeriesPerPayload
+	}
+
+	// Group the timeseries by metric name, keeping the order of first appearance.
+	var names []string
+	byName := make(map[string][]Serie)
+	for _, serie := range series {
+		if _, ok := byName[serie.Metric]; !ok {
+			names = append(names, serie.Metric)
+		}
+		byName[serie.Metric] = append(byName[serie.Metric], serie)
+	}
+
+	var batches [][]Serie
+	var batch []Serie
+	flush := func() {
+		if len(batch) > 0 {
+			batches = append(batches, batch)
+			batch = nil
+		}
+	}
+	for _, name := range names {
+		group := byName[name]
+		if len(batch)+len(group) > maxPerBatch && len(group) <= maxPerBatch {
+			flush()
+		}
+		for len(group) > 0 {
+			n := maxPerBatch - len(batch)
+			if n > len(group) {
+				n = len(group)
+			}
+			batch = append(batch, group[:n]...)
+			group = group[n:]
+			if len(batch) == maxPerBatch {
+				flush()
+			}
+		}
+	}
+	flush()
+	return batches
+}
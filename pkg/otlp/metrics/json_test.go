@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestMapMetricsToJSON(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("host.name", "web-1")
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("system.load")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(10))
+	dp.SetDoubleValue(1.5)
+	dp.Attributes().PutStr("cpu", "0")
+
+	sum := metrics.AppendEmpty()
+	sum.SetName("http.requests")
+	sum.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	sum.Sum().SetIsMonotonic(true)
+	dp = sum.Sum().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(seconds(5))
+	dp.SetTimestamp(seconds(10))
+	dp.SetIntValue(42)
+
+	b, err := MapMetricsToJSON(md)
+	require.NoError(t, err)
+
+	var payload SeriesPayload
+	require.NoError(t, json.Unmarshal(b, &payload))
+	assert.Equal(t, []Serie{
+		{
+			Metric: "system.load",
+			Points: [][2]float64{{10, 1.5}},
+			Type:   Gauge,
+			Host:   "web-1",
+			Tags:   []string{"cpu:0", "service:checkout"},
+		},
+		{
+			Metric:   "http.requests",
+			Points:   [][2]float64{{10, 42}},
+			Type:     Count,
+			Interval: 5,
+			Host:     "web-1",
+			Tags:     []string{"service:checkout"},
+		},
+	}, payload.Series)
+}
+
+func TestMapMetricsToJSONEmpty(t *testing.T) {
+	b, err := MapMetricsToJSON(pmetric.NewMetrics())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"series": []}`, string(b))
+
+	_, err = MapMetricsToJSON(pmetric.NewMetrics(), WithMaxTagCount(0))
+	assert.EqualError(t, err, "failed to create translator: maximum tag count must be positive: 0")
+}
+
+func TestMapMetricsFromJSON(t *testing.T) {
+	payload, err := os.ReadFile("testdata/otlpdata/mixed/simple.json")
+	require.NoError(t, err)
+
+	series, err := MapMetricsFromJSON(payload)
+	require.NoError(t, err)
+
+	// The payload is translated like the equivalent pmetric.Metrics.
+	var unmarshaler pmetric.JSONUnmarshaler
+	md, err := unmarshaler.UnmarshalMetrics(payload)
+	require.NoError(t, err)
+	b, err := MapMetricsToJSON(md)
+	require.NoError(t, err)
+	var expected SeriesPayload
+	require.NoError(t, json.Unmarshal(b, &expected))
+	assert.Equal(t, expected.Series, series)
+	var names []string
+	for _, serie := range series {
+		names = append(names, serie.Metric)
+	}
+	assert.Contains(t, names, "int.gauge")
+	assert.Contains(t, names, "double.delta.monotonic.sum")
+
+	_, err = MapMetricsFromJSON([]byte(`{"resourceMetrics": 1}`))
+	assert.ErrorContains(t, err, "failed to unmarshal OTLP JSON payload")
+}
+
+func TestSplitMetricPayload(t *testing.T) {
+	newSeries := func(name string, n int) []Serie {
+		series := make([]Serie, n)
+		for i := range series {
+			series[i] = Serie{Metric: name, Points: [][2]float64{{float64(i), 1}}, Type: Gauge}
+		}
+		return series
+	}
+	distinctSeries := func(n int) []Serie {
+		series := make([]Serie, n)
+		for i := range series {
+			series[i] = Serie{Metric: fmt.Sprintf("metric.%d", i), Type: Gauge}
+		}
+		return series
+	}
+	batchSizes := func(batches [][]Serie) []int {
+		sizes := make([]int, 0, len(batches))
+		for _, batch := range batches {
+			sizes = append(sizes, len(batch))
+		}
+		return sizes
+	}
+
+	tests := []struct {
+		name        string
+		series      []Serie
+		maxPerBatch int
+		sizes       []int
+	}{
+		{
+			name:   "empty",
+			series: nil,
+			sizes:  []int{},
+		},
+		{
+			name:   "exactly the maximum",
+			series: distinctSeries(MaxSeriesPerPayload),
+			sizes:  []int{500},
+		},
+		{
+			name:   "one over the maximum",
+			series: distinctSeries(MaxSeriesPerPayload + 1),
+			sizes:  []int{500, 1},
+		},
+		{
+			name:        "metrics kept together",
+			series:      append(append(newSeries("a", 3), newSeries("b", 3)...), newSeries("c", 2)...),
+			maxPerBatch: 5,
+			sizes:       []int{3, 5},
+		},
+		{
+			name:        "metric larger than a batch",
+			series:      append(newSeries("a", 2), newSeries("b", 12)...),
+			maxPerBatch: 5,
+			sizes:       []int{5, 5, 4},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			batches := SplitMetricPayload(testInstance.series, testInstance.maxPerBatch)
+			assert.Equal(t, testInstance.sizes, batchSizes(batches))
+
+			// No timeseries is lost or duplicated.
+			var all []Serie
+			for _, batch := range batches {
+				all = append(all, batch...)
+			}
+			assert.ElementsMatch(t, testInstance.series, all)
+		})
+	}
+}
+
+func TestSplitMetricPayloadGrouping(t *testing.T) {
+	series := []Serie{
+		{Metric: "a", Tags: []string{"i:0"}},
+		{Metric: "b", Tags: []string{"i:0"}},
+		{Metric: "a", Tags: []string{"i:1"}},
+		{Metric: "c", Tags: []string{"i:0"}},
+		{Metric: "b", Tags: []string{"i:1"}},
+	}
+
+	assert.Equal(t, [][]Serie{
+		{
+			{Metric: "a", Tags: []string{"i:0"}},
+			{Metric: "a", Tags: []string{"i:1"}},
+		},
+		{
+			{Metric: "b", Tags: []string{"i:0"}},
+			{Metric: "b", Tags: []string{"i:1"}},
+			{Metric: "c", Tags: []string{"i:0"}},
+		},
+	}, SplitMetricPayload(series, 3))
+}
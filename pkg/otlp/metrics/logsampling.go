@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var _ zapcore.Core = (*dedupCore)(nil)
+
+// dedupCore is a zapcore.Core that collapses identical log messages within an interval.
+// The first message is logged, and the following ones are suppressed until the interval has elapsed;
+// the next logged message then reports how many messages were suppressed.
+type dedupCore struct {
+	zapcore.Core
+	interval time.Duration
+	now      func() time.Time
+
+	// state is shared with the cores created by With.
+	state *dedupState
+}
+
+type dedupState struct {
+	mu      sync.Mutex
+	entries map[dedupKey]*dedupEntry
+}
+
+type dedupKey struct {
+	level   zapcore.Level
+	message string
+}
+
+type dedupEntry struct {
+	logged     time.Time
+	suppressed int
+}
+
+func newDedupCore(core zapcore.Core, interval time.Duration, now func() time.Time) *dedupCore {
+	return &dedupCore{
+		Core:     core,
+		interval: interval,
+		now:      now,
+		state:    &dedupState{entries: make(map[dedupKey]*dedupEntry)},
+	}
+}
+
+// With implements zapcore.Core.
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{
+		Core:     c.Core.With(fields),
+		interval: c.interval,
+		now:      c.now,
+		state:    c.state,
+	}
+}
+
+// Check implements zapcore.Core.
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+
+	now := c.now()
+	key := dedupKey{level: ent.Level, message: ent.Message}
+	c.state.mu.Lock()
+	entry, ok := c.state.entries[key]
+	if ok && now.Sub(entry.logged) < c.interval {
+		entry.suppressed++
+		c.state.mu.Unlock()
+		return ce
+	}
+	if ok && entry.suppressed > 0 {
+		ent.Message = fmt.Sprintf("%s (suppressed %d identical messages)", ent.Message, entry.suppressed)
+	}
+	c.state.entries[key] = &dedupEntry{logged: now}
+	c.state.mu.Unlock()
+
+	return c.Core.Check(ent, ce)
+}
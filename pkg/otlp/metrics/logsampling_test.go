@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDedupCore(t *testing.T) {
+	core, observed := observer.New(zapcore.DebugLevel)
+	now := time.Unix(0, 0)
+	logger := zap.New(newDedupCore(core, time.Minute, func() time.Time { return now })).
+		With(zap.String("component", "test"))
+
+	for i := 0; i < 1000; i++ {
+		logger.Debug("Unknown or unsupported metric type")
+	}
+	logger.Info("Unknown or unsupported metric type")
+	logger.Debug("Other message")
+	now = now.Add(59 * time.Second)
+	logger.Debug("Unknown or unsupported metric type")
+	now = now.Add(time.Second)
+	logger.Debug("Unknown or unsupported metric type")
+	logger.Debug("Unknown or unsupported metric type")
+
+	var messages []string
+	for _, entry := range observed.All() {
+		messages = append(messages, entry.Message)
+		assert.Equal(t, map[string]interface{}{"component": "test"}, entry.ContextMap())
+	}
+	assert.Equal(t, []string{
+		"Unknown or unsupported metric type",
+		// Messages with different levels are not identical.
+		"Unknown or unsupported metric type",
+		"Other message",
+		"Unknown or unsupported metric type (suppressed 1000 identical messages)",
+	}, messages)
+}
+
+func TestWithLogSamplingIntervalInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithLogSamplingInterval(0))
+	assert.EqualError(t, err, "log sampling interval must be positive: 0s")
+}
@@ -0,0 +1,283 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const (
+	unsupportedMetricType  = "Unknown or unsupported metric type"
+	unsupportedTemporality = "Unknown or unsupported aggregation temporality"
+)
+
+// metricTypeMapping is an expected OTLP to Datadog metric type mapping.
+// Empty numberMode and histMode match any mode.
+type metricTypeMapping struct {
+	typ         pmetric.MetricType
+	temporality pmetric.AggregationTemporality
+	monotonic   bool
+	numberMode  NumberMode
+	histMode    HistogramMode
+
+	// outputs are the distinct "<datadog type> <name>" outputs for a metric named "test.metric".
+	outputs []string
+	// log is the debug message logged for unsupported combinations.
+	log string
+}
+
+func (m metricTypeMapping) matches(
+	typ pmetric.MetricType,
+	temporality pmetric.AggregationTemporality,
+	monotonic bool,
+	numberMode NumberMode,
+	histMode HistogramMode,
+) bool {
+	return m.typ == typ && m.temporality == temporality && m.monotonic == monotonic &&
+		(m.numberMode == "" || m.numberMode == numberMode) &&
+		(m.histMode == "" || m.histMode == histMode)
+}
+
+// metricTypeMappings is the OTLP to Datadog metric type mapping table.
+// Types without aggregation temporality use AggregationTemporalityUnspecified.
+var metricTypeMappings = []metricTypeMapping{
+	{typ: pmetric.MetricTypeEmpty, log: unsupportedMetricType},
+
+	{typ: pmetric.MetricTypeGauge, outputs: []string{"gauge test.metric"}},
+
+	{typ: pmetric.MetricTypeSum, log: unsupportedTemporality},
+	{typ: pmetric.MetricTypeSum, monotonic: true, log: unsupportedTemporality},
+	{typ: pmetric.MetricTypeSum, temporality: pmetric.AggregationTemporalityDelta, outputs: []string{"gauge test.metric"}},
+	{typ: pmetric.MetricTypeSum, temporality: pmetric.AggregationTemporalityDelta, monotonic: true, outputs: []string{"count test.metric"}},
+	{typ: pmetric.MetricTypeSum, temporality: pmetric.AggregationTemporalityCumulative, outputs: []string{"gauge test.metric"}},
+	{
+		typ:         pmetric.MetricTypeSum,
+		temporality: pmetric.AggregationTemporalityCumulative,
+		monotonic:   true,
+		numberMode:  NumberModeCumulativeToDelta,
+		outputs:     []string{"count test.metric"},
+	},
+	{
+		typ:         pmetric.MetricTypeSum,
+		temporality: pmetric.AggregationTemporalityCumulative,
+		monotonic:   true,
+		numberMode:  NumberModeRawValue,
+		outputs:     []string{"gauge test.metric"},
+	},
+
+	{typ: pmetric.MetricTypeHistogram, log: unsupportedTemporality},
+	{
+		typ:         pmetric.MetricTypeHistogram,
+		temporality: pmetric.AggregationTemporalityDelta,
+		histMode:    HistogramModeNoBuckets,
+		outputs:     []string{"count test.metric.count", "count test.metric.sum"},
+	},
+	{
+		typ:         pmetric.MetricTypeHistogram,
+		temporality: pmetric.AggregationTemporalityDelta,
+		histMode:    HistogramModeCounters,
+		outputs:     []string{"count test.metric.bucket", "count test.metric.count", "count test.metric.sum"},
+	},
+	{
+		typ:         pmetric.MetricTypeHistogram,
+		temporality: pmetric.AggregationTemporalityDelta,
+		histMode:    HistogramModeDistributions,
+		outputs:     []string{"count test.metric.count", "count test.metric.sum", "sketch test.metric"},
+	},
+	{
+		typ:         pmetric.MetricTypeHistogram,
+		temporality: pmetric.AggregationTemporalityCumulative,
+		histMode:    HistogramModeNoBuckets,
+		outputs:     []string{"count test.metric.count", "count test.metric.sum"},
+	},
+	{
+		typ:         pmetric.MetricTypeHistogram,
+		temporality: pmetric.AggregationTemporalityCumulative,
+		histMode:    HistogramModeCounters,
+		outputs:     []string{"count test.metric.bucket", "count test.metric.count", "count test.metric.sum"},
+	},
+	{
+		typ:         pmetric.MetricTypeHistogram,
+		temporality: pmetric.AggregationTemporalityCumulative,
+		histMode:    HistogramModeDistributions,
+		outputs:     []string{"count test.metric.count", "count test.metric.sum", "sketch test.metric"},
+	},
+
+	// Exponential histograms are always exported as distributions, and only the delta temporality is supported.
+	{typ: pmetric.MetricTypeExponentialHistogram, log: unsupportedTemporality},
+	{
+		typ:         pmetric.MetricTypeExponentialHistogram,
+		temporality: pmetric.AggregationTemporalityDelta,
+		outputs:     []string{"count test.metric.count", "count test.metric.sum", "sketch test.metric"},
+	},
+	{typ: pmetric.MetricTypeExponentialHistogram, temporality: pmetric.AggregationTemporalityCumulative, log: unsupportedTemporality},
+
+	{typ: pmetric.MetricTypeSummary, outputs: []string{"count test.metric.count", "count test.metric.sum"}},
+}
+
+// TestMetricTypeMappings checks that every combination of OTLP metric type, aggregation temporality,
+// number mode and histogram mode is covered by exactly one entry of the mapping table, and that
+// the translator output matches it.
+func TestMetricTypeMappings(t *testing.T) {
+	for typ := pmetric.MetricType(0); typ.String() != ""; typ++ {
+		for _, temporality := range temporalitiesFor(typ) {
+			for _, monotonic := range []bool{false, true} {
+				if typ != pmetric.MetricTypeSum && monotonic {
+					continue
+				}
+				for _, numberMode := range []NumberMode{NumberModeCumulativeToDelta, NumberModeRawValue} {
+					for _, histMode := range []HistogramMode{HistogramModeNoBuckets, HistogramModeCounters, HistogramModeDistributions} {
+						name := fmt.Sprintf("%s/%s/monotonic=%t/%s/%s", typ, temporality, monotonic, numberMode, histMode)
+						t.Run(name, func(t *testing.T) {
+							var expected []metricTypeMapping
+							for _, mapping := range metricTypeMappings {
+								if mapping.matches(typ, temporality, monotonic, numberMode, histMode) {
+									expected = append(expected, mapping)
+								}
+							}
+							require.Len(t, expected, 1, "the combination must be covered by exactly one mapping")
+
+							outputs, logs := translateMetricType(t, typ, temporality, monotonic, numberMode, histMode)
+							assert.Equal(t, expected[0].outputs, outputs)
+							if expected[0].log != "" {
+								assert.Equal(t, []string{expected[0].log}, logs)
+							} else {
+								assert.Empty(t, logs)
+							}
+						})
+					}
+				}
+			}
+		}
+	}
+}
+
+func temporalitiesFor(typ pmetric.MetricType) []pmetric.AggregationTemporality {
+	switch typ {
+	case pmetric.MetricTypeSum, pmetric.MetricTypeHistogram, pmetric.MetricTypeExponentialHistogram:
+		var temporalities []pmetric.AggregationTemporality
+		for temporality := pmetric.AggregationTemporality(0); temporality.String() != ""; temporality++ {
+			temporalities = append(temporalities, temporality)
+		}
+		return temporalities
+	}
+	return []pmetric.AggregationTemporality{pmetric.AggregationTemporalityUnspecified}
+}
+
+// newMetricOfType creates a metric of the given type with two datapoints.
+func newMetricOfType(t *testing.T, typ pmetric.MetricType, temporality pmetric.AggregationTemporality, monotonic bool) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("test.metric")
+	for i := 1; i <= 2; i++ {
+		switch typ {
+		case pmetric.MetricTypeEmpty:
+			return md
+		case pmetric.MetricTypeGauge:
+			if i == 1 {
+				met.SetEmptyGauge()
+			}
+			dp := met.Gauge().DataPoints().AppendEmpty()
+			dp.SetTimestamp(seconds(i))
+			dp.SetDoubleValue(float64(i))
+		case pmetric.MetricTypeSum:
+			if i == 1 {
+				met.SetEmptySum().SetAggregationTemporality(temporality)
+				met.Sum().SetIsMonotonic(monotonic)
+			}
+			dp := met.Sum().DataPoints().AppendEmpty()
+			dp.SetTimestamp(seconds(i))
+			dp.SetDoubleValue(float64(i))
+		case pmetric.MetricTypeHistogram:
+			if i == 1 {
+				met.SetEmptyHistogram().SetAggregationTemporality(temporality)
+			}
+			dp := met.Histogram().DataPoints().AppendEmpty()
+			dp.SetTimestamp(seconds(i))
+			dp.SetCount(uint64(2 * i))
+			dp.SetSum(float64(2 * i))
+			dp.ExplicitBounds().FromRaw([]float64{1})
+			dp.BucketCounts().FromRaw([]uint64{uint64(i), uint64(i)})
+		case pmetric.MetricTypeExponentialHistogram:
+			if i == 1 {
+				met.SetEmptyExponentialHistogram().SetAggregationTemporality(temporality)
+			}
+			dp := met.ExponentialHistogram().DataPoints().AppendEmpty()
+			dp.SetTimestamp(seconds(i))
+			dp.SetCount(uint64(2 * i))
+			dp.SetSum(float64(2 * i))
+			dp.Positive().BucketCounts().FromRaw([]uint64{uint64(i), uint64(i)})
+		case pmetric.MetricTypeSummary:
+			if i == 1 {
+				met.SetEmptySummary()
+			}
+			dp := met.Summary().DataPoints().AppendEmpty()
+			dp.SetTimestamp(seconds(i))
+			dp.SetCount(uint64(2 * i))
+			dp.SetSum(float64(2 * i))
+			q := dp.QuantileValues().AppendEmpty()
+			q.SetQuantile(0.5)
+			q.SetValue(float64(i))
+		default:
+			t.Fatalf("metric type %s is missing from the metric type mapping test", typ)
+		}
+	}
+	return md
+}
+
+// translateMetricType translates a metric of the given type, and returns the sorted list of
+// distinct outputs (as "<datadog type> <name>") along with the debug log messages.
+func translateMetricType(
+	t *testing.T,
+	typ pmetric.MetricType,
+	temporality pmetric.AggregationTemporality,
+	monotonic bool,
+	numberMode NumberMode,
+	histMode HistogramMode,
+) ([]string, []string) {
+	core, observed := observer.New(zapcore.DebugLevel)
+	tr, err := NewTranslator(zap.New(core),
+		WithNumberMode(numberMode),
+		WithHistogramMode(histMode),
+		// Required by HistogramModeNoBuckets.
+		WithHistogramAggregations(),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), newMetricOfType(t, typ, temporality, monotonic), consumer)
+	require.NoError(t, err)
+
+	seen := make(map[string]struct{})
+	for _, m := range consumer.metrics {
+		typ, err := m.typ.MarshalText()
+		require.NoError(t, err)
+		seen[fmt.Sprintf("%s %s", typ, m.name)] = struct{}{}
+	}
+	for _, s := range consumer.sketches {
+		seen["sketch "+s.name] = struct{}{}
+	}
+	var outputs []string
+	for output := range seen {
+		outputs = append(outputs, output)
+	}
+	sort.Strings(outputs)
+
+	var logs []string
+	for _, entry := range observed.All() {
+		logs = append(logs, entry.Message)
+	}
+	return outputs, logs
+}
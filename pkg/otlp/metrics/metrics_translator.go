@@ -19,13 +19,16 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"golang.org/x/exp/slices"
 
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes"
@@ -36,8 +39,15 @@ import (
 )
 
 const (
-	metricName             string = "metric name"
-	errNoBucketsNoSumCount string = "no buckets mode and no send count sum are incompatible"
+	metricName              string = "metric name"
+	noRecordedValueMessage  string = "Skipping datapoint with no recorded value"
+	errNoBucketsNoSumCount  string = "no buckets mode and no send count sum are incompatible"
+	errBothMetadataAsTags   string = "WithInstrumentationLibraryMetadataAsTags and WithInstrumentationScopeMetadataAsTags must not be enabled at the same time"
+	errExcludeInfNoSumCount string = "WithHistogramExcludeInfBucket requires histogram count and sum to be exported"
+	errExclusionNoCounters  string = "WithHistogramExcludeInfBucket and WithHistogramBucketExclusion require HistogramModeCounters"
+	errResetModeNoDelta     string = "NegativeDeltaModeReset requires NumberModeCumulativeToDelta for at least some metrics"
+	errMaxSizeDeltaStore    string = "WithDeltaCacheMaxSize can't be used with WithDeltaStore"
+	errStatsDeltaStore      string = "WithCacheStatsCallback can't be used with WithDeltaStore"
 )
 
 var _ source.Provider = (*noSourceProvider)(nil)
@@ -59,41 +69,297 @@ type Translator struct {
 type Metadata struct {
 	// Languages specifies a list of languages for which runtime metrics were found.
 	Languages []string
+	// Stats are diagnostics about the translation.
+	Stats TranslatorStats
 }
 
-// NewTranslator creates a new translator with given options.
-func NewTranslator(logger *zap.Logger, options ...TranslatorOption) (*Translator, error) {
-	cfg := translatorConfig{
-		HistMode:                             HistogramModeDistributions,
-		SendHistogramAggregations:            false,
-		Quantiles:                            false,
-		SendMonotonic:                        true,
-		ResourceAttributesAsTags:             false,
-		InstrumentationLibraryMetadataAsTags: false,
-		sweepInterval:                        1800,
-		deltaTTL:                             3600,
-		fallbackSourceProvider:               &noSourceProvider{},
+// TranslatorBuilder builds a Translator, collecting all the configuration errors.
+type TranslatorBuilder struct {
+	logger *zap.Logger
+	cfg    translatorConfig
+	errs   []error
+}
+
+// NewTranslatorBuilder creates a new translator builder with the default configuration.
+func NewTranslatorBuilder(logger *zap.Logger) *TranslatorBuilder {
+	return &TranslatorBuilder{
+		logger: logger,
+		cfg: translatorConfig{
+			HistMode:                             HistogramModeDistributions,
+			SendHistogramCountSum:                false,
+			SendHistogramMinMax:                  false,
+			SummaryMode:                          SummaryModeNoBuckets,
+			SendMonotonic:                        true,
+			ResourceAttributesAsTags:             false,
+			InstrumentationLibraryMetadataAsTags: false,
+			NegativeDeltaMode:                    NegativeDeltaModeDrop,
+			InvalidCumulativeMode:                InvalidCumulativeModeWarn,
+			ScopeVersionTag:                      true,
+			StaleMode:                            StaleModeDrop,
+			sweepInterval:                        1800,
+			deltaTTL:                             3600,
+			fallbackSourceProvider:               &noSourceProvider{},
+		},
 	}
+}
 
-	for _, opt := range options {
-		err := opt(&cfg)
-		if err != nil {
-			return nil, err
+// Add applies an option to the translator configuration.
+// If the option is invalid, its error is reported by Build.
+func (b *TranslatorBuilder) Add(opt TranslatorOption) *TranslatorBuilder {
+	if err := opt(&b.cfg); err != nil {
+		b.errs = append(b.errs, err)
+	}
+	return b
+}
+
+// validate returns all the errors of the options, followed by the errors of invalid option combinations.
+func (b *TranslatorBuilder) validate() []error {
+	cfg := b.cfg
+	errs := append([]error(nil), b.errs...)
+
+	if (cfg.HistMode == HistogramModeNoBuckets || hasPatternRule(cfg.HistogramModeRules, HistogramModeNoBuckets)) && !cfg.SendHistogramCountSum {
+		errs = append(errs, errors.New(errNoBucketsNoSumCount))
+	}
+
+	if cfg.InstrumentationLibraryMetadataAsTags && cfg.InstrumentationScopeMetadataAsTags {
+		errs = append(errs, errors.New(errBothMetadataAsTags))
+	}
+
+	if cfg.HistogramExcludeInfBucket && !cfg.SendHistogramCountSum {
+		errs = append(errs, errors.New(errExcludeInfNoSumCount))
+	}
+
+	// Bucket exclusions only apply to the bucket counters.
+	if (cfg.HistogramExcludeInfBucket || len(cfg.HistogramExcludedBucketBounds) > 0) &&
+		cfg.HistMode != HistogramModeCounters && !hasPatternRule(cfg.HistogramModeRules, HistogramModeCounters) {
+		errs = append(errs, errors.New(errExclusionNoCounters))
+	}
+
+	// Resets are only handled when computing deltas of cumulative monotonic sums.
+	if cfg.NegativeDeltaMode == NegativeDeltaModeReset && !cfg.SendMonotonic && !hasPatternRule(cfg.NumberModeRules, NumberModeCumulativeToDelta) {
+		errs = append(errs, errors.New(errResetModeNoDelta))
+	}
+
+	// The max size and cache statistics only apply to the in-memory store.
+	if cfg.deltaCacheMaxSize > 0 && cfg.deltaStore != nil {
+		errs = append(errs, errors.New(errMaxSizeDeltaStore))
+	}
+	if cfg.cacheStatsCallback != nil && cfg.deltaStore != nil {
+		errs = append(errs, errors.New(errStatsDeltaStore))
+	}
+
+	return errs
+}
+
+// hasPatternRule checks if any of the per-metric rules uses the given mode.
+func hasPatternRule[M comparable](rules map[string]M, mode M) bool {
+	for _, ruleMode := range rules {
+		if ruleMode == mode {
+			return true
 		}
 	}
+	return false
+}
 
-	if cfg.HistMode == HistogramModeNoBuckets && !cfg.SendHistogramAggregations {
-		return nil, errors.New(errNoBucketsNoSumCount)
+// Build creates the translator. It returns all the errors of the options,
+// followed by the errors of invalid option combinations.
+func (b *TranslatorBuilder) Build() (*Translator, []error) {
+	if errs := b.validate(); len(errs) > 0 {
+		return nil, errs
 	}
+	cfg := b.cfg
 
-	cache := newTTLCache(cfg.sweepInterval, cfg.deltaTTL)
+	logger := b.logger
+	if cfg.LogSamplingInterval > 0 {
+		logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newDedupCore(core, cfg.LogSamplingInterval, time.Now)
+		}))
+	}
+	logger = logger.With(zap.String("component", "metrics translator"))
+	if cfg.deltaStore == nil {
+		// Expired datapoints of every timeseries must be removed at least every TTL/2 seconds.
+		sweepInterval := cfg.sweepInterval
+		for _, ttl := range cfg.DeltaTTLRules {
+			if ruleSweepInterval := ttl / 2; ruleSweepInterval < sweepInterval {
+				sweepInterval = ruleSweepInterval
+			}
+		}
+		if sweepInterval < 1 {
+			sweepInterval = 1
+		}
+		if cfg.cacheStatsCallback != nil {
+			cfg.deltaStore = newStatsDeltaStore(sweepInterval, cfg.deltaTTL, cfg.deltaCacheMaxSize, logger, cfg.cacheStatsCallback)
+		} else {
+			cfg.deltaStore = newInMemoryDeltaStore(sweepInterval, cfg.deltaTTL, cfg.deltaCacheMaxSize, logger)
+		}
+	}
+	cache := newTTLCacheWithStore(cfg.deltaStore)
+	cache.ttlRules = cfg.DeltaTTLRules
 	return &Translator{
 		prevPts: cache,
-		logger:  logger.With(zap.String("component", "metrics translator")),
+		logger:  logger,
 		cfg:     cfg,
 	}, nil
 }
 
+// NewTranslator creates a new translator with given options.
+// If the configuration is invalid, the returned error combines all the errors of the options
+// and of invalid option combinations; use TranslatorBuilder to get them as a list.
+func NewTranslator(logger *zap.Logger, options ...TranslatorOption) (*Translator, error) {
+	builder := NewTranslatorBuilder(logger)
+	for _, opt := range options {
+		builder.Add(opt)
+	}
+
+	tr, errs := builder.Build()
+	if len(errs) > 0 {
+		return nil, multierr.Combine(errs...)
+	}
+	return tr, nil
+}
+
+// ValidateOptions checks the given options, and the combination of them, without creating a translator.
+// It returns the same error as NewTranslator would, e.g. to validate a configuration at startup.
+func ValidateOptions(options ...TranslatorOption) error {
+	builder := NewTranslatorBuilder(zap.NewNop())
+	for _, opt := range options {
+		builder.Add(opt)
+	}
+	return multierr.Combine(builder.validate()...)
+}
+
+// NewTranslatorWithMappingTable creates a new translator with given options,
+// converting resource attributes to tags with the mappings of the given table.
+// It is equivalent to NewTranslator with the WithAttributeMappingTable option.
+func NewTranslatorWithMappingTable(logger *zap.Logger, table *attributes.AttributeMappingTable, options ...TranslatorOption) (*Translator, error) {
+	return NewTranslator(logger, append([]TranslatorOption{WithAttributeMappingTable(table)}, options...)...)
+}
+
+// Config returns a snapshot of the configuration of the translator.
+// The maps, slices and pointers of the snapshot are copies, so that modifying it doesn't affect the translator.
+func (t *Translator) Config() TranslatorConfig {
+	return TranslatorConfig{
+		HistMode:                             t.cfg.HistMode,
+		HistogramModeRules:                   cloneMap(t.cfg.HistogramModeRules),
+		StaleMode:                            t.cfg.StaleMode,
+		SendHistogramCountSum:                t.cfg.SendHistogramCountSum,
+		SendHistogramMinMax:                  t.cfg.SendHistogramMinMax,
+		SummaryMode:                          t.cfg.SummaryMode,
+		SendMonotonic:                        t.cfg.SendMonotonic,
+		NumberModeRules:                      cloneMap(t.cfg.NumberModeRules),
+		ResourceAttributesAsTags:             t.cfg.ResourceAttributesAsTags,
+		ResourceAttributePrefix:              t.cfg.ResourceAttributePrefix,
+		ProcessMetadata:                      t.cfg.ProcessMetadata,
+		SDKMetadataAsTags:                    t.cfg.SDKMetadataAsTags,
+		KubernetesPodLabelsAsTags:            cloneMap(t.cfg.KubernetesPodLabelsAsTags),
+		DryRun:                               t.cfg.DryRun,
+		ExemplarTranslation:                  t.cfg.ExemplarTranslation,
+		ExemplarAPMLink:                      t.cfg.ExemplarAPMLink,
+		InstrumentationLibraryMetadataAsTags: t.cfg.InstrumentationLibraryMetadataAsTags,
+		InstrumentationScopeMetadataAsTags:   t.cfg.InstrumentationScopeMetadataAsTags,
+		ScopeTagPrefix:                       t.cfg.ScopeTagPrefix,
+		ScopeVersionTag:                      t.cfg.ScopeVersionTag,
+		MetricNamePrefix:                     t.cfg.MetricNamePrefix,
+		MetricNameSuffix:                     t.cfg.MetricNameSuffix,
+		SanitizeMetricNames:                  t.cfg.SanitizeMetricNames,
+		AttributeDenyList:                    cloneSlice(t.cfg.AttributeDenyList),
+		AttributeValueAllowList:              cloneAllowList(t.cfg.AttributeValueAllowList),
+		HostTagAttributes:                    cloneSlice(t.cfg.HostTagAttributes),
+		GaugeToDistributionPatterns:          cloneSlice(t.cfg.GaugeToDistributionPatterns),
+		ServiceCheckRules:                    cloneSlice(t.cfg.ServiceCheckRules),
+		MetricRenameRules:                    cloneSlice(t.cfg.MetricRenameRules),
+		HistogramExcludeInfBucket:            t.cfg.HistogramExcludeInfBucket,
+		HistogramExcludedBucketBounds:        cloneSlice(t.cfg.HistogramExcludedBucketBounds),
+		MaxTagCount:                          t.cfg.MaxTagCount,
+		ConstantTags:                         cloneSlice(t.cfg.ConstantTags),
+		TagKeyRenameRules:                    cloneMap(t.cfg.TagKeyRenameRules),
+		TagSorting:                           t.cfg.TagSorting,
+		ValueRange:                           clonePointer(t.cfg.ValueRange),
+		NegativeDeltaMode:                    t.cfg.NegativeDeltaMode,
+		InvalidCumulativeMode:                t.cfg.InvalidCumulativeMode,
+		LogSamplingInterval:                  t.cfg.LogSamplingInterval,
+		MetricTimestampLag:                   t.cfg.MetricTimestampLag,
+		NormalizeSumTemporality:              t.cfg.NormalizeSumTemporality,
+		DeltaTTLRules:                        cloneMap(t.cfg.DeltaTTLRules),
+		SweepInterval:                        t.cfg.sweepInterval,
+		DeltaTTL:                             t.cfg.deltaTTL,
+		DeltaCacheMaxSize:                    t.cfg.deltaCacheMaxSize,
+		CacheStatsCallback:                   t.cfg.cacheStatsCallback,
+		FallbackSourceProvider:               t.cfg.fallbackSourceProvider,
+		MetricFilter:                         t.cfg.metricFilter,
+		DeltaStore:                           t.cfg.deltaStore,
+		UnitConversionTable:                  cloneMap(t.cfg.unitConversionTable),
+		TagValueTransform:                    t.cfg.tagValueTransform,
+		TranslationHooks:                     cloneSlice(t.cfg.translationHooks),
+		AttributeMappingTable:                cloneMappingTable(t.cfg.attributeMappingTable),
+		AttributeMerger:                      clonePointer(t.cfg.attributeMerger),
+	}
+}
+
+// cloneMap returns a copy of m, or nil if m is nil.
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[K]V, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// cloneSlice returns a copy of s, or nil if s is nil.
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
+// clonePointer returns a pointer to a copy of *p, or nil if p is nil.
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	return &clone
+}
+
+// cloneAllowList returns a deep copy of an attribute value allow list.
+func cloneAllowList(allowList map[string][]string) map[string][]string {
+	if allowList == nil {
+		return nil
+	}
+	clone := make(map[string][]string, len(allowList))
+	for key, values := range allowList {
+		clone[key] = cloneSlice(values)
+	}
+	return clone
+}
+
+// cloneMappingTable returns a copy of an attribute mapping table.
+func cloneMappingTable(table *attributes.AttributeMappingTable) *attributes.AttributeMappingTable {
+	if table == nil {
+		return nil
+	}
+	return &attributes.AttributeMappingTable{Mappings: cloneSlice(table.Mappings)}
+}
+
+// Reset clears the delta cache, e.g. after a known reset of the cumulative metrics, and returns the number
+// of removed timeseries. Like the CacheEvictions stat, it counts timeseries rather than cache entries:
+// the start timestamp stored along with each timeseries is not counted, nor are the running totals
+// of WithNormalizeSumTemporality, which are cleared as well.
+// The cache is cleared atomically: points stored concurrently are either counted and removed, or kept.
+// The next point of every cumulative timeseries is then handled as its first point.
+// The counters of the cache (e.g. the evictions reported in TranslatorStats) are reset as well.
+// It is safe to call Reset concurrently with MapMetrics. It has no effect on a store set by WithDeltaStore.
+func (t *Translator) Reset() int {
+	if store, ok := t.prevPts.store.(resetter); ok {
+		return store.reset()
+	}
+	return 0
+}
+
 // isCumulativeMonotonic checks if a metric is a cumulative monotonic metric
 func isCumulativeMonotonic(md pmetric.Metric) bool {
 	switch md.Type() {
@@ -104,6 +370,27 @@ func isCumulativeMonotonic(md pmetric.Metric) bool {
 	return false
 }
 
+// hasNoDataPoints checks if a metric has no datapoints.
+// Metrics with an unsupported type or aggregation temporality are not considered, so that they are reported as such.
+func hasNoDataPoints(md pmetric.Metric) bool {
+	switch md.Type() {
+	case pmetric.MetricTypeGauge:
+		return md.Gauge().DataPoints().Len() == 0
+	case pmetric.MetricTypeSum:
+		return md.Sum().AggregationTemporality() != pmetric.AggregationTemporalityUnspecified &&
+			md.Sum().DataPoints().Len() == 0
+	case pmetric.MetricTypeHistogram:
+		return md.Histogram().AggregationTemporality() != pmetric.AggregationTemporalityUnspecified &&
+			md.Histogram().DataPoints().Len() == 0
+	case pmetric.MetricTypeExponentialHistogram:
+		return md.ExponentialHistogram().AggregationTemporality() == pmetric.AggregationTemporalityDelta &&
+			md.ExponentialHistogram().DataPoints().Len() == 0
+	case pmetric.MetricTypeSummary:
+		return md.Summary().DataPoints().Len() == 0
+	}
+	return false
+}
+
 // isSkippable checks if a value can be skipped (because it is not supported by the backend).
 // It logs that the value is unsupported for debugging since this sometimes means there is a bug.
 func (t *Translator) isSkippable(name string, v float64) bool {
@@ -114,6 +401,262 @@ func (t *Translator) isSkippable(name string, v float64) bool {
 	return skippable
 }
 
+// isStale checks if a datapoint is older than the maximum age set by WithMetricTimestampLag.
+// Stale datapoints are dropped since they would be rejected by the backend.
+func (t *Translator) isStale(ctx context.Context, name string, ts uint64) bool {
+	if t.cfg.MetricTimestampLag <= 0 {
+		return false
+	}
+	age := time.Since(time.Unix(0, int64(ts)))
+	if age <= t.cfg.MetricTimestampLag {
+		return false
+	}
+	t.logger.Warn("Dropping datapoint older than the maximum timestamp lag",
+		zap.String(metricName, name),
+		zap.Duration("age", age),
+		zap.Duration("max timestamp lag", t.cfg.MetricTimestampLag),
+	)
+	recordStats(ctx, func(stats *TranslatorStats) { stats.DroppedStalePoints++ })
+	return true
+}
+
+// applyValueRange applies the range set by WithValueClamp or WithValueDropOutsideRange to a value.
+// It returns the value to report, and false if the value must be dropped.
+func (t *Translator) applyValueRange(name string, v float64) (float64, bool) {
+	r := t.cfg.ValueRange
+	if r == nil || (v >= r.Min && v <= r.Max) {
+		return v, true
+	}
+	if r.Drop {
+		t.logger.Debug("Dropping value outside of the configured range", zap.String(metricName, name), zap.Float64("value", v))
+		return 0, false
+	}
+	t.logger.Warn("Clamping value outside of the configured range", zap.String(metricName, name), zap.Float64("value", v))
+	return math.Min(math.Max(v, r.Min), r.Max), true
+}
+
+// filterAttributes returns the attributes that can be converted to tags, with the values
+// not in the attribute value allow list replaced.
+func (t *Translator) filterAttributes(attrs pcommon.Map) pcommon.Map {
+	return t.restrictAttributeValues(t.denyAttributes(attrs))
+}
+
+// denyAttributes removes the attributes in the attribute deny list.
+// The attributes are copied only if some of them are in the attribute deny list.
+func (t *Translator) denyAttributes(attrs pcommon.Map) pcommon.Map {
+	if len(t.cfg.AttributeDenyList) == 0 {
+		return attrs
+	}
+
+	denied := false
+	attrs.Range(func(key string, _ pcommon.Value) bool {
+		denied = matchesAnyPattern(t.cfg.AttributeDenyList, key)
+		return !denied
+	})
+	if !denied {
+		return attrs
+	}
+
+	filtered := pcommon.NewMap()
+	attrs.CopyTo(filtered)
+	filtered.RemoveIf(func(key string, _ pcommon.Value) bool {
+		return matchesAnyPattern(t.cfg.AttributeDenyList, key)
+	})
+	return filtered
+}
+
+// otherAttributeValue replaces the attribute values that are not in the attribute value allow list.
+const otherAttributeValue = "other"
+
+// restrictAttributeValues replaces the values of the attributes in the attribute value allow list
+// that are not allowed by otherAttributeValue.
+// The attributes are copied only if some of their values are replaced.
+func (t *Translator) restrictAttributeValues(attrs pcommon.Map) pcommon.Map {
+	if len(t.cfg.AttributeValueAllowList) == 0 {
+		return attrs
+	}
+
+	allowed := func(key string, value pcommon.Value) bool {
+		values, ok := t.cfg.AttributeValueAllowList[key]
+		return !ok || slices.Contains(values, value.AsString())
+	}
+	restricted := false
+	attrs.Range(func(key string, value pcommon.Value) bool {
+		restricted = !allowed(key, value)
+		return !restricted
+	})
+	if !restricted {
+		return attrs
+	}
+
+	replaced := pcommon.NewMap()
+	attrs.CopyTo(replaced)
+	replaced.Range(func(key string, value pcommon.Value) bool {
+		if !allowed(key, value) {
+			value.SetStr(otherAttributeValue)
+		}
+		return true
+	})
+	return replaced
+}
+
+// sendMonotonic checks if cumulative monotonic sums with the given Datadog metric name
+// must be reported as deltas, based on the per-metric number mode rules and the global number mode.
+func (t *Translator) sendMonotonic(name string) bool {
+	if mode, ok := lookupPatternRule(t.cfg.NumberModeRules, name); ok {
+		return mode == NumberModeCumulativeToDelta
+	}
+	return t.cfg.SendMonotonic
+}
+
+// histogramMode returns the histogram mode of the histograms with the given Datadog metric name,
+// based on the per-metric histogram mode rules and the global histogram mode.
+func (t *Translator) histogramMode(name string) HistogramMode {
+	if mode, ok := lookupPatternRule(t.cfg.HistogramModeRules, name); ok {
+		return mode
+	}
+	return t.cfg.HistMode
+}
+
+// attributeMappings returns the custom attribute mappings of the translator, if any.
+func (t *Translator) attributeMappings() []attributes.AttributeMapping {
+	if t.cfg.attributeMappingTable == nil {
+		return nil
+	}
+	return t.cfg.attributeMappingTable.Mappings
+}
+
+// noAttributes is an empty attribute map shared by the functions returning no attributes, to avoid allocations.
+// It must not be modified.
+var noAttributes = pcommon.NewMap()
+
+// splitHostTagAttributes splits the attributes that must be converted to host tags from the others.
+// The attributes are only copied if some of them are host tag attributes.
+// The returned host tag attributes must not be modified.
+func (t *Translator) splitHostTagAttributes(attrs pcommon.Map) (hostAttrs pcommon.Map, otherAttrs pcommon.Map) {
+	if len(t.cfg.HostTagAttributes) == 0 {
+		return noAttributes, attrs
+	}
+
+	hostAttrs = pcommon.NewMap()
+	otherAttrs = pcommon.NewMap()
+	attrs.Range(func(key string, value pcommon.Value) bool {
+		if matchesAnyPattern(t.cfg.HostTagAttributes, key) {
+			value.CopyTo(hostAttrs.PutEmpty(key))
+		} else {
+			value.CopyTo(otherAttrs.PutEmpty(key))
+		}
+		return true
+	})
+	if hostAttrs.Len() == 0 {
+		return hostAttrs, attrs
+	}
+	return hostAttrs, otherAttrs
+}
+
+// pointDimensions returns the dimensions of a datapoint with the given attributes.
+func (t *Translator) pointDimensions(ctx context.Context, dims *Dimensions, attrs pcommon.Map) *Dimensions {
+	hostAttrs, attrs := t.splitHostTagAttributes(t.filterAttributes(t.mergeAttributes(ctx, attrs)))
+	pointDims := dims.AddTags(t.transformTags(getTags(attrs))...)
+	if hostAttrs.Len() > 0 {
+		pointDims = pointDims.addHostTags(t.transformTags(getTags(hostAttrs))...)
+	}
+	// Resource and datapoint attributes may result in the same tags.
+	pointDims.tags = attributes.DedupTags(pointDims.tags)
+	if t.cfg.MaxTagCount > 0 && len(pointDims.tags) > t.cfg.MaxTagCount {
+		// Sort the tags so that the same tags are kept for every datapoint.
+		sort.Strings(pointDims.tags)
+		t.logger.Warn("Too many tags, truncating",
+			zap.String(metricName, pointDims.name),
+			zap.Int("tag count", len(pointDims.tags)),
+			zap.Int("max tag count", t.cfg.MaxTagCount),
+		)
+		pointDims.tags = pointDims.tags[:t.cfg.MaxTagCount]
+		recordStats(ctx, func(stats *TranslatorStats) { stats.TagsTruncated++ })
+	}
+	for _, tag := range t.cfg.ConstantTags {
+		tag = t.renameTagKey(tag)
+		if !slices.Contains(pointDims.tags, tag) {
+			pointDims.tags = append(pointDims.tags, tag)
+		}
+	}
+	return pointDims
+}
+
+// prefixResourceTags prepends the resource attribute prefix, if any, to the keys of the given tags.
+func (t *Translator) prefixResourceTags(tags []string) []string {
+	if t.cfg.ResourceAttributePrefix == "" {
+		return tags
+	}
+	prefixed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		prefixed = append(prefixed, t.cfg.ResourceAttributePrefix+tag)
+	}
+	return prefixed
+}
+
+// sdkMetadataTagPrefix is the prefix of the tags built from the SDK metadata.
+const sdkMetadataTagPrefix = "otel.sdk."
+
+// sdkMetadataTags returns the tags built from the SDK metadata of a resource, if enabled.
+func (t *Translator) sdkMetadataTags(attrs pcommon.Map) []string {
+	if !t.cfg.SDKMetadataAsTags {
+		return nil
+	}
+	sdk := attributes.SDKMetadataFromAttributes(attrs)
+	var tags []string
+	for _, field := range []struct{ key, value string }{
+		{"name", sdk.Name},
+		{"language", sdk.Language},
+		{"version", sdk.Version},
+	} {
+		if field.value != "" {
+			tags = append(tags, sdkMetadataTagPrefix+field.key+":"+field.value)
+		}
+	}
+	return tags
+}
+
+// kubernetesPodLabelTags returns the tags built from the Kubernetes pod labels of a resource,
+// following the pod label mapping.
+func (t *Translator) kubernetesPodLabelTags(attrs pcommon.Map) []string {
+	if len(t.cfg.KubernetesPodLabelsAsTags) == 0 {
+		return nil
+	}
+	return attributes.PodLabelTags(attrs, t.cfg.KubernetesPodLabelsAsTags)
+}
+
+// transformTags applies the tag value transform and the tag key rename rules, if any, to the given tags.
+// The value transform is given the original key. The tags are copied, since they may be shared with other metrics.
+func (t *Translator) transformTags(tags []string) []string {
+	if t.cfg.tagValueTransform == nil && len(t.cfg.TagKeyRenameRules) == 0 {
+		return tags
+	}
+	transformed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		key, value, found := strings.Cut(tag, ":")
+		if found && t.cfg.tagValueTransform != nil {
+			tag = key + ":" + t.cfg.tagValueTransform(key, value)
+		}
+		transformed = append(transformed, t.renameTagKey(tag))
+	}
+	return transformed
+}
+
+// renameTagKey renames the key of the given tag following the tag key rename rules.
+// Tags are renamed at most once, so that rules renaming keys into each other do not loop.
+func (t *Translator) renameTagKey(tag string) string {
+	key, value, found := strings.Cut(tag, ":")
+	newKey, ok := t.cfg.TagKeyRenameRules[key]
+	if !ok {
+		return tag
+	}
+	if !found {
+		return newKey
+	}
+	return newKey + ":" + value
+}
+
 // mapNumberMetrics maps double datapoints into Datadog metrics
 func (t *Translator) mapNumberMetrics(
 	ctx context.Context,
@@ -125,7 +668,96 @@ func (t *Translator) mapNumberMetrics(
 
 	for i := 0; i < slice.Len(); i++ {
 		p := slice.At(i)
-		pointDims := dims.WithAttributeMap(p.Attributes())
+		pointDims := t.pointDimensions(ctx, dims, p.Attributes())
+		if t.isStale(ctx, pointDims.name, uint64(p.Timestamp())) {
+			continue
+		}
+		if p.Flags().NoRecordedValue() {
+			t.mapNoRecordedValue(ctx, consumer, pointDims, dt, uint64(p.Timestamp()))
+			continue
+		}
+		var val float64
+		switch p.ValueType() {
+		case pmetric.NumberDataPointValueTypeDouble:
+			val = p.DoubleValue()
+		case pmetric.NumberDataPointValueTypeInt:
+			val = float64(p.IntValue())
+		}
+
+		if t.isSkippable(pointDims.name, val) {
+			continue
+		}
+		val, ok := t.applyValueRange(pointDims.name, val)
+		if !ok {
+			continue
+		}
+
+		if dt == Count {
+			interval := intervalSeconds(uint64(p.StartTimestamp()), uint64(p.Timestamp()))
+			consumeTimeSeriesWithInterval(ctx, consumer, pointDims, dt, uint64(p.Timestamp()), interval, val)
+		} else {
+			consumer.ConsumeTimeSeries(ctx, pointDims, dt, uint64(p.Timestamp()), val)
+			t.mapExemplars(ctx, consumer, pointDims, uint64(p.Timestamp()), p.Exemplars(), 1)
+		}
+	}
+}
+
+// mapNoRecordedValue handles a number datapoint with no recorded value, according to the stale mode.
+func (t *Translator) mapNoRecordedValue(ctx context.Context, consumer TimeSeriesConsumer, dims *Dimensions, dt DataType, ts uint64) {
+	if t.cfg.StaleMode == StaleModeEmitNaN {
+		consumer.ConsumeTimeSeries(ctx, dims, dt, ts, math.NaN())
+		return
+	}
+	t.logger.Debug(noRecordedValueMessage, zap.String(metricName, dims.name))
+}
+
+// intervalSeconds returns the length in seconds of the window between the given timestamps,
+// or zero if the start timestamp is unknown.
+func intervalSeconds(startTs, ts uint64) int64 {
+	if startTs == 0 || startTs >= ts {
+		return 0
+	}
+	return int64((ts - startTs) / uint64(time.Second))
+}
+
+// consumeTimeSeriesWithInterval passes a metric along with its interval to consumers implementing
+// TimeSeriesIntervalConsumer. Other consumers only get the metric.
+func consumeTimeSeriesWithInterval(
+	ctx context.Context,
+	consumer TimeSeriesConsumer,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	interval int64,
+	value float64,
+) {
+	if c, ok := consumer.(TimeSeriesIntervalConsumer); ok {
+		c.ConsumeTimeSeriesWithInterval(ctx, dimensions, typ, timestamp, interval, value)
+		return
+	}
+	consumer.ConsumeTimeSeries(ctx, dimensions, typ, timestamp, value)
+}
+
+// mapNumberDistributionMetrics maps number datapoints into Datadog distributions,
+// with one distribution point per datapoint. Values are multiplied by the given scale.
+func (t *Translator) mapNumberDistributionMetrics(
+	ctx context.Context,
+	consumer SketchConsumer,
+	dims *Dimensions,
+	slice pmetric.NumberDataPointSlice,
+	scale float64,
+) {
+	for i := 0; i < slice.Len(); i++ {
+		p := slice.At(i)
+		pointDims := t.pointDimensions(ctx, dims, p.Attributes())
+		if t.isStale(ctx, pointDims.name, uint64(p.Timestamp())) {
+			continue
+		}
+		if p.Flags().NoRecordedValue() {
+			// Distributions can't represent a missing value.
+			t.logger.Debug(noRecordedValueMessage, zap.String(metricName, pointDims.name))
+			continue
+		}
 		var val float64
 		switch p.ValueType() {
 		case pmetric.NumberDataPointValueTypeDouble:
@@ -137,8 +769,15 @@ func (t *Translator) mapNumberMetrics(
 		if t.isSkippable(pointDims.name, val) {
 			continue
 		}
+		val, ok := t.applyValueRange(pointDims.name, val)
+		if !ok {
+			continue
+		}
 
-		consumer.ConsumeTimeSeries(ctx, pointDims, dt, uint64(p.Timestamp()), val)
+		as := &quantile.Agent{}
+		as.Insert(val*scale, 1)
+		consumer.ConsumeSketch(ctx, pointDims, uint64(p.Timestamp()), as.Finish())
+		t.mapExemplars(ctx, consumer, pointDims, uint64(p.Timestamp()), p.Exemplars(), scale)
 	}
 }
 
@@ -162,7 +801,15 @@ func (t *Translator) mapNumberMonotonicMetrics(
 		p := slice.At(i)
 		ts := uint64(p.Timestamp())
 		startTs := uint64(p.StartTimestamp())
-		pointDims := dims.WithAttributeMap(p.Attributes())
+		pointDims := t.pointDimensions(ctx, dims, p.Attributes())
+		if t.isStale(ctx, pointDims.name, ts) {
+			continue
+		}
+		if p.Flags().NoRecordedValue() {
+			// The previous point is kept, so that the next delta covers the missing value.
+			t.mapNoRecordedValue(ctx, consumer, pointDims, Count, ts)
+			continue
+		}
 
 		var val float64
 		switch p.ValueType() {
@@ -176,10 +823,76 @@ func (t *Translator) mapNumberMonotonicMetrics(
 			continue
 		}
 
-		if dx, ok := t.prevPts.MonotonicDiff(pointDims, startTs, ts, val); ok {
-			consumer.ConsumeTimeSeries(ctx, pointDims, Count, ts, dx)
+		dropInvalid := t.cfg.InvalidCumulativeMode == InvalidCumulativeModeWarnAndDrop
+		dx, ok, reset, invalid, prevTs := t.prevPts.MonotonicDiffWithReset(pointDims, startTs, ts, val, dropInvalid)
+		if invalid {
+			t.logger.Warn("Cumulative value decreased without a reset",
+				zap.String(metricName, pointDims.name),
+				zap.Float64("previous value", val-dx),
+				zap.Float64("value", val),
+				zap.String("invalid cumulative mode", string(t.cfg.InvalidCumulativeMode)),
+				zap.String("negative delta mode", string(t.cfg.NegativeDeltaMode)),
+			)
+			recordStats(ctx, func(stats *TranslatorStats) { stats.InvalidCumulativeValues++ })
+		}
+		if reset {
+			recordStats(ctx, func(stats *TranslatorStats) { stats.NegativeDeltasReset++ })
+		}
+		if ok {
+			dx, ok = t.applyValueRange(pointDims.name, dx)
+		}
+		if ok {
+			// The delta is computed over the window since the previous point.
+			consumeTimeSeriesWithInterval(ctx, consumer, pointDims, Count, ts, intervalSeconds(prevTs, ts), dx)
+		} else if reset && (t.cfg.NegativeDeltaMode == NegativeDeltaModeReset ||
+			invalid && t.cfg.InvalidCumulativeMode == InvalidCumulativeModeWarnAndReset) {
+			consumer.ConsumeTimeSeries(ctx, pointDims, Count, ts, 0)
+		}
+	}
+}
+
+// accumulateDeltas converts the points of a delta sum to cumulative points by accumulating their values into
+// a running total per timeseries (see WithNormalizeSumTemporality). The cumulative points start at the start
+// timestamp of the first point of their timeseries. For monotonic sums, a zero point is added at that start
+// timestamp before the first point of a timeseries, so that the delta conversion doesn't drop its value.
+func (t *Translator) accumulateDeltas(
+	dims *Dimensions,
+	slice pmetric.NumberDataPointSlice,
+	monotonic bool,
+) pmetric.NumberDataPointSlice {
+	cumulative := pmetric.NewNumberDataPointSlice()
+	cumulative.EnsureCapacity(slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		p := slice.At(i)
+		ts := uint64(p.Timestamp())
+
+		var val float64
+		switch p.ValueType() {
+		case pmetric.NumberDataPointValueTypeDouble:
+			val = p.DoubleValue()
+		case pmetric.NumberDataPointValueTypeInt:
+			val = float64(p.IntValue())
+		}
+		if p.Flags().NoRecordedValue() || math.IsInf(val, 0) || math.IsNaN(val) {
+			// These points are not accumulated, and are handled as such by the cumulative translation.
+			p.CopyTo(cumulative.AppendEmpty())
+			continue
+		}
+
+		total, startTs, first := t.prevPts.Accumulate(dims.WithAttributeMap(p.Attributes()), uint64(p.StartTimestamp()), ts, val)
+		if first && monotonic && startTs != 0 && startTs < ts {
+			zero := cumulative.AppendEmpty()
+			p.Attributes().CopyTo(zero.Attributes())
+			zero.SetStartTimestamp(pcommon.Timestamp(startTs))
+			zero.SetTimestamp(pcommon.Timestamp(startTs))
+			zero.SetDoubleValue(0)
 		}
+		cp := cumulative.AppendEmpty()
+		p.CopyTo(cp)
+		cp.SetStartTimestamp(pcommon.Timestamp(startTs))
+		cp.SetDoubleValue(total)
 	}
+	return cumulative
 }
 
 func getBounds(p pmetric.HistogramDataPoint, idx int) (lowerBound float64, upperBound float64) {
@@ -213,6 +926,22 @@ type histogramInfo struct {
 	ok bool
 }
 
+// cumulativeDiff returns the delta of a value of a cumulative histogram or summary since the previous point.
+// The delta is only valid if `ok` is true. With NegativeDeltaModeReset, the first point after a reset of a
+// known timeseries has a zero delta rather than being dropped, like the points of cumulative monotonic sums.
+func (t *Translator) cumulativeDiff(dims *Dimensions, monotonic bool, startTs, ts uint64, val float64) (dx float64, ok bool) {
+	var reset bool
+	if monotonic {
+		dx, ok, reset, _, _ = t.prevPts.MonotonicDiffWithReset(dims, startTs, ts, val, false)
+	} else {
+		dx, ok, reset = t.prevPts.DiffWithReset(dims, startTs, ts, val)
+	}
+	if reset && t.cfg.NegativeDeltaMode == NegativeDeltaModeReset {
+		return 0, true
+	}
+	return dx, ok
+}
+
 func (t *Translator) getSketchBuckets(
 	ctx context.Context,
 	consumer SketchConsumer,
@@ -244,11 +973,16 @@ func (t *Translator) getSketchBuckets(
 			lowerBound = upperBound
 		}
 
-		count := p.BucketCounts().At(j)
-		if delta {
+		count, ok := float64(p.BucketCounts().At(j)), true
+		if !delta {
+			count, ok = t.cumulativeDiff(bucketDims, true, startTs, ts, count)
+		}
+		if ok {
+			count, ok = t.applyValueRange(pointDims.name, count)
+		}
+		// A bucket can't hold a negative number of values.
+		if ok && count > 0 {
 			as.InsertInterpolate(lowerBound, upperBound, uint(count))
-		} else if dx, ok := t.prevPts.Diff(bucketDims, startTs, ts, float64(count)); ok {
-			as.InsertInterpolate(lowerBound, upperBound, uint(dx))
 		}
 
 	}
@@ -279,9 +1013,23 @@ func (t *Translator) getSketchBuckets(
 		}
 
 		consumer.ConsumeSketch(ctx, pointDims, ts, sketch)
+		t.mapExemplars(ctx, consumer, pointDims, ts, p.Exemplars(), 1)
 	}
 }
 
+// isExcludedBucket checks if a bucket with the given upper bound must not be exported as a count.
+func (t *Translator) isExcludedBucket(upperBound float64) bool {
+	if t.cfg.HistogramExcludeInfBucket && math.IsInf(upperBound, 1) {
+		return true
+	}
+	for _, bound := range t.cfg.HistogramExcludedBucketBounds {
+		if bound == upperBound {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *Translator) getLegacyBuckets(
 	ctx context.Context,
 	consumer TimeSeriesConsumer,
@@ -296,16 +1044,23 @@ func (t *Translator) getLegacyBuckets(
 	baseBucketDims := pointDims.WithSuffix("bucket")
 	for idx := 0; idx < p.BucketCounts().Len(); idx++ {
 		lowerBound, upperBound := getBounds(p, idx)
+		if t.isExcludedBucket(upperBound) {
+			continue
+		}
 		bucketDims := baseBucketDims.AddTags(
 			fmt.Sprintf("lower_bound:%s", formatFloat(lowerBound)),
 			fmt.Sprintf("upper_bound:%s", formatFloat(upperBound)),
 		)
 
-		count := float64(p.BucketCounts().At(idx))
-		if delta {
+		count, ok := float64(p.BucketCounts().At(idx)), true
+		if !delta {
+			count, ok = t.cumulativeDiff(bucketDims, true, startTs, ts, count)
+		}
+		if ok {
+			count, ok = t.applyValueRange(bucketDims.name, count)
+		}
+		if ok {
 			consumer.ConsumeTimeSeries(ctx, bucketDims, Count, ts, count)
-		} else if dx, ok := t.prevPts.Diff(bucketDims, startTs, ts, count); ok {
-			consumer.ConsumeTimeSeries(ctx, bucketDims, Count, ts, dx)
 		}
 	}
 }
@@ -330,18 +1085,27 @@ func (t *Translator) mapHistogramMetrics(
 	slice pmetric.HistogramDataPointSlice,
 	delta bool,
 ) {
+	histMode := t.histogramMode(dims.name)
 	for i := 0; i < slice.Len(); i++ {
 		p := slice.At(i)
 		startTs := uint64(p.StartTimestamp())
 		ts := uint64(p.Timestamp())
-		pointDims := dims.WithAttributeMap(p.Attributes())
+		pointDims := t.pointDimensions(ctx, dims, p.Attributes())
+		if t.isStale(ctx, pointDims.name, ts) {
+			continue
+		}
+		if p.Flags().NoRecordedValue() {
+			t.logger.Debug(noRecordedValueMessage, zap.String(metricName, pointDims.name))
+			continue
+		}
 
 		histInfo := histogramInfo{ok: true}
 
+		// The count is monotonic: a decrease means the histogram was reset.
 		countDims := pointDims.WithSuffix("count")
 		if delta {
 			histInfo.count = p.Count()
-		} else if dx, ok := t.prevPts.Diff(countDims, startTs, ts, float64(p.Count())); ok {
+		} else if dx, ok := t.cumulativeDiff(countDims, true, startTs, ts, float64(p.Count())); ok {
 			histInfo.count = uint64(dx)
 		} else { // not ok
 			histInfo.ok = false
@@ -351,7 +1115,7 @@ func (t *Translator) mapHistogramMetrics(
 		if !t.isSkippable(sumDims.name, p.Sum()) {
 			if delta {
 				histInfo.sum = p.Sum()
-			} else if dx, ok := t.prevPts.Diff(sumDims, startTs, ts, p.Sum()); ok {
+			} else if dx, ok := t.cumulativeDiff(sumDims, false, startTs, ts, p.Sum()); ok {
 				histInfo.sum = dx
 			} else { // not ok
 				histInfo.ok = false
@@ -370,12 +1134,14 @@ func (t *Translator) mapHistogramMetrics(
 			histInfo.hasMaxFromLastTimeWindow = delta || t.prevPts.PutAndCheckMax(maxDims, startTs, ts, p.Max())
 		}
 
-		if t.cfg.SendHistogramAggregations && histInfo.ok {
+		if histInfo.ok {
 			// We only send the sum and count if both values were ok.
-			consumer.ConsumeTimeSeries(ctx, countDims, Count, ts, float64(histInfo.count))
-			consumer.ConsumeTimeSeries(ctx, sumDims, Count, ts, histInfo.sum)
+			if t.cfg.SendHistogramCountSum {
+				consumer.ConsumeTimeSeries(ctx, countDims, Count, ts, float64(histInfo.count))
+				consumer.ConsumeTimeSeries(ctx, sumDims, Count, ts, histInfo.sum)
+			}
 
-			if delta {
+			if t.cfg.SendHistogramMinMax && delta {
 				// We could check is[Min/Max]FromLastTimeWindow here, and report the minimum/maximum
 				// for cumulative timeseries when we know it. These would be metrics with progressively
 				// less frequency which would be confusing, so we limit reporting these metrics to delta points,
@@ -390,7 +1156,7 @@ func (t *Translator) mapHistogramMetrics(
 			}
 		}
 
-		switch t.cfg.HistMode {
+		switch histMode {
 		case HistogramModeCounters:
 			t.getLegacyBuckets(ctx, consumer, pointDims, p, delta)
 		case HistogramModeDistributions:
@@ -438,12 +1204,19 @@ func (t *Translator) mapSummaryMetrics(
 		p := slice.At(i)
 		startTs := uint64(p.StartTimestamp())
 		ts := uint64(p.Timestamp())
-		pointDims := dims.WithAttributeMap(p.Attributes())
+		pointDims := t.pointDimensions(ctx, dims, p.Attributes())
+		if t.isStale(ctx, pointDims.name, ts) {
+			continue
+		}
+		if p.Flags().NoRecordedValue() {
+			t.logger.Debug(noRecordedValueMessage, zap.String(metricName, pointDims.name))
+			continue
+		}
 
 		// count and sum are increasing; we treat them as cumulative monotonic sums.
 		{
 			countDims := pointDims.WithSuffix("count")
-			if dx, ok := t.prevPts.Diff(countDims, startTs, ts, float64(p.Count())); ok && !t.isSkippable(countDims.name, dx) {
+			if dx, ok := t.cumulativeDiff(countDims, false, startTs, ts, float64(p.Count())); ok && !t.isSkippable(countDims.name, dx) {
 				consumer.ConsumeTimeSeries(ctx, countDims, Count, ts, dx)
 			}
 		}
@@ -451,13 +1224,13 @@ func (t *Translator) mapSummaryMetrics(
 		{
 			sumDims := pointDims.WithSuffix("sum")
 			if !t.isSkippable(sumDims.name, p.Sum()) {
-				if dx, ok := t.prevPts.Diff(sumDims, startTs, ts, p.Sum()); ok {
+				if dx, ok := t.cumulativeDiff(sumDims, false, startTs, ts, p.Sum()); ok {
 					consumer.ConsumeTimeSeries(ctx, sumDims, Count, ts, dx)
 				}
 			}
 		}
 
-		if t.cfg.Quantiles {
+		if t.cfg.SummaryMode == SummaryModeGauges {
 			baseQuantileDims := pointDims.WithSuffix("quantile")
 			quantiles := p.QuantileValues()
 			for i := 0; i < quantiles.Len(); i++ {
@@ -474,6 +1247,15 @@ func (t *Translator) mapSummaryMetrics(
 	}
 }
 
+// metricName returns the Datadog metric name for the given OTLP metric name.
+func (t *Translator) metricName(name string) string {
+	name = t.cfg.MetricNamePrefix + name + t.cfg.MetricNameSuffix
+	if t.cfg.SanitizeMetricNames {
+		name = sanitizeMetricName(name)
+	}
+	return name
+}
+
 func (t *Translator) source(m pcommon.Map) (source.Source, error) {
 	src, ok := attributes.SourceFromAttrs(m)
 	if !ok {
@@ -587,13 +1369,45 @@ func mapHistogramRuntimeMetricWithAttributes(md pmetric.Metric, metricsArray pme
 	}
 }
 
-// MapMetrics maps OTLP metrics into the DataDog format
+// MapMetrics maps OTLP metrics into the DataDog format.
+//
+// OTLP metric types are mapped as follows:
+//   - Gauge: Datadog gauge.
+//   - Sum: delta sums are Datadog counts. Cumulative monotonic sums are Datadog counts with
+//     NumberModeCumulativeToDelta and gauges with NumberModeRawValue. Other cumulative sums are gauges.
+//   - Histogram (delta or cumulative): depends on the HistogramMode, with optional count, sum, min and max metrics.
+//   - ExponentialHistogram (delta only): Datadog distribution, with optional count, sum, min and max metrics.
+//   - Summary: depends on the SummaryMode.
+//
+// Unsupported types and aggregation temporalities are skipped, and logged at debug level.
+//
+// The context is checked for cancellation before each resource is mapped: if it is done,
+// MapMetrics returns the context error, and the metrics of the resources mapped so far
+// have already been passed to the consumer.
 func (t *Translator) MapMetrics(ctx context.Context, md pmetric.Metrics, consumer Consumer) (Metadata, error) {
 	metadata := Metadata{
 		Languages: []string{},
 	}
+	ctx = contextWithStats(ctx, &metadata.Stats)
+	if t.cfg.DryRun {
+		consumer = &dryRunConsumer{stats: &metadata.Stats}
+	}
+	// Tag sorting, rename rules and hooks only apply to translated metrics: hosts, tags and service checks are reported to the original consumer.
+	baseConsumer := consumer
+	if t.cfg.TagSorting {
+		consumer = &sortedTagsConsumer{Consumer: consumer}
+	}
+	if len(t.cfg.translationHooks) > 0 {
+		consumer = &hookConsumer{Consumer: consumer, hooks: t.cfg.translationHooks}
+	}
+	if len(t.cfg.MetricRenameRules) > 0 {
+		consumer = &renameConsumer{Consumer: consumer, rules: t.cfg.MetricRenameRules}
+	}
 	rms := md.ResourceMetrics()
 	for i := 0; i < rms.Len(); i++ {
+		if err := ctx.Err(); err != nil {
+			return metadata, err
+		}
 		rm := rms.At(i)
 		if v, ok := rm.Resource().Attributes().Get(keyAPMStats); ok && v.Bool() {
 			// these resource metrics are an APM Stats payload; consume it as such
@@ -612,33 +1426,63 @@ func (t *Translator) MapMetrics(ctx context.Context, md pmetric.Metrics, consume
 		switch src.Kind {
 		case source.HostnameKind:
 			host = src.Identifier
-			if c, ok := consumer.(HostConsumer); ok {
+			if c, ok := baseConsumer.(HostConsumer); ok {
 				c.ConsumeHost(host)
 			}
+			if c, ok := baseConsumer.(HostMetadataConsumer); ok && t.cfg.ProcessMetadata {
+				if md := attributes.ProcessAttributesToHostMetadata(rm.Resource().Attributes()); md != (attributes.HostMetadata{}) {
+					c.ConsumeHostMetadata(host, md)
+				}
+			}
 		case source.AWSECSFargateKind:
-			if c, ok := consumer.(TagsConsumer); ok {
+			if c, ok := baseConsumer.(TagsConsumer); ok {
 				c.ConsumeTag(src.Tag())
 			}
 		}
 
 		// Fetch tags from attributes.
-		attributeTags := attributes.TagsFromAttributes(rm.Resource().Attributes())
+		hostAttrs, resourceAttrs := t.splitHostTagAttributes(t.filterAttributes(rm.Resource().Attributes()))
+		attributeTags := t.prefixResourceTags(attributes.TagsFromAttributesVersioned(resourceAttrs, rm.SchemaUrl(), t.attributeMappings()))
+		attributeTags = append(attributeTags, t.sdkMetadataTags(resourceAttrs)...)
+		attributeTags = append(attributeTags, t.prefixResourceTags(t.kubernetesPodLabelTags(resourceAttrs))...)
+		var hostTags []string
+		if hostAttrs.Len() > 0 {
+			hostTags = t.transformTags(attributes.TagsFromResourceAttributes(hostAttrs))
+		}
 		ilms := rm.ScopeMetrics()
 		for j := 0; j < ilms.Len(); j++ {
 			ilm := ilms.At(j)
 			metricsArray := ilm.Metrics()
+			// The attribute merger, if any, merges the resource and scope attributes into the datapoint attributes.
+			ctx := contextWithMergedAttributes(ctx, rm.Resource().Attributes(), ilm.Scope().Attributes())
 
 			var additionalTags []string
-			if t.cfg.InstrumentationScopeMetadataAsTags {
-				additionalTags = append(attributeTags, instrumentationscope.TagsFromInstrumentationScopeMetadata(ilm.Scope())...)
+			if t.cfg.InstrumentationScopeMetadataAsTags && t.cfg.ScopeVersionTag {
+				additionalTags = append(attributeTags, instrumentationscope.TagsFromInstrumentationScopeMetadataWithPrefix(ilm.Scope(), t.cfg.ScopeTagPrefix)...)
+			} else if t.cfg.InstrumentationScopeMetadataAsTags {
+				additionalTags = append(attributeTags, instrumentationscope.TagsFromInstrumentationScopeNameWithPrefix(ilm.Scope(), t.cfg.ScopeTagPrefix)...)
 			} else if t.cfg.InstrumentationLibraryMetadataAsTags {
 				additionalTags = append(attributeTags, instrumentationlibrary.TagsFromInstrumentationLibraryMetadata(ilm.Scope())...)
 			} else {
 				additionalTags = attributeTags
 			}
+			additionalTags = t.transformTags(additionalTags)
 
 			for k := 0; k < metricsArray.Len(); k++ {
 				md := metricsArray.At(k)
+				if t.cfg.metricFilter != nil && !t.cfg.metricFilter.Include(md.Name()) {
+					t.logger.Debug("Metric excluded by filter", zap.String(metricName, md.Name()))
+					metadata.Stats.DroppedMetrics++
+					// Excluded metrics are also counted as unsupported, so that misconfigured filters show up.
+					metadata.Stats.UnsupportedMetricTypes++
+					continue
+				}
+				if hasNoDataPoints(md) {
+					// Metrics that were not observed yet may have no datapoints.
+					t.logger.Debug("Skipping metric with no datapoints", zap.String(metricName, md.Name()))
+					metadata.Stats.EmptyDataPoints++
+					continue
+				}
 				if v, ok := runtimeMetricsMappings[md.Name()]; ok {
 					metadata.Languages = extractLanguageTag(md.Name(), metadata.Languages)
 					for _, mp := range v {
@@ -658,30 +1502,68 @@ func (t *Translator) MapMetrics(ctx context.Context, md pmetric.Metrics, consume
 						}
 					}
 				}
+				name := md.Name()
+				scale := 1.0
+				var numberConsumer TimeSeriesConsumer = consumer
+				if conversion, ok := t.unitConversion(md); ok {
+					name += conversion.Suffix
+					scale = conversion.Scale
+					numberConsumer = &scaledTimeSeriesConsumer{consumer: consumer, scale: scale}
+				}
+				mappedName := t.metricName(name)
+				if mappedName == "" && t.cfg.SanitizeMetricNames {
+					// Names without any letter are empty once sanitized, and would be rejected by Datadog.
+					t.logger.Debug("Dropping metric with an empty sanitized name", zap.String(metricName, md.Name()))
+					metadata.Stats.DroppedMetrics++
+					continue
+				}
 				baseDims := &Dimensions{
-					name:     md.Name(),
-					tags:     additionalTags,
-					host:     host,
-					originID: attributes.OriginIDFromAttributes(rm.Resource().Attributes()),
+					name:         mappedName,
+					tags:         additionalTags,
+					host:         host,
+					hostTags:     hostTags,
+					originID:     attributes.OriginIDFromAttributes(rm.Resource().Attributes()),
+					scopeVersion: ilm.Scope().Version(),
 				}
 				switch md.Type() {
 				case pmetric.MetricTypeGauge:
-					t.mapNumberMetrics(ctx, consumer, baseDims, Gauge, md.Gauge().DataPoints())
+					if matchesAnyPattern(t.cfg.GaugeToDistributionPatterns, baseDims.name) {
+						t.mapNumberDistributionMetrics(ctx, consumer, baseDims, md.Gauge().DataPoints(), scale)
+					} else {
+						t.mapNumberMetrics(ctx, numberConsumer, baseDims, Gauge, md.Gauge().DataPoints())
+					}
+					if c, ok := baseConsumer.(ServiceCheckConsumer); ok && len(t.cfg.ServiceCheckRules) > 0 {
+						t.mapServiceChecks(ctx, c, baseDims, md.Gauge().DataPoints())
+					}
 				case pmetric.MetricTypeSum:
-					switch md.Sum().AggregationTemporality() {
+					temporality := md.Sum().AggregationTemporality()
+					dataPoints := md.Sum().DataPoints()
+					if temporality == pmetric.AggregationTemporalityDelta &&
+						t.cfg.NormalizeSumTemporality == pmetric.AggregationTemporalityCumulative {
+						// Delta sums are translated like their cumulative counterparts.
+						dataPoints = t.accumulateDeltas(baseDims, dataPoints, md.Sum().IsMonotonic())
+						temporality = pmetric.AggregationTemporalityCumulative
+					}
+					switch temporality {
 					case pmetric.AggregationTemporalityCumulative:
-						if t.cfg.SendMonotonic && isCumulativeMonotonic(md) {
-							t.mapNumberMonotonicMetrics(ctx, consumer, baseDims, md.Sum().DataPoints())
+						if t.sendMonotonic(baseDims.name) && md.Sum().IsMonotonic() {
+							t.mapNumberMonotonicMetrics(ctx, numberConsumer, baseDims, dataPoints)
 						} else {
-							t.mapNumberMetrics(ctx, consumer, baseDims, Gauge, md.Sum().DataPoints())
+							t.mapNumberMetrics(ctx, numberConsumer, baseDims, Gauge, dataPoints)
 						}
 					case pmetric.AggregationTemporalityDelta:
-						t.mapNumberMetrics(ctx, consumer, baseDims, Count, md.Sum().DataPoints())
+						if md.Sum().IsMonotonic() {
+							t.mapNumberMetrics(ctx, numberConsumer, baseDims, Count, dataPoints)
+						} else {
+							// UpDownCounters are reported as gauges, like cumulative ones.
+							t.mapNumberMetrics(ctx, numberConsumer, baseDims, Gauge, dataPoints)
+						}
 					default: // pmetric.AggregationTemporalityUnspecified or any other not supported type
 						t.logger.Debug("Unknown or unsupported aggregation temporality",
 							zap.String(metricName, md.Name()),
 							zap.Any("aggregation temporality", md.Sum().AggregationTemporality()),
 						)
+						metadata.Stats.DroppedMetrics++
 						continue
 					}
 				case pmetric.MetricTypeHistogram:
@@ -694,6 +1576,7 @@ func (t *Translator) MapMetrics(ctx context.Context, md pmetric.Metrics, consume
 							zap.String("metric name", md.Name()),
 							zap.Any("aggregation temporality", md.Histogram().AggregationTemporality()),
 						)
+						metadata.Stats.DroppedMetrics++
 						continue
 					}
 				case pmetric.MetricTypeExponentialHistogram:
@@ -706,16 +1589,27 @@ func (t *Translator) MapMetrics(ctx context.Context, md pmetric.Metrics, consume
 							zap.String("metric name", md.Name()),
 							zap.Any("aggregation temporality", md.ExponentialHistogram().AggregationTemporality()),
 						)
+						metadata.Stats.DroppedMetrics++
 						continue
 					}
 				case pmetric.MetricTypeSummary:
+					if t.cfg.SummaryMode == SummaryModeSkip {
+						t.logger.Debug("Skipping summary metric", zap.String(metricName, md.Name()))
+						metadata.Stats.DroppedMetrics++
+						continue
+					}
 					t.mapSummaryMetrics(ctx, consumer, baseDims, md.Summary().DataPoints())
 				default: // pmetric.MetricDataTypeNone or any other not supported type
 					t.logger.Debug("Unknown or unsupported metric type", zap.String(metricName, md.Name()), zap.Any("data type", md.Type()))
+					metadata.Stats.UnsupportedMetricTypes++
 					continue
 				}
 			}
 		}
 	}
+	// Evictions happen both between calls, when entries expire, and while mapping, when the cache is full.
+	if counter, ok := t.prevPts.store.(evictionCounter); ok {
+		metadata.Stats.CacheEvictions = counter.takeEvictions()
+	}
 	return metadata, nil
 }
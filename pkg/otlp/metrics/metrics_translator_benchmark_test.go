@@ -18,6 +18,9 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -240,6 +243,130 @@ func benchmarkMapMetrics(metrics pmetric.Metrics, b *testing.B) {
 	}
 }
 
+// createBenchmarkCumulativeSumMetrics creates n monotonic cumulative Sum metrics with d data points each.
+// Each data point has a distinct attribute, and hence its own entry in the delta cache.
+func createBenchmarkCumulativeSumMetrics(n int, d int) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr(attributes.AttributeDatadogHostname, testHostname)
+	metricsArray := rm.ScopeMetrics().AppendEmpty().Metrics()
+	metricsArray.EnsureCapacity(n)
+
+	startTs := int(getProcessStartTime()) + 1
+	for i := 0; i < n; i++ {
+		met := metricsArray.AppendEmpty()
+		met.SetName(fmt.Sprintf("cumulative.sum.%d", i))
+		met.SetEmptySum()
+		met.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		met.Sum().SetIsMonotonic(true)
+		dps := met.Sum().DataPoints()
+		dps.EnsureCapacity(d)
+		for j := 0; j < d; j++ {
+			dp := dps.AppendEmpty()
+			dp.Attributes().PutStr("instance", fmt.Sprintf("instance-%d", j))
+			dp.SetStartTimestamp(seconds(startTs))
+			dp.SetTimestamp(seconds(startTs + 1))
+			dp.SetDoubleValue(float64(10 * (i + j)))
+		}
+	}
+
+	return md
+}
+
+// createBenchmarkDeltaHistogramMetrics creates n delta Histogram data points, each with the given number of
+// explicit bucket boundaries and a count in every bucket.
+func createBenchmarkDeltaHistogramMetrics(n int, bounds int) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr(attributes.AttributeDatadogHostname, testHostname)
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.duration")
+	met.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	dps := met.Histogram().DataPoints()
+	dps.EnsureCapacity(n)
+
+	explicitBounds := make([]float64, bounds)
+	bucketCounts := make([]uint64, bounds+1)
+	for i := range explicitBounds {
+		explicitBounds[i] = float64(5 * (i + 1))
+	}
+	var count uint64
+	for i := range bucketCounts {
+		bucketCounts[i] = uint64(i + 1)
+		count += bucketCounts[i]
+	}
+	for i := 0; i < n; i++ {
+		dp := dps.AppendEmpty()
+		dp.Attributes().PutStr("instance", fmt.Sprintf("instance-%d", i))
+		dp.SetTimestamp(seconds(0))
+		dp.ExplicitBounds().FromRaw(explicitBounds)
+		dp.BucketCounts().FromRaw(bucketCounts)
+		dp.SetCount(count)
+		dp.SetSum(float64(count) * 10)
+	}
+	return md
+}
+
+// benchmarkHistogramMode maps 100 histogram data points with 50 bucket boundaries in the given histogram mode.
+func benchmarkHistogramMode(b *testing.B, mode HistogramMode) {
+	metrics := createBenchmarkDeltaHistogramMetrics(100, 50)
+	tr := newBenchmarkTranslator(b, zap.NewNop(), WithHistogramMode(mode))
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		consumer := &mockFullConsumer{}
+		_, err := tr.MapMetrics(ctx, metrics, consumer)
+		assert.NoError(b, err)
+	}
+}
+
+// BenchmarkHistogramModeCounters and BenchmarkHistogramModeDistributions compare the cost of the
+// two histogram modes: one count per bucket, or one sketch per data point.
+func BenchmarkHistogramModeCounters(b *testing.B) {
+	benchmarkHistogramMode(b, HistogramModeCounters)
+}
+
+func BenchmarkHistogramModeDistributions(b *testing.B) {
+	benchmarkHistogramMode(b, HistogramModeDistributions)
+}
+
+// BenchmarkMapMetrics maps each of the OTLP test fixtures.
+func BenchmarkMapMetrics(b *testing.B) {
+	files, err := filepath.Glob("testdata/otlpdata/*/*.json")
+	require.NoError(b, err)
+
+	for _, file := range files {
+		otlpbytes, err := os.ReadFile(file)
+		require.NoError(b, err)
+		var unmarshaler pmetric.JSONUnmarshaler
+		metrics, err := unmarshaler.UnmarshalMetrics(otlpbytes)
+		require.NoError(b, err)
+
+		b.Run(strings.TrimPrefix(file, "testdata/otlpdata/"), func(b *testing.B) {
+			b.ReportAllocs()
+			benchmarkMapMetrics(metrics, b)
+		})
+	}
+}
+
+// BenchmarkMapMetricsLargePayload maps a payload of 1000 cumulative metrics with 100 data points each,
+// reusing the same translator so that every data point goes through the delta cache.
+func BenchmarkMapMetricsLargePayload(b *testing.B) {
+	metrics := createBenchmarkCumulativeSumMetrics(1000, 100)
+	tr := newBenchmarkTranslator(b, zap.NewNop())
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		consumer := &mockFullConsumer{}
+		_, err := tr.MapMetrics(ctx, metrics, consumer)
+		assert.NoError(b, err)
+	}
+}
+
 func BenchmarkMapDeltaExponentialHistogramMetrics1_5(b *testing.B) {
 	metrics := createBenchmarkDeltaExponentialHistogramMetrics(1, 5, map[string]string{
 		"attribute_tag": "attribute_value",
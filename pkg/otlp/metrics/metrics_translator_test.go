@@ -16,7 +16,11 @@ package metrics
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -25,8 +29,11 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes"
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes/source"
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile/summary"
@@ -358,6 +365,40 @@ func TestMapIntMonotonicWithReboot(t *testing.T) {
 	)
 }
 
+func TestMapIntMonotonicWithStartTimeChange(t *testing.T) {
+	// The process restarts between the second and third points and its counter grows past the
+	// previous value: only the start timestamp change reveals the reset.
+	points := []struct {
+		startTs int
+		ts      int
+		value   int64
+	}{
+		{startTs: 1, ts: 2, value: 10},
+		{startTs: 1, ts: 3, value: 15},
+		{startTs: 4, ts: 5, value: 40},
+		{startTs: 4, ts: 6, value: 45},
+	}
+	slice := pmetric.NewNumberDataPointSlice()
+	for _, p := range points {
+		point := slice.AppendEmpty()
+		point.SetStartTimestamp(seconds(p.startTs))
+		point.SetTimestamp(seconds(p.ts))
+		point.SetIntValue(p.value)
+	}
+
+	ctx := context.Background()
+	tr := newTranslator(t, zap.NewNop())
+	consumer := &mockTimeSeriesConsumer{}
+	tr.mapNumberMonotonicMetrics(ctx, consumer, exampleDims, slice)
+	assert.ElementsMatch(t,
+		consumer.metrics,
+		[]metric{
+			newCount(exampleDims, uint64(seconds(3)), 5),
+			newCount(exampleDims, uint64(seconds(6)), 5),
+		},
+	)
+}
+
 func TestMapIntMonotonicReportFirstValue(t *testing.T) {
 	ctx := context.Background()
 	tr := newTranslator(t, zap.NewNop())
@@ -404,6 +445,357 @@ func TestMapIntMonotonicReportDiffForFirstValue(t *testing.T) {
 	assert.Empty(t, rmt.Languages)
 }
 
+func TestMapIntMonotonicNegativeDeltaHandling(t *testing.T) {
+	values := []int64{0, 30, 0, 20}
+	slice := pmetric.NewNumberDataPointSlice()
+	slice.EnsureCapacity(len(values))
+	for i, val := range values {
+		point := slice.AppendEmpty()
+		point.SetTimestamp(seconds(i))
+		point.SetIntValue(val)
+	}
+
+	tests := []struct {
+		mode     NegativeDeltaMode
+		expected []metric
+	}{
+		{
+			mode: NegativeDeltaModeDrop,
+			expected: []metric{
+				newCount(exampleDims, uint64(seconds(1)), 30),
+				newCount(exampleDims, uint64(seconds(3)), 20),
+			},
+		},
+		{
+			mode: NegativeDeltaModeReset,
+			expected: []metric{
+				newCount(exampleDims, uint64(seconds(1)), 30),
+				newCount(exampleDims, uint64(seconds(2)), 0),
+				newCount(exampleDims, uint64(seconds(3)), 20),
+			},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(string(testInstance.mode), func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), WithNegativeDeltaHandling(testInstance.mode))
+			require.NoError(t, err)
+			consumer := &mockTimeSeriesConsumer{}
+			tr.mapNumberMonotonicMetrics(context.Background(), consumer, exampleDims, slice)
+			assert.ElementsMatch(t, testInstance.expected, consumer.metrics)
+		})
+	}
+
+	_, err := NewTranslator(zap.NewNop(), WithNegativeDeltaHandling("ignore"))
+	assert.EqualError(t, err, `unknown negative delta mode: "ignore"`)
+}
+
+func TestMapHistogramNegativeDeltaHandling(t *testing.T) {
+	slice := pmetric.NewHistogramDataPointSlice()
+	for _, values := range []struct {
+		startTs, ts int
+		buckets     []uint64
+		sum         float64
+	}{
+		{startTs: 1, ts: 2, buckets: []uint64{2, 3}, sum: 10},
+		{startTs: 1, ts: 3, buckets: []uint64{3, 5}, sum: 16},
+		// The histogram was reset.
+		{startTs: 4, ts: 5, buckets: []uint64{1, 0}, sum: 2},
+	} {
+		point := slice.AppendEmpty()
+		point.SetStartTimestamp(seconds(values.startTs))
+		point.SetTimestamp(seconds(values.ts))
+		point.ExplicitBounds().FromRaw([]float64{0})
+		point.BucketCounts().FromRaw(values.buckets)
+		point.SetCount(values.buckets[0] + values.buckets[1])
+		point.SetSum(values.sum)
+	}
+
+	countDims := exampleDims.WithSuffix("count")
+	sumDims := exampleDims.WithSuffix("sum")
+	lowerBucketDims := exampleDims.WithSuffix("bucket").AddTags("lower_bound:-inf", "upper_bound:0")
+	upperBucketDims := exampleDims.WithSuffix("bucket").AddTags("lower_bound:0", "upper_bound:inf")
+	afterIncrease := []metric{
+		newCount(countDims, uint64(seconds(3)), 3),
+		newCount(sumDims, uint64(seconds(3)), 6),
+		newCount(lowerBucketDims, uint64(seconds(3)), 1),
+		newCount(upperBucketDims, uint64(seconds(3)), 2),
+	}
+	tests := []struct {
+		mode     NegativeDeltaMode
+		expected []metric
+	}{
+		{
+			mode:     NegativeDeltaModeDrop,
+			expected: afterIncrease,
+		},
+		{
+			mode: NegativeDeltaModeReset,
+			expected: append([]metric{
+				newCount(countDims, uint64(seconds(5)), 0),
+				newCount(sumDims, uint64(seconds(5)), 0),
+				newCount(lowerBucketDims, uint64(seconds(5)), 0),
+				newCount(upperBucketDims, uint64(seconds(5)), 0),
+			}, afterIncrease...),
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(string(testInstance.mode), func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(),
+				WithNegativeDeltaHandling(testInstance.mode),
+				WithHistogramMode(HistogramModeCounters),
+				WithHistogramCountSum(),
+			)
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			tr.mapHistogramMetrics(context.Background(), consumer, exampleDims, slice, false)
+			assert.ElementsMatch(t, testInstance.expected, consumer.metrics)
+		})
+	}
+}
+
+func TestMapSummaryNegativeDeltaHandling(t *testing.T) {
+	slice := pmetric.NewSummaryDataPointSlice()
+	for _, values := range []struct {
+		startTs, ts int
+		count       uint64
+		sum         float64
+	}{
+		{startTs: 1, ts: 2, count: 5, sum: 10},
+		{startTs: 1, ts: 3, count: 8, sum: 16},
+		// The summary was reset.
+		{startTs: 4, ts: 5, count: 1, sum: 2},
+	} {
+		point := slice.AppendEmpty()
+		point.SetStartTimestamp(seconds(values.startTs))
+		point.SetTimestamp(seconds(values.ts))
+		point.SetCount(values.count)
+		point.SetSum(values.sum)
+	}
+
+	countDims := exampleDims.WithSuffix("count")
+	sumDims := exampleDims.WithSuffix("sum")
+	afterIncrease := []metric{
+		newCount(countDims, uint64(seconds(3)), 3),
+		newCount(sumDims, uint64(seconds(3)), 6),
+	}
+	tests := []struct {
+		mode     NegativeDeltaMode
+		expected []metric
+	}{
+		{
+			mode:     NegativeDeltaModeDrop,
+			expected: afterIncrease,
+		},
+		{
+			mode: NegativeDeltaModeReset,
+			expected: append([]metric{
+				newCount(countDims, uint64(seconds(5)), 0),
+				newCount(sumDims, uint64(seconds(5)), 0),
+			}, afterIncrease...),
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(string(testInstance.mode), func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), WithNegativeDeltaHandling(testInstance.mode))
+			require.NoError(t, err)
+			consumer := &mockTimeSeriesConsumer{}
+			tr.mapSummaryMetrics(context.Background(), consumer, exampleDims, slice)
+			assert.ElementsMatch(t, testInstance.expected, consumer.metrics)
+		})
+	}
+}
+
+func TestMapIntMonotonicInvalidCumulativeHandling(t *testing.T) {
+	// The value decreases at timestamp 4 although the start timestamp is unchanged.
+	values := []int64{10, 15, 12, 20}
+	slice := pmetric.NewNumberDataPointSlice()
+	for i, val := range values {
+		point := slice.AppendEmpty()
+		point.SetStartTimestamp(seconds(1))
+		point.SetTimestamp(seconds(i + 2))
+		point.SetIntValue(val)
+	}
+
+	tests := []struct {
+		mode          InvalidCumulativeMode
+		expected      []metric
+		expectedStats TranslatorStats
+	}{
+		{
+			mode: InvalidCumulativeModeWarn,
+			expected: []metric{
+				newCount(exampleDims, uint64(seconds(3)), 5),
+				newCount(exampleDims, uint64(seconds(5)), 8),
+			},
+			expectedStats: TranslatorStats{InvalidCumulativeValues: 1, NegativeDeltasReset: 1},
+		},
+		{
+			mode: InvalidCumulativeModeWarnAndDrop,
+			expected: []metric{
+				newCount(exampleDims, uint64(seconds(3)), 5),
+				newCount(exampleDims, uint64(seconds(5)), 5),
+			},
+			expectedStats: TranslatorStats{InvalidCumulativeValues: 1},
+		},
+		{
+			mode: InvalidCumulativeModeWarnAndReset,
+			expected: []metric{
+				newCount(exampleDims, uint64(seconds(3)), 5),
+				newCount(exampleDims, uint64(seconds(4)), 0),
+				newCount(exampleDims, uint64(seconds(5)), 8),
+			},
+			expectedStats: TranslatorStats{InvalidCumulativeValues: 1, NegativeDeltasReset: 1},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(string(testInstance.mode), func(t *testing.T) {
+			core, observed := observer.New(zapcore.DebugLevel)
+			tr, err := NewTranslator(zap.New(core), WithInvalidCumulativeHandling(testInstance.mode))
+			require.NoError(t, err)
+			consumer := &mockTimeSeriesConsumer{}
+			var stats TranslatorStats
+			tr.mapNumberMonotonicMetrics(contextWithStats(context.Background(), &stats), consumer, exampleDims, slice)
+			assert.ElementsMatch(t, testInstance.expected, consumer.metrics)
+			assert.Equal(t, testInstance.expectedStats, stats)
+
+			logs := observed.FilterMessage("Cumulative value decreased without a reset").AllUntimed()
+			require.Len(t, logs, 1)
+			assert.Equal(t, zapcore.WarnLevel, logs[0].Level)
+			assert.Equal(t, map[string]interface{}{
+				metricName:                exampleDims.name,
+				"previous value":          15.0,
+				"value":                   12.0,
+				"invalid cumulative mode": string(testInstance.mode),
+				"negative delta mode":     string(NegativeDeltaModeDrop),
+				"component":               "metrics translator",
+			}, logs[0].ContextMap())
+		})
+	}
+
+	_, err := NewTranslator(zap.NewNop(), WithInvalidCumulativeHandling("ignore"))
+	assert.EqualError(t, err, `unknown invalid cumulative mode: "ignore"`)
+}
+
+func TestTranslatorReset(t *testing.T) {
+	ctx := context.Background()
+	tr, err := NewTranslator(zap.NewNop())
+	require.NoError(t, err)
+	_, err = tr.MapMetrics(ctx, createTestIntCumulativeMonotonicMetrics(false), &mockFullConsumer{})
+	require.NoError(t, err)
+
+	// The timeseries has an entry for its value and one for its start timestamp, but is counted once.
+	assert.Equal(t, 1, tr.Reset())
+	assert.Equal(t, 0, tr.Reset())
+
+	// After a reset, the first point is handled as the first point of the timeseries again.
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(ctx, createTestIntCumulativeMonotonicMetrics(false), consumer)
+	require.NoError(t, err)
+	assert.Len(t, consumer.metrics, 2)
+
+	tr, err = NewTranslator(zap.NewNop(), WithDeltaStore(newMapDeltaStore()))
+	require.NoError(t, err)
+	_, err = tr.MapMetrics(ctx, createTestIntCumulativeMonotonicMetrics(false), &mockFullConsumer{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, tr.Reset())
+}
+
+func TestMapMetricsScopeVersionChange(t *testing.T) {
+	newPayload := func(scopeVersion string, ts int, val int64) pmetric.Metrics {
+		md := pmetric.NewMetrics()
+		sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+		sm.Scope().SetName("checkout")
+		sm.Scope().SetVersion(scopeVersion)
+		met := sm.Metrics().AppendEmpty()
+		met.SetName("requests")
+		met.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		met.Sum().SetIsMonotonic(true)
+		dp := met.Sum().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(seconds(0))
+		dp.SetTimestamp(seconds(ts))
+		dp.SetIntValue(val)
+		return md
+	}
+
+	tr, err := NewTranslator(zap.NewNop())
+	require.NoError(t, err)
+	values := func(md pmetric.Metrics) []float64 {
+		consumer := &mockFullConsumer{}
+		_, err := tr.MapMetrics(context.Background(), md, consumer)
+		require.NoError(t, err)
+		var values []float64
+		for _, m := range consumer.metrics {
+			values = append(values, m.value)
+		}
+		return values
+	}
+
+	assert.Empty(t, values(newPayload("1.0.0", 1, 10)))
+	assert.Equal(t, []float64{5}, values(newPayload("1.0.0", 2, 15)))
+	// A new scope version starts a new timeseries: its first point is not diffed against the previous version.
+	assert.Empty(t, values(newPayload("2.0.0", 3, 20)))
+	assert.Equal(t, []float64{2}, values(newPayload("2.0.0", 4, 22)))
+}
+
+func TestTranslatorResetConcurrent(t *testing.T) {
+	tr, err := NewTranslator(zap.NewNop(), WithDeltaCacheMaxSize(10))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, err := tr.MapMetrics(context.Background(), createTestIntCumulativeMonotonicMetrics(false), &mockFullConsumer{})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		tr.Reset()
+	}
+	wg.Wait()
+
+	tr.Reset()
+	store := tr.prevPts.store.(*inMemoryDeltaStore)
+	assert.Zero(t, store.cache.ItemCount())
+	assert.Zero(t, store.recent.Len())
+}
+
+func TestTranslatorResetCountConcurrent(t *testing.T) {
+	tr, err := NewTranslator(zap.NewNop())
+	require.NoError(t, err)
+
+	const writers, series = 4, 500
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < series; j++ {
+				dims := &Dimensions{name: fmt.Sprintf("requests.%d.%d", i, j)}
+				tr.prevPts.MonotonicDiff(dims, 1, 2, 10)
+				// Running totals are cleared, but not counted as timeseries.
+				tr.prevPts.Accumulate(dims, 1, 2, 10)
+			}
+		}(i)
+	}
+	removed := 0
+	for i := 0; i < 50; i++ {
+		removed += tr.Reset()
+	}
+	wg.Wait()
+
+	// Every timeseries is counted by exactly one reset.
+	removed += tr.Reset()
+	assert.Equal(t, writers*series, removed)
+}
+
 func TestMapRuntimeMetricsHasMapping(t *testing.T) {
 	ctx := context.Background()
 	tr := newTranslator(t, zap.NewNop())
@@ -960,6 +1352,1203 @@ func TestFormatFloat(t *testing.T) {
 	}
 }
 
+func TestMapMetricsNamePrefixSuffix(t *testing.T) {
+	md := createTestHistogramMetric("http.server.duration")
+	gauge := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().AppendEmpty()
+	gauge.SetName("system.load")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1.5)
+
+	tr := newTranslator(t, zap.NewNop())
+	tr.cfg.MetricNamePrefix = "myteam."
+	tr.cfg.MetricNameSuffix = ".otel"
+	consumer := &mockFullConsumer{}
+	_, err := tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	var names []string
+	for _, m := range consumer.metrics {
+		names = append(names, m.name)
+	}
+	for _, s := range consumer.sketches {
+		names = append(names, s.name)
+	}
+	assert.ElementsMatch(t, []string{
+		"myteam.http.server.duration.otel.count",
+		"myteam.http.server.duration.otel.sum",
+		"myteam.http.server.duration.otel.min",
+		"myteam.http.server.duration.otel.max",
+		"myteam.http.server.duration.otel",
+		"myteam.system.load.otel",
+	}, names)
+}
+
+func TestMapMetricsOriginID(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    map[string]interface{}
+		originID string
+	}{
+		{
+			name: "container ID and pod UID",
+			attrs: map[string]interface{}{
+				"container.id": "container_id_goes_here",
+				"k8s.pod.uid":  "k8s_pod_uid_goes_here",
+			},
+			originID: "container_id://container_id_goes_here",
+		},
+		{
+			name: "only container ID",
+			attrs: map[string]interface{}{
+				"container.id": "container_id_goes_here",
+			},
+			originID: "container_id://container_id_goes_here",
+		},
+		{
+			name: "only pod UID",
+			attrs: map[string]interface{}{
+				"k8s.pod.uid": "k8s_pod_uid_goes_here",
+			},
+			originID: "kubernetes_pod_uid://k8s_pod_uid_goes_here",
+		},
+		{
+			name:     "none",
+			attrs:    map[string]interface{}{},
+			originID: "",
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			md := createTestHistogramMetric("http.server.duration")
+			require.NoError(t, md.ResourceMetrics().At(0).Resource().Attributes().FromRaw(testInstance.attrs))
+
+			tr := newTranslator(t, zap.NewNop())
+			var consumer testConsumer
+			_, err := tr.MapMetrics(context.Background(), md, &consumer)
+			require.NoError(t, err)
+
+			require.NotEmpty(t, consumer.testMetrics.TimeSeries)
+			for _, ts := range consumer.testMetrics.TimeSeries {
+				assert.Equal(t, testInstance.originID, ts.OriginID)
+			}
+			require.NotEmpty(t, consumer.testMetrics.Sketches)
+			for _, sk := range consumer.testMetrics.Sketches {
+				assert.Equal(t, testInstance.originID, sk.OriginID)
+			}
+		})
+	}
+}
+
+func TestMapMetricsAttributeDenyList(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	rm.Resource().Attributes().PutStr("deployment.environment", "prod")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("http.method", "GET")
+	dp.Attributes().PutStr("user.email", "user@example.com")
+	dp.Attributes().PutStr("http.request.header.authorization", "Bearer token")
+	dp.Attributes().PutStr("http.request.header.cookie", "session=1")
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithAttributeDenyList("user.email", "http.request.header.*"),
+		WithAttributeDenyList("deployment.environment"),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	require.Len(t, consumer.metrics, 1)
+	assert.ElementsMatch(t, []string{"service:checkout", "http.method:GET"}, consumer.metrics[0].tags)
+	// The original attributes are left untouched.
+	assert.Equal(t, 4, dp.Attributes().Len())
+	assert.Equal(t, 2, rm.Resource().Attributes().Len())
+}
+
+func TestMapMetricsAttributeValueAllowList(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	rm.Resource().Attributes().PutStr("deployment.environment", "staging-42")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.requests")
+	met.SetEmptyGauge()
+	for _, route := range []string{"/users", "/users/1234"} {
+		dp := met.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(1)
+		dp.Attributes().PutStr("http.route", route)
+		dp.Attributes().PutStr("http.method", "GET")
+	}
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithAttributeValueAllowList(map[string][]string{"http.route": {"/users"}}),
+		WithAttributeValueAllowList(map[string][]string{"http.route": {"/orders"}, "deployment.environment": {"prod", "staging"}}),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	require.Len(t, consumer.metrics, 2)
+	assert.ElementsMatch(t, []string{"service:checkout", "env:other", "http.route:/users", "http.method:GET"}, consumer.metrics[0].tags)
+	assert.ElementsMatch(t, []string{"service:checkout", "env:other", "http.route:other", "http.method:GET"}, consumer.metrics[1].tags)
+	// The original attributes are left untouched.
+	route, _ := met.Gauge().DataPoints().At(1).Attributes().Get("http.route")
+	assert.Equal(t, "/users/1234", route.Str())
+
+	_, err = NewTranslator(zap.NewNop(), WithAttributeValueAllowList(map[string][]string{"": {"value"}}))
+	assert.EqualError(t, err, "attribute value allow list key must not be empty")
+}
+
+func TestMapMetricsDuplicateTags(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("service", "checkout")
+	dp.Attributes().PutStr("http.method", "GET")
+
+	tr := newTranslator(t, zap.NewNop())
+	consumer := &mockFullConsumer{}
+	_, err := tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	require.Len(t, consumer.metrics, 1)
+	assert.ElementsMatch(t, []string{"service:checkout", "http.method:GET"}, consumer.metrics[0].tags)
+}
+
+func TestNewTranslatorWithMappingTable(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	rm.Resource().Attributes().PutStr("deployment.environment", "prod")
+	rm.Resource().Attributes().PutStr("acme.team", "payments")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+
+	table, err := attributes.LoadAttributeMappingTable(strings.NewReader(`{
+		"mappings": [
+			{"otlp_key": "acme.team", "datadog_tag": "team"},
+			{"otlp_key": "deployment.environment", "datadog_tag": "environment"}
+		]
+	}`))
+	require.NoError(t, err)
+	tr, err := NewTranslatorWithMappingTable(zap.NewNop(), table)
+	require.NoError(t, err)
+	assert.Equal(t, table, tr.Config().AttributeMappingTable)
+
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+	require.Len(t, consumer.metrics, 1)
+	assert.ElementsMatch(t, []string{"service:checkout", "environment:prod", "team:payments"}, consumer.metrics[0].tags)
+
+	_, err = NewTranslatorWithMappingTable(zap.NewNop(), nil)
+	assert.EqualError(t, err, "attribute mapping table must not be nil")
+}
+
+type mockIntervalConsumer struct {
+	mockFullConsumer
+	intervals map[string][]int64
+}
+
+func (c *mockIntervalConsumer) ConsumeTimeSeriesWithInterval(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	interval int64,
+	value float64,
+) {
+	c.intervals[dimensions.Name()] = append(c.intervals[dimensions.Name()], interval)
+	c.ConsumeTimeSeries(ctx, dimensions, typ, timestamp, value)
+}
+
+func TestMapMetricsCountInterval(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+
+	delta := metricsArray.AppendEmpty()
+	delta.SetName("delta.sum")
+	delta.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	delta.Sum().SetIsMonotonic(true)
+	deltaPoint := delta.Sum().DataPoints().AppendEmpty()
+	deltaPoint.SetStartTimestamp(seconds(10))
+	deltaPoint.SetTimestamp(seconds(25))
+	deltaPoint.SetIntValue(3)
+	// No start timestamp.
+	deltaPoint = delta.Sum().DataPoints().AppendEmpty()
+	deltaPoint.SetTimestamp(seconds(40))
+	deltaPoint.SetIntValue(4)
+
+	cumulative := metricsArray.AppendEmpty()
+	cumulative.SetName("cumulative.sum")
+	cumulative.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	cumulative.Sum().SetIsMonotonic(true)
+	for i, ts := range []int{10, 20, 35} {
+		dp := cumulative.Sum().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(seconds(1))
+		dp.SetTimestamp(seconds(ts))
+		dp.SetIntValue(int64(i))
+	}
+
+	gauge := metricsArray.AppendEmpty()
+	gauge.SetName("gauge")
+	gaugePoint := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	gaugePoint.SetTimestamp(seconds(10))
+	gaugePoint.SetIntValue(1)
+
+	tests := []struct {
+		name    string
+		options []TranslatorOption
+	}{
+		{name: "no options"},
+		{
+			name: "translation hook",
+			options: []TranslatorOption{
+				WithTranslationHook(func(dims *Dimensions) *Dimensions { return dims }),
+			},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), testInstance.options...)
+			require.NoError(t, err)
+			consumer := &mockIntervalConsumer{intervals: make(map[string][]int64)}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+
+			assert.Len(t, consumer.metrics, 5)
+			assert.Equal(t, map[string][]int64{
+				// Delta sums are aggregated since their start timestamp, if any.
+				"delta.sum": {15, 0},
+				// Cumulative sums are converted to deltas since the previous point.
+				"cumulative.sum": {10, 15},
+			}, consumer.intervals)
+		})
+	}
+}
+
+func TestMapMetricsResourceAttributePrefix(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("deployment.environment", "prod")
+	rm.Resource().Attributes().PutStr("k8s.namespace.name", "default")
+	rm.Resource().Attributes().PutStr("k8s.pod.labels.app", "checkout")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	// Same tag keys as the ones derived from the resource attributes.
+	dp.Attributes().PutStr("env", "staging")
+	dp.Attributes().PutStr("kube_namespace", "payments")
+	dp.Attributes().PutStr("app", "frontend")
+
+	tests := []struct {
+		name     string
+		prefix   string
+		expected []string
+	}{
+		{
+			name:     "no prefix",
+			expected: []string{"env:prod", "kube_namespace:default", "app:checkout", "env:staging", "kube_namespace:payments", "app:frontend"},
+		},
+		{
+			name:   "prefix",
+			prefix: "resource.",
+			expected: []string{
+				"resource.env:prod", "resource.kube_namespace:default", "resource.app:checkout",
+				"env:staging", "kube_namespace:payments", "app:frontend",
+			},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(),
+				WithResourceAttributePrefix(testInstance.prefix),
+				WithKubernetesPodLabelsAsTags(map[string]string{"app": "app"}),
+			)
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+
+			require.Len(t, consumer.metrics, 1)
+			assert.ElementsMatch(t, testInstance.expected, consumer.metrics[0].tags)
+		})
+	}
+}
+
+func TestWithResourceAttributePrefixInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithResourceAttributePrefix("resource:"))
+	assert.EqualError(t, err, `resource attribute prefix must not contain ':': "resource:"`)
+}
+
+func TestMapMetricsResourceSchemaURL(t *testing.T) {
+	md := pmetric.NewMetrics()
+	for _, schemaURL := range []string{"https://opentelemetry.io/schemas/1.6.1", "https://opentelemetry.io/schemas/1.22.0"} {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.SetSchemaUrl(schemaURL)
+		rm.Resource().Attributes().PutEmptySlice("container.image.tags").FromRaw([]any{"1.2.3"})
+		met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		met.SetName("container.cpu.usage")
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(1)
+	}
+
+	tr := newTranslator(t, zap.NewNop())
+	consumer := &mockFullConsumer{}
+	_, err := tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	// container.image.tags is only mapped from semantic conventions v1.22.0 on.
+	require.Len(t, consumer.metrics, 2)
+	assert.Empty(t, consumer.metrics[0].tags)
+	assert.Equal(t, []string{"image_tag:1.2.3"}, consumer.metrics[1].tags)
+}
+
+type mockHostMetadataConsumer struct {
+	mockFullConsumer
+	hostMetadata map[string]attributes.HostMetadata
+}
+
+func (c *mockHostMetadataConsumer) ConsumeHostMetadata(host string, metadata attributes.HostMetadata) {
+	if c.hostMetadata == nil {
+		c.hostMetadata = make(map[string]attributes.HostMetadata)
+	}
+	c.hostMetadata[host] = metadata
+}
+
+func TestMapMetricsProcessMetadata(t *testing.T) {
+	md := pmetric.NewMetrics()
+	for _, host := range []string{"process-host", "other-host"} {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("host.name", host)
+		if host == "process-host" {
+			rm.Resource().Attributes().PutStr("process.executable.name", "otelcol")
+			rm.Resource().Attributes().PutInt("process.pid", 42)
+			rm.Resource().Attributes().PutStr("process.owner", "otel")
+		}
+		met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		met.SetName("otelcol.process.uptime")
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(1)
+	}
+
+	tr, err := NewTranslator(zap.NewNop())
+	require.NoError(t, err)
+	consumer := &mockHostMetadataConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+	assert.Empty(t, consumer.hostMetadata)
+
+	tr, err = NewTranslator(zap.NewNop(), WithProcessMetadata())
+	require.NoError(t, err)
+	consumer = &mockHostMetadataConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]attributes.HostMetadata{
+		"process-host": {Process: attributes.ProcessMetadata{ExecutableName: "otelcol", PID: 42, Owner: "otel"}},
+	}, consumer.hostMetadata)
+	assert.Len(t, consumer.metrics, 2)
+}
+
+func TestMapMetricsSDKMetadataAsTags(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	rm.Resource().Attributes().PutStr("telemetry.sdk.name", "opentelemetry")
+	rm.Resource().Attributes().PutStr("telemetry.sdk.language", "go")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+
+	tr, err := NewTranslator(zap.NewNop())
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+	require.Len(t, consumer.metrics, 1)
+	assert.Equal(t, []string{"service:checkout"}, consumer.metrics[0].tags)
+
+	tr, err = NewTranslator(zap.NewNop(), WithSDKMetadataAsTags())
+	require.NoError(t, err)
+	consumer = &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+	require.Len(t, consumer.metrics, 1)
+	assert.ElementsMatch(t, []string{"service:checkout", "otel.sdk.name:opentelemetry", "otel.sdk.language:go"}, consumer.metrics[0].tags)
+}
+
+func TestMapMetricsKubernetesPodLabelsAsTags(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("k8s.pod.name", "checkout-6d4cf56db6-xk8qv")
+	rm.Resource().Attributes().PutStr("k8s.container.name", "checkout")
+	rm.Resource().Attributes().PutStr("k8s.pod.labels.app", "checkout")
+	rm.Resource().Attributes().PutStr("k8s.pod.labels.version", "1.2.3")
+	rm.Resource().Attributes().PutStr("k8s.pod.labels.pod-template-hash", "6d4cf56db6")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("container.cpu.usage")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithKubernetesPodLabelsAsTags(map[string]string{"app": "kube_app"}),
+		WithKubernetesPodLabelsAsTags(map[string]string{"version": "version"}),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+	require.Len(t, consumer.metrics, 1)
+	assert.ElementsMatch(t, []string{
+		"pod_name:checkout-6d4cf56db6-xk8qv",
+		"kube_container_name:checkout",
+		"kube_app:checkout",
+		"version:1.2.3",
+	}, consumer.metrics[0].tags)
+
+	_, err = NewTranslator(zap.NewNop(), WithKubernetesPodLabelsAsTags(map[string]string{"app": ""}))
+	assert.EqualError(t, err, `invalid pod label mapping "app": ""`)
+}
+
+func TestMapMetricsTagValueTransform(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "Checkout")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("region", "AWS:us-east-1")
+	dp.Attributes().PutStr("http.method", "GET")
+
+	tests := []struct {
+		name      string
+		transform TagValueTransform
+		expected  []string
+	}{
+		{
+			name:      "lowercase",
+			transform: LowercaseTagValueTransform,
+			expected:  []string{"service:checkout", "region:aws:us-east-1", "http.method:get"},
+		},
+		{
+			name: "custom",
+			transform: func(key, value string) string {
+				if key == "region" {
+					return strings.ReplaceAll(value, "-", "_")
+				}
+				return value
+			},
+			expected: []string{"service:Checkout", "region:AWS:us_east_1", "http.method:GET"},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), WithTagValueTransform(testInstance.transform))
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+
+			require.Len(t, consumer.metrics, 1)
+			assert.ElementsMatch(t, testInstance.expected, consumer.metrics[0].tags)
+		})
+	}
+
+	_, err := NewTranslator(zap.NewNop(), WithTagValueTransform(nil))
+	assert.EqualError(t, err, "tag value transform must not be nil")
+}
+
+// cancellingConsumer cancels a context after consuming a given number of timeseries.
+type cancellingConsumer struct {
+	mockFullConsumer
+	cancel context.CancelFunc
+	limit  int
+}
+
+func (c *cancellingConsumer) ConsumeTimeSeries(ctx context.Context, dimensions *Dimensions, typ DataType, timestamp uint64, value float64) {
+	c.mockFullConsumer.ConsumeTimeSeries(ctx, dimensions, typ, timestamp, value)
+	if len(c.metrics) == c.limit {
+		c.cancel()
+	}
+}
+
+func TestMapMetricsContextCancellation(t *testing.T) {
+	md := pmetric.NewMetrics()
+	for i := 0; i < 3; i++ {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutInt("resource.index", int64(i))
+		met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		met.SetName("test.gauge")
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(float64(i))
+	}
+	tr := newTranslator(t, zap.NewNop())
+
+	t.Run("cancelled partway", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		consumer := &cancellingConsumer{cancel: cancel, limit: 2}
+		_, err := tr.MapMetrics(ctx, md, consumer)
+		assert.ErrorIs(t, err, context.Canceled)
+		// The resources mapped before the cancellation are kept.
+		require.Len(t, consumer.metrics, 2)
+		assert.Equal(t, 0.0, consumer.metrics[0].value)
+		assert.Equal(t, 1.0, consumer.metrics[1].value)
+	})
+
+	t.Run("cancelled before", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		consumer := &mockFullConsumer{}
+		_, err := tr.MapMetrics(ctx, md, consumer)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, consumer.metrics)
+	})
+}
+
+func TestMapMetricsHostTagAttributes(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	rm.Resource().Attributes().PutStr("deployment.environment", "prod")
+	rm.Resource().Attributes().PutStr("team", "payments")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.server.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("http.method", "GET")
+	dp.Attributes().PutStr("availability_zone", "us-east-1a")
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithHostTagAttributes("deployment.environment", "team"),
+		WithHostTagAttributes("availability_*"),
+	)
+	require.NoError(t, err)
+	var consumer testConsumer
+	_, err = tr.MapMetrics(context.Background(), md, &consumer)
+	require.NoError(t, err)
+
+	require.Len(t, consumer.testMetrics.TimeSeries, 1)
+	dims := consumer.testMetrics.TimeSeries[0].TestDimensions
+	assert.ElementsMatch(t, []string{"service:checkout", "http.method:GET"}, dims.Tags)
+	assert.ElementsMatch(t, []string{"env:prod", "team:payments", "availability_zone:us-east-1a"}, dims.HostTags)
+	// The original attributes are left untouched.
+	assert.Equal(t, 3, rm.Resource().Attributes().Len())
+	assert.Equal(t, 2, dp.Attributes().Len())
+}
+
+func TestSplitHostTagAttributesWithoutHostTagAttributes(t *testing.T) {
+	tr := newTranslator(t, zap.NewNop())
+	attrs := pcommon.NewMap()
+	attrs.PutStr("team", "payments")
+
+	// No map is allocated when no host tag attributes are set.
+	var hostAttrs, otherAttrs pcommon.Map
+	allocs := testing.AllocsPerRun(100, func() {
+		hostAttrs, otherAttrs = tr.splitHostTagAttributes(attrs)
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, 0, hostAttrs.Len())
+	assert.Equal(t, 1, otherAttrs.Len())
+}
+
+func TestWithHostTagAttributesInvalidPattern(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithHostTagAttributes("host.["))
+	assert.Error(t, err)
+}
+
+func TestWithAttributeDenyListInvalidPattern(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithAttributeDenyList("http.request.header.[a"))
+	assert.EqualError(t, err, `invalid pattern "http.request.header.[a": syntax error in pattern`)
+}
+
+func TestMapMetricsGaugeToDistribution(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"request.duration", "db.query.latency", "system.load"} {
+		met := metricsArray.AppendEmpty()
+		met.SetName(name)
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(2.5)
+	}
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithGaugeToDistribution("request.duration"),
+		WithGaugeToDistribution("*.latency"),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []metric{
+		newGauge(newDims("system.load"), uint64(seconds(1)), 2.5),
+	}, consumer.metrics)
+	expectedSummary := summary.Summary{Min: 2.5, Max: 2.5, Sum: 2.5, Avg: 2.5, Cnt: 1}
+	assert.ElementsMatch(t, []sketch{
+		newSketch(newDims("request.duration"), uint64(seconds(1)), expectedSummary),
+		newSketch(newDims("db.query.latency"), uint64(seconds(1)), expectedSummary),
+	}, consumer.sketches)
+}
+
+func TestWithGaugeToDistributionInvalidPattern(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithGaugeToDistribution("request.[duration"))
+	assert.EqualError(t, err, `invalid pattern "request.[duration": syntax error in pattern`)
+}
+
+func TestMapMetricsNumberModePerMetric(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"process.cpu.time", "process.cpu.seconds", "system.io"} {
+		met := metricsArray.AppendEmpty()
+		met.SetName(name)
+		sum := met.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		for i, val := range []float64{10, 15} {
+			dp := sum.DataPoints().AppendEmpty()
+			dp.SetTimestamp(seconds(i + 1))
+			dp.SetDoubleValue(val)
+		}
+	}
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithNumberMode(NumberModeRawValue),
+		WithNumberModePerMetric(map[string]NumberMode{"process.*": NumberModeCumulativeToDelta}),
+		WithNumberModePerMetric(map[string]NumberMode{"process.cpu.time": NumberModeRawValue}),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []metric{
+		newGauge(newDims("process.cpu.time"), uint64(seconds(1)), 10),
+		newGauge(newDims("process.cpu.time"), uint64(seconds(2)), 15),
+		newCount(newDims("process.cpu.seconds"), uint64(seconds(2)), 5),
+		newGauge(newDims("system.io"), uint64(seconds(1)), 10),
+		newGauge(newDims("system.io"), uint64(seconds(2)), 15),
+	}, consumer.metrics)
+}
+
+func TestMapMetricsStaleMode(t *testing.T) {
+	noRecordedValue := pmetric.DefaultDataPointFlags.WithNoRecordedValue(true)
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metricsArray.AppendEmpty()
+	gauge.SetName("gauge")
+	gaugePoint := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	gaugePoint.SetTimestamp(seconds(1))
+	gaugePoint.SetFlags(noRecordedValue)
+
+	cumulative := metricsArray.AppendEmpty()
+	cumulative.SetName("cumulative.sum")
+	cumulative.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	cumulative.Sum().SetIsMonotonic(true)
+	for i, val := range []int64{1, 0, 4} {
+		dp := cumulative.Sum().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(i + 1))
+		dp.SetIntValue(val)
+		if i == 1 {
+			dp.SetFlags(noRecordedValue)
+		}
+	}
+
+	hist := metricsArray.AppendEmpty()
+	hist.SetName("histogram")
+	hist.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	histPoint := hist.Histogram().DataPoints().AppendEmpty()
+	histPoint.SetTimestamp(seconds(1))
+	histPoint.SetFlags(noRecordedValue)
+
+	tests := []struct {
+		name     string
+		mode     StaleMode
+		expected []metric
+	}{
+		{
+			name: "drop",
+			mode: StaleModeDrop,
+			expected: []metric{
+				newCount(newDims("cumulative.sum"), uint64(seconds(3)), 3),
+			},
+		},
+		{
+			name: "emit NaN",
+			mode: StaleModeEmitNaN,
+			expected: []metric{
+				newGauge(newDims("gauge"), uint64(seconds(1)), math.NaN()),
+				newCount(newDims("cumulative.sum"), uint64(seconds(2)), math.NaN()),
+				// The delta is computed since the last recorded value.
+				newCount(newDims("cumulative.sum"), uint64(seconds(3)), 3),
+			},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			core, observed := observer.New(zapcore.DebugLevel)
+			tr, err := NewTranslator(zap.New(core), WithStaleMetricHandling(testInstance.mode))
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+
+			require.Len(t, consumer.metrics, len(testInstance.expected))
+			for i, expected := range testInstance.expected {
+				actual := consumer.metrics[i]
+				assert.Equal(t, expected.name, actual.name)
+				assert.Equal(t, expected.typ, actual.typ)
+				assert.Equal(t, expected.timestamp, actual.timestamp)
+				if math.IsNaN(expected.value) {
+					assert.True(t, math.IsNaN(actual.value), "expected NaN, got %v", actual.value)
+				} else {
+					assert.Equal(t, expected.value, actual.value)
+				}
+			}
+			assert.Empty(t, consumer.sketches)
+
+			skipped := 3
+			if testInstance.mode == StaleModeEmitNaN {
+				skipped = 1
+			}
+			assert.Equal(t, skipped, observed.FilterMessage(noRecordedValueMessage).Len())
+		})
+	}
+
+	_, err := NewTranslator(zap.NewNop(), WithStaleMetricHandling("keep"))
+	assert.EqualError(t, err, `unknown stale mode: "keep"`)
+}
+
+func TestWithNumberModePerMetricInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithNumberModePerMetric(map[string]NumberMode{"process.[cpu": NumberModeRawValue}))
+	assert.EqualError(t, err, `invalid pattern "process.[cpu": syntax error in pattern`)
+
+	_, err = NewTranslator(zap.NewNop(), WithNumberModePerMetric(map[string]NumberMode{"process.*": "sum"}))
+	assert.EqualError(t, err, `unknown number mode for "process.*": "sum"`)
+}
+
+func TestMapMetricsMaxTagCount(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("system.load")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("request.id", "f3a9")
+	dp.Attributes().PutStr("az", "us-east-1a")
+
+	core, observed := observer.New(zapcore.WarnLevel)
+	tr, err := NewTranslator(zap.New(core), WithMaxTagCount(2))
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	require.Len(t, consumer.metrics, 1)
+	assert.Equal(t, []string{"az:us-east-1a", "request.id:f3a9"}, consumer.metrics[0].tags)
+	assert.Equal(t, 1, observed.FilterMessage("Too many tags, truncating").Len())
+
+	_, err = NewTranslator(zap.NewNop(), WithMaxTagCount(0))
+	assert.EqualError(t, err, "maximum tag count must be positive: 0")
+}
+
+func TestMapMetricsStats(t *testing.T) {
+	md := pmetric.NewMetrics()
+	met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http.requests")
+	met.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	met.Sum().SetIsMonotonic(true)
+	for i, val := range []int64{10, 5} {
+		dp := met.Sum().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(seconds(1))
+		dp.SetTimestamp(seconds(i + 2))
+		dp.SetIntValue(val)
+		dp.Attributes().PutStr("request.id", "f3a9")
+		dp.Attributes().PutStr("az", "us-east-1a")
+		dp.Attributes().PutStr("http.method", "GET")
+	}
+	excluded := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().AppendEmpty()
+	excluded.SetName("debug.requests")
+	excluded.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+
+	filter, err := NewDenyListFilter("debug.*")
+	require.NoError(t, err)
+	tr, err := NewTranslator(zap.NewNop(), WithMaxTagCount(2), WithMetricFilter(filter))
+	require.NoError(t, err)
+	metadata, err := tr.MapMetrics(context.Background(), md, &mockFullConsumer{})
+	require.NoError(t, err)
+	// The value decreases with an unchanged start timestamp: it is both invalid and handled as a reset.
+	assert.Equal(t, TranslatorStats{DroppedMetrics: 1, UnsupportedMetricTypes: 1, NegativeDeltasReset: 1, InvalidCumulativeValues: 1, TagsTruncated: 2}, metadata.Stats)
+
+	// Evictions are reported once, by the next call.
+	cache := tr.prevPts.store.(*inMemoryDeltaStore).cache
+	for key := range cache.Items() {
+		cache.Delete(key)
+	}
+	metadata, err = tr.MapMetrics(context.Background(), pmetric.NewMetrics(), &mockFullConsumer{})
+	require.NoError(t, err)
+	assert.Equal(t, TranslatorStats{CacheEvictions: 1}, metadata.Stats)
+	metadata, err = tr.MapMetrics(context.Background(), pmetric.NewMetrics(), &mockFullConsumer{})
+	require.NoError(t, err)
+	assert.Equal(t, TranslatorStats{}, metadata.Stats)
+}
+
+func TestMapMetricsConstantTags(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("deployment.environment", "production")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("system.load")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("request.id", "f3a9")
+	dp.Attributes().PutStr("az", "us-east-1a")
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithMaxTagCount(2),
+		WithConstantTags("Region:US-East-1"),
+		WithConstantTags("env:production", "team:payments"),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	// Constant tags are added after truncation, and only once.
+	require.Len(t, consumer.metrics, 1)
+	assert.Equal(t, []string{"az:us-east-1a", "env:production", "region:us-east-1", "team:payments"}, consumer.metrics[0].tags)
+}
+
+func TestMapMetricsTagKeyRenameRules(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("k8s.namespace.name", "payments")
+	met := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("system.load")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("source", "a")
+	dp.Attributes().PutStr("destination", "b")
+	dp.Attributes().PutStr("http.method", "GET")
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithConstantTags("team:checkout"),
+		WithTagKeyRenameRules(map[string]string{"kube_namespace": "namespace", "team": "owner"}),
+		// Circular rules swap the keys rather than looping.
+		WithTagKeyRenameRules(map[string]string{"source": "destination", "destination": "source"}),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	require.Len(t, consumer.metrics, 1)
+	assert.ElementsMatch(t, []string{
+		"namespace:payments",
+		"destination:a",
+		"source:b",
+		"http.method:GET",
+		"owner:checkout",
+	}, consumer.metrics[0].tags)
+
+	_, err = NewTranslator(zap.NewNop(), WithTagKeyRenameRules(map[string]string{"kube_namespace": ""}))
+	assert.EqualError(t, err, `invalid tag key rename rule "kube_namespace": ""`)
+}
+
+func TestMapMetricsValueRange(t *testing.T) {
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetName("gauge")
+	gaugeDps := gauge.SetEmptyGauge().DataPoints()
+	for i, v := range []float64{-5, 50, 500} {
+		dp := gaugeDps.AppendEmpty()
+		dp.SetTimestamp(seconds(i))
+		dp.SetDoubleValue(v)
+	}
+	count := ms.AppendEmpty()
+	count.SetName("count")
+	count.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	count.Sum().SetIsMonotonic(true)
+	dp := count.Sum().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(0))
+	dp.SetIntValue(1000)
+	hist := ms.AppendEmpty()
+	hist.SetName("hist")
+	hist.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	hdp := hist.Histogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(seconds(0))
+	hdp.ExplicitBounds().FromRaw([]float64{0})
+	hdp.BucketCounts().FromRaw([]uint64{5, 200})
+
+	lowerBucket := newDims("hist.bucket").AddTags("lower_bound:-inf", "upper_bound:0")
+	upperBucket := newDims("hist.bucket").AddTags("lower_bound:0", "upper_bound:inf")
+	tests := []struct {
+		name     string
+		option   TranslatorOption
+		expected []metric
+		warnings int
+	}{
+		{
+			name:   "clamp",
+			option: WithValueClamp(0, 100),
+			expected: []metric{
+				newGauge(newDims("gauge"), uint64(seconds(0)), 0),
+				newGauge(newDims("gauge"), uint64(seconds(1)), 50),
+				newGauge(newDims("gauge"), uint64(seconds(2)), 100),
+				newCount(newDims("count"), uint64(seconds(0)), 100),
+				newCount(lowerBucket, uint64(seconds(0)), 5),
+				newCount(upperBucket, uint64(seconds(0)), 100),
+			},
+			warnings: 4,
+		},
+		{
+			name:   "drop",
+			option: WithValueDropOutsideRange(0, 100),
+			expected: []metric{
+				newGauge(newDims("gauge"), uint64(seconds(1)), 50),
+				newCount(lowerBucket, uint64(seconds(0)), 5),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, observed := observer.New(zapcore.DebugLevel)
+			tr, err := NewTranslator(zap.New(core), WithHistogramMode(HistogramModeCounters), tt.option)
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expected, consumer.metrics)
+			assert.Equal(t, tt.warnings, observed.FilterMessage("Clamping value outside of the configured range").Len())
+		})
+	}
+}
+
+func TestMapMetricsTimestampLag(t *testing.T) {
+	now := pcommon.NewTimestampFromTime(time.Now())
+	old := pcommon.NewTimestampFromTime(time.Now().Add(-2 * time.Hour))
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetName("gauge")
+	gaugeDps := gauge.SetEmptyGauge().DataPoints()
+	for _, ts := range []pcommon.Timestamp{old, now} {
+		dp := gaugeDps.AppendEmpty()
+		dp.SetTimestamp(ts)
+		dp.SetDoubleValue(1)
+	}
+	count := ms.AppendEmpty()
+	count.SetName("count")
+	count.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	count.Sum().SetIsMonotonic(true)
+	dp := count.Sum().DataPoints().AppendEmpty()
+	dp.SetTimestamp(old)
+	dp.SetIntValue(10)
+	hist := ms.AppendEmpty()
+	hist.SetName("hist")
+	hist.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	hdp := hist.Histogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(old)
+	hdp.SetCount(1)
+	hdp.BucketCounts().FromRaw([]uint64{1})
+
+	core, observed := observer.New(zapcore.DebugLevel)
+	tr, err := NewTranslator(zap.New(core), WithMetricTimestampLag(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, tr.Config().MetricTimestampLag)
+	consumer := &mockFullConsumer{}
+	metadata, err := tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	assert.Equal(t, []metric{newGauge(newDims("gauge"), uint64(now), 1)}, consumer.metrics)
+	assert.Empty(t, consumer.sketches)
+	assert.Equal(t, 3, metadata.Stats.DroppedStalePoints)
+	assert.Equal(t, 3, observed.FilterMessage("Dropping datapoint older than the maximum timestamp lag").Len())
+
+	// Without the option, old datapoints are translated.
+	tr, err = NewTranslator(zap.NewNop())
+	require.NoError(t, err)
+	consumer = &mockFullConsumer{}
+	metadata, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+	assert.Len(t, consumer.metrics, 3)
+	assert.Len(t, consumer.sketches, 1)
+	assert.Zero(t, metadata.Stats.DroppedStalePoints)
+
+	_, err = NewTranslator(zap.NewNop(), WithMetricTimestampLag(0))
+	assert.EqualError(t, err, "metric timestamp lag must be positive: 0s")
+}
+
+// createTestSumMetrics creates a sum with one point per value, the i-th point covering [startTs+i, startTs+i+1] seconds
+// for delta sums, and [startTs, startTs+i+1] seconds for cumulative sums.
+func createTestSumMetrics(temporality pmetric.AggregationTemporality, monotonic bool, startTs int, values ...float64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("requests")
+	met.SetEmptySum().SetAggregationTemporality(temporality)
+	met.Sum().SetIsMonotonic(monotonic)
+	for i, val := range values {
+		dp := met.Sum().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(seconds(startTs))
+		if temporality == pmetric.AggregationTemporalityDelta {
+			dp.SetStartTimestamp(seconds(startTs + i))
+		}
+		dp.SetTimestamp(seconds(startTs + i + 1))
+		dp.SetDoubleValue(val)
+	}
+	return md
+}
+
+func TestMapMetricsNormalizeSumTemporality(t *testing.T) {
+	ctx := context.Background()
+	dims := newDims("requests")
+	newNormalizingTranslator := func() *Translator {
+		tr, err := NewTranslator(zap.NewNop(), WithNormalizeSumTemporality(pmetric.AggregationTemporalityCumulative))
+		require.NoError(t, err)
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, tr.Config().NormalizeSumTemporality)
+		return tr
+	}
+
+	t.Run("delta monotonic", func(t *testing.T) {
+		// The deltas are accumulated and converted back to the original deltas, across payloads.
+		tr := newNormalizingTranslator()
+		consumer := &mockFullConsumer{}
+		_, err := tr.MapMetrics(ctx, createTestSumMetrics(pmetric.AggregationTemporalityDelta, true, 1, 3, 5), consumer)
+		require.NoError(t, err)
+		_, err = tr.MapMetrics(ctx, createTestSumMetrics(pmetric.AggregationTemporalityDelta, true, 3, 2), consumer)
+		require.NoError(t, err)
+		assert.Equal(t, []metric{
+			newCount(dims, uint64(seconds(2)), 3),
+			newCount(dims, uint64(seconds(3)), 5),
+			newCount(dims, uint64(seconds(4)), 2),
+		}, consumer.metrics)
+	})
+
+	t.Run("delta non-monotonic", func(t *testing.T) {
+		// UpDownCounters report their total, like cumulative ones.
+		tr := newNormalizingTranslator()
+		consumer := &mockFullConsumer{}
+		_, err := tr.MapMetrics(ctx, createTestSumMetrics(pmetric.AggregationTemporalityDelta, false, 1, 3, -1, 2), consumer)
+		require.NoError(t, err)
+		assert.Equal(t, []metric{
+			newGauge(dims, uint64(seconds(2)), 3),
+			newGauge(dims, uint64(seconds(3)), 2),
+			newGauge(dims, uint64(seconds(4)), 4),
+		}, consumer.metrics)
+	})
+
+	t.Run("cumulative", func(t *testing.T) {
+		// Cumulative sums are translated as without the option.
+		md := createTestSumMetrics(pmetric.AggregationTemporalityCumulative, true, 1, 3, 8, 10)
+		consumer := &mockFullConsumer{}
+		_, err := newNormalizingTranslator().MapMetrics(ctx, md, consumer)
+		require.NoError(t, err)
+		tr, err := NewTranslator(zap.NewNop())
+		require.NoError(t, err)
+		expected := &mockFullConsumer{}
+		_, err = tr.MapMetrics(ctx, md, expected)
+		require.NoError(t, err)
+		assert.NotEmpty(t, consumer.metrics)
+		assert.Equal(t, expected.metrics, consumer.metrics)
+	})
+
+	t.Run("delta target", func(t *testing.T) {
+		// Delta sums are reported as is, which is the default behavior.
+		tr, err := NewTranslator(zap.NewNop(), WithNormalizeSumTemporality(pmetric.AggregationTemporalityDelta))
+		require.NoError(t, err)
+		consumer := &mockFullConsumer{}
+		_, err = tr.MapMetrics(ctx, createTestSumMetrics(pmetric.AggregationTemporalityDelta, false, 1, 3, -1), consumer)
+		require.NoError(t, err)
+		assert.Equal(t, []metric{
+			newGauge(dims, uint64(seconds(2)), 3),
+			newGauge(dims, uint64(seconds(3)), -1),
+		}, consumer.metrics)
+	})
+
+	_, err := NewTranslator(zap.NewNop(), WithNormalizeSumTemporality(pmetric.AggregationTemporalityUnspecified))
+	assert.EqualError(t, err, "unsupported sum temporality: Unspecified")
+}
+
+func TestMapHistogramDecreasingBucketCounts(t *testing.T) {
+	// A decreasing cumulative bucket count would result in a negative delta: it is treated as a reset.
+	md := pmetric.NewMetrics()
+	hist := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	hist.SetName("hist")
+	hist.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	for i, counts := range [][]uint64{{10, 20}, {15, 5}} {
+		dp := hist.Histogram().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(seconds(0))
+		dp.SetTimestamp(seconds(i + 1))
+		dp.ExplicitBounds().FromRaw([]float64{0})
+		dp.BucketCounts().FromRaw(counts)
+		dp.SetCount(counts[0] + counts[1])
+	}
+
+	for _, mode := range []HistogramMode{HistogramModeCounters, HistogramModeDistributions} {
+		t.Run(string(mode), func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), WithHistogramMode(mode), WithValueClamp(-100, 100))
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+			for _, m := range consumer.metrics {
+				assert.GreaterOrEqual(t, m.value, 0.0, m.name)
+			}
+			for _, s := range consumer.sketches {
+				assert.GreaterOrEqual(t, s.basic.Cnt, int64(0), s.name)
+			}
+		})
+	}
+}
+
+func TestWithConstantTagsInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithConstantTags("production"))
+	assert.EqualError(t, err, `constant tag "production" must be of the form key:value`)
+
+	_, err = NewTranslator(zap.NewNop(), WithConstantTags("1env:prod"))
+	assert.ErrorContains(t, err, "invalid constant tag: invalid tag \"1env\":\"prod\": key must start with a letter")
+}
+
 const (
 	testHostname     = "res-hostname"
 	fallbackHostname = "fallbackHostname"
@@ -1177,3 +2766,249 @@ var statsPayloads = []*pb.ClientStatsPayload{
 		},
 	},
 }
+
+func TestIdempotentTranslation(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metricsArray.AppendEmpty()
+	gauge.SetName("gauge")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(10))
+	dp.SetDoubleValue(1.5)
+
+	delta := metricsArray.AppendEmpty()
+	delta.SetName("delta.sum")
+	delta.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	delta.Sum().SetIsMonotonic(true)
+	dp = delta.Sum().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(10))
+	dp.SetIntValue(3)
+
+	upDown := metricsArray.AppendEmpty()
+	upDown.SetName("cumulative.updown.sum")
+	upDown.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp = upDown.Sum().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(seconds(1))
+	dp.SetTimestamp(seconds(10))
+	dp.SetIntValue(-2)
+
+	histogram := metricsArray.AppendEmpty()
+	histogram.SetName("delta.histogram")
+	histogram.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	hdp := histogram.Histogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(seconds(10))
+	hdp.SetCount(2)
+	hdp.SetSum(3)
+	hdp.BucketCounts().FromRaw([]uint64{1, 1})
+	hdp.ExplicitBounds().FromRaw([]float64{1})
+
+	monotonic := metricsArray.AppendEmpty()
+	monotonic.SetName("cumulative.monotonic.sum")
+	monotonic.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	monotonic.Sum().SetIsMonotonic(true)
+	dp = monotonic.Sum().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(seconds(1))
+	dp.SetTimestamp(seconds(10))
+	dp.SetIntValue(5)
+
+	tr, err := NewTranslator(zap.NewNop())
+	require.NoError(t, err)
+
+	first := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, first)
+	require.NoError(t, err)
+	second := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, second)
+	require.NoError(t, err)
+
+	// Non-monotonic paths produce the same output on both calls.
+	nonMonotonic := []metric{
+		newGauge(newDims("gauge"), uint64(seconds(10)), 1.5),
+		newCount(newDims("delta.sum"), uint64(seconds(10)), 3),
+		newGauge(newDims("cumulative.updown.sum"), uint64(seconds(10)), -2),
+	}
+	assert.ElementsMatch(t, nonMonotonic, first.metrics)
+	assert.Equal(t, first.sketches, second.sketches)
+	assert.Len(t, second.sketches, 1)
+
+	// The first point of a cumulative monotonic sum only initializes it: the repeated point is a zero delta.
+	assert.ElementsMatch(t, append(nonMonotonic, newCount(newDims("cumulative.monotonic.sum"), uint64(seconds(10)), 0)), second.metrics)
+}
+
+func TestMapMetricsNegativeValues(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	values := []float64{-5, 5, -5, 5}
+
+	gauge := metricsArray.AppendEmpty()
+	gauge.SetName("temperature.offset")
+	gauge.SetEmptyGauge()
+	upDown := metricsArray.AppendEmpty()
+	upDown.SetName("queue.balance")
+	upDown.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	for i, val := range values {
+		dp := gauge.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(i + 1))
+		dp.SetDoubleValue(val)
+		dp = upDown.Sum().DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(seconds(0))
+		dp.SetTimestamp(seconds(i + 1))
+		dp.SetDoubleValue(val)
+	}
+
+	for _, numberMode := range []NumberMode{NumberModeCumulativeToDelta, NumberModeRawValue} {
+		t.Run(string(numberMode), func(t *testing.T) {
+			core, observed := observer.New(zapcore.DebugLevel)
+			tr, err := NewTranslator(zap.New(core), WithNumberMode(numberMode))
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			metadata, err := tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+
+			var expected []metric
+			for i, val := range values {
+				expected = append(expected,
+					newGauge(newDims("temperature.offset"), uint64(seconds(i+1)), val),
+					newGauge(newDims("queue.balance"), uint64(seconds(i+1)), val),
+				)
+			}
+			assert.ElementsMatch(t, expected, consumer.metrics)
+			assert.Zero(t, observed.Len(), "negative values must not be logged")
+			assert.Equal(t, TranslatorStats{}, metadata.Stats)
+		})
+	}
+}
+
+func TestMapMetricsNoDataPoints(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	metricsArray.AppendEmpty().SetEmptyGauge()
+	metricsArray.AppendEmpty().SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	metricsArray.AppendEmpty().SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	metricsArray.AppendEmpty().SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	metricsArray.AppendEmpty().SetEmptySummary()
+	for i := 0; i < metricsArray.Len(); i++ {
+		metricsArray.At(i).SetName("empty." + strconv.Itoa(i))
+	}
+	gauge := metricsArray.AppendEmpty()
+	gauge.SetName("gauge")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+
+	core, observed := observer.New(zapcore.DebugLevel)
+	tr, err := NewTranslator(zap.New(core))
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	var metadata Metadata
+	require.NotPanics(t, func() {
+		metadata, err = tr.MapMetrics(context.Background(), md, consumer)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []metric{newGauge(newDims("gauge"), uint64(seconds(1)), 1)}, consumer.metrics)
+	assert.Empty(t, consumer.sketches)
+	assert.Equal(t, TranslatorStats{EmptyDataPoints: 5}, metadata.Stats)
+	assert.Equal(t, 5, observed.FilterMessage("Skipping metric with no datapoints").Len())
+}
+
+func TestMapMetricsScopeTagPrefix(t *testing.T) {
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("go.opentelemetry.io/otel/metric")
+	sm.Scope().SetVersion("1.11.1")
+	met := sm.Metrics().AppendEmpty()
+	met.SetName("system.load")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+
+	tests := []struct {
+		name   string
+		prefix string
+		tags   []string
+	}{
+		{
+			name:   "prefix",
+			prefix: "otel.scope.",
+			tags:   []string{"otel.scope.name:go.opentelemetry.io/otel/metric", "otel.scope.version:1.11.1"},
+		},
+		{
+			name:   "empty prefix",
+			prefix: "",
+			tags:   []string{"instrumentation_scope:go.opentelemetry.io/otel/metric", "instrumentation_scope_version:1.11.1"},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), WithInstrumentationScopeMetadataAsTags(), WithScopeTagPrefix(testInstance.prefix))
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+
+			require.Len(t, consumer.metrics, 1)
+			assert.ElementsMatch(t, testInstance.tags, consumer.metrics[0].tags)
+			for _, tag := range consumer.metrics[0].tags {
+				assert.False(t, strings.HasPrefix(tag, ":"), "tag %q has an empty key", tag)
+			}
+		})
+	}
+
+	_, err := NewTranslator(zap.NewNop(), WithScopeTagPrefix("otel:"))
+	assert.EqualError(t, err, `scope tag prefix must not contain ':': "otel:"`)
+}
+
+func TestMapMetricsScopeVersionTag(t *testing.T) {
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("go.opentelemetry.io/otel/metric")
+	sm.Scope().SetVersion("1.11.1")
+	met := sm.Metrics().AppendEmpty()
+	met.SetName("system.load")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+
+	tests := []struct {
+		name    string
+		options []TranslatorOption
+		tags    []string
+	}{
+		{
+			name:    "default",
+			options: []TranslatorOption{WithInstrumentationScopeMetadataAsTags()},
+			tags:    []string{"instrumentation_scope:go.opentelemetry.io/otel/metric", "instrumentation_scope_version:1.11.1"},
+		},
+		{
+			name:    "enabled",
+			options: []TranslatorOption{WithInstrumentationScopeMetadataAsTags(), WithScopeVersionTag(true)},
+			tags:    []string{"instrumentation_scope:go.opentelemetry.io/otel/metric", "instrumentation_scope_version:1.11.1"},
+		},
+		{
+			name:    "disabled",
+			options: []TranslatorOption{WithInstrumentationScopeMetadataAsTags(), WithScopeVersionTag(false)},
+			tags:    []string{"instrumentation_scope:go.opentelemetry.io/otel/metric"},
+		},
+		{
+			name:    "disabled with prefix",
+			options: []TranslatorOption{WithInstrumentationScopeMetadataAsTags(), WithScopeTagPrefix("otel.scope."), WithScopeVersionTag(false)},
+			tags:    []string{"otel.scope.name:go.opentelemetry.io/otel/metric"},
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), testInstance.options...)
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+
+			require.Len(t, consumer.metrics, 1)
+			assert.ElementsMatch(t, testInstance.tags, consumer.metrics[0].tags)
+		})
+	}
+}
@@ -6,10 +6,14 @@
 package metrics
 
 import (
+	"context"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
@@ -23,6 +27,8 @@ func TestMapMetrics(t *testing.T) {
 		options                                   []TranslatorOption
 		expectedUnknownMetricType                 int
 		expectedUnsupportedAggregationTemporality int
+		// expectedStats defaults to the stats matching the expected log counts.
+		expectedStats *TranslatorStats
 	}{
 		{
 			name:                      "no-options",
@@ -105,6 +111,33 @@ func TestMapMetrics(t *testing.T) {
 			expectedUnknownMetricType:                 1,
 			expectedUnsupportedAggregationTemporality: 2,
 		},
+		{
+			// Delta sums are reported as counts regardless of the number mode.
+			name:     "raw-value-number-mode",
+			otlpfile: "testdata/otlpdata/mixed/simple.json",
+			ddogfile: "testdata/datadogdata/mixed/simple_raw-value.json",
+			options: []TranslatorOption{
+				WithNumberMode(NumberModeRawValue),
+			},
+			expectedUnknownMetricType:                 1,
+			expectedUnsupportedAggregationTemporality: 2,
+		},
+		{
+			// Identical log messages are collapsed: the two unsupported temporality messages are logged once.
+			name:     "log-sampling",
+			otlpfile: "testdata/otlpdata/mixed/simple.json",
+			ddogfile: "testdata/datadogdata/mixed/simple.json",
+			options: []TranslatorOption{
+				WithLogSamplingInterval(time.Hour),
+			},
+			expectedUnknownMetricType:                 1,
+			expectedUnsupportedAggregationTemporality: 1,
+			// Logs are sampled but all dropped metrics are counted.
+			expectedStats: &TranslatorStats{
+				DroppedMetrics:         2,
+				UnsupportedMetricTypes: 1,
+			},
+		},
 		{
 			name:     "with-all",
 			otlpfile: "testdata/otlpdata/mixed/simple.json",
@@ -112,12 +145,40 @@ func TestMapMetrics(t *testing.T) {
 			options: []TranslatorOption{
 				WithHistogramAggregations(),
 				WithResourceAttributesAsTags(),
-				WithInstrumentationLibraryMetadataAsTags(),
 				WithInstrumentationScopeMetadataAsTags(),
 			},
 			expectedUnknownMetricType:                 1,
 			expectedUnsupportedAggregationTemporality: 2,
 		},
+		{
+			// Each resource is mapped following the semantic conventions version of its schema URL:
+			// container.image.tags is only mapped from v1.22.0 on.
+			name:     "schema-versions",
+			otlpfile: "testdata/otlpdata/mixed/schema-versions.json",
+			ddogfile: "testdata/datadogdata/mixed/schema-versions.json",
+		},
+		{
+			name:     "exemplars-disabled",
+			otlpfile: "testdata/otlpdata/mixed/exemplars.json",
+			ddogfile: "testdata/datadogdata/mixed/exemplars.json",
+		},
+		{
+			name:     "exemplars",
+			otlpfile: "testdata/otlpdata/mixed/exemplars.json",
+			ddogfile: "testdata/datadogdata/mixed/exemplars_exemplars.json",
+			options: []TranslatorOption{
+				WithExemplarTranslation(),
+			},
+		},
+		{
+			name:     "exemplars-counters",
+			otlpfile: "testdata/otlpdata/mixed/exemplars.json",
+			ddogfile: "testdata/datadogdata/mixed/exemplars_exemplars-counters.json",
+			options: []TranslatorOption{
+				WithExemplarTranslation(),
+				WithHistogramMode(HistogramModeCounters),
+			},
+		},
 	}
 
 	for _, testinstance := range tests {
@@ -126,9 +187,43 @@ func TestMapMetrics(t *testing.T) {
 			testLogger := zap.New(core)
 			translator, err := NewTranslator(testLogger, testinstance.options...)
 			require.NoError(t, err)
-			AssertTranslatorMap(t, translator, testinstance.otlpfile, testinstance.ddogfile)
+			stats := TranslatorStats{
+				DroppedMetrics:         testinstance.expectedUnsupportedAggregationTemporality,
+				UnsupportedMetricTypes: testinstance.expectedUnknownMetricType,
+			}
+			if testinstance.expectedStats != nil {
+				stats = *testinstance.expectedStats
+			}
+			AssertTranslatorMapWithStats(t, translator, testinstance.otlpfile, testinstance.ddogfile, stats)
 			assert.Equal(t, testinstance.expectedUnknownMetricType, observed.FilterMessage("Unknown or unsupported metric type").Len())
 			assert.Equal(t, testinstance.expectedUnsupportedAggregationTemporality, observed.FilterMessage("Unknown or unsupported aggregation temporality").Len())
 		})
 	}
 }
+
+func TestMapMetricsDryRun(t *testing.T) {
+	otlpbytes, err := os.ReadFile("testdata/otlpdata/mixed/simple.json")
+	require.NoError(t, err)
+	var unmarshaler pmetric.JSONUnmarshaler
+	md, err := unmarshaler.UnmarshalMetrics(otlpbytes)
+	require.NoError(t, err)
+
+	tr, err := NewTranslator(zap.NewNop())
+	require.NoError(t, err)
+	var consumer testConsumer
+	metadata, err := tr.MapMetrics(context.Background(), md, &consumer)
+	require.NoError(t, err)
+
+	tr, err = NewTranslator(zap.NewNop(), WithDryRun())
+	require.NoError(t, err)
+	dryRunMetadata, err := tr.MapMetrics(context.Background(), md, nil)
+	require.NoError(t, err)
+
+	// The dry run accounts for everything the regular run consumed.
+	expected := metadata.Stats
+	expected.TimeSeries = len(consumer.testMetrics.TimeSeries)
+	expected.Sketches = len(consumer.testMetrics.Sketches)
+	assert.Equal(t, expected, dryRunMetadata.Stats)
+	assert.NotZero(t, dryRunMetadata.Stats.TimeSeries)
+	assert.NotZero(t, dryRunMetadata.Stats.Sketches)
+}
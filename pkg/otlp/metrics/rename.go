@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+)
+
+// RenameRule renames the translated metrics whose Datadog metric name matches a regular expression.
+type RenameRule struct {
+	// Pattern is the regular expression matched against the metric name.
+	Pattern string
+	// Replacement is the new metric name. It may reference capture groups of Pattern (e.g. "$1"),
+	// following the regexp.Regexp.ReplaceAllString syntax.
+	Replacement string
+	// DuplicateOnMatch reports the metric under both its original and its new name.
+	DuplicateOnMatch bool
+
+	re *regexp.Regexp
+}
+
+var _ Consumer = (*renameConsumer)(nil)
+var _ TimeSeriesIntervalConsumer = (*renameConsumer)(nil)
+var _ ExemplarConsumer = (*renameConsumer)(nil)
+
+// renameConsumer is a Consumer that applies rename rules before passing metrics to another consumer.
+type renameConsumer struct {
+	Consumer
+	rules []RenameRule
+}
+
+// rename returns the dimensions to report the metric with: the renamed dimensions, preceded by
+// the original ones if the matching rule duplicates metrics. The first matching rule is used.
+func (c *renameConsumer) rename(dims *Dimensions) []*Dimensions {
+	for _, rule := range c.rules {
+		if !rule.re.MatchString(dims.name) {
+			continue
+		}
+		renamed := dims.WithName(rule.re.ReplaceAllString(dims.name, rule.Replacement))
+		if rule.DuplicateOnMatch {
+			return []*Dimensions{dims, renamed}
+		}
+		return []*Dimensions{renamed}
+	}
+	return []*Dimensions{dims}
+}
+
+// ConsumeTimeSeries implements the TimeSeriesConsumer interface.
+func (c *renameConsumer) ConsumeTimeSeries(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	value float64,
+) {
+	for _, dims := range c.rename(dimensions) {
+		c.Consumer.ConsumeTimeSeries(ctx, dims, typ, timestamp, value)
+	}
+}
+
+// ConsumeTimeSeriesWithInterval implements the TimeSeriesIntervalConsumer interface.
+func (c *renameConsumer) ConsumeTimeSeriesWithInterval(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	interval int64,
+	value float64,
+) {
+	for _, dims := range c.rename(dimensions) {
+		consumeTimeSeriesWithInterval(ctx, c.Consumer, dims, typ, timestamp, interval, value)
+	}
+}
+
+// ConsumeSketch implements the SketchConsumer interface.
+func (c *renameConsumer) ConsumeSketch(
+	ctx context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	sketch *quantile.Sketch,
+) {
+	for _, dims := range c.rename(dimensions) {
+		c.Consumer.ConsumeSketch(ctx, dims, timestamp, sketch)
+	}
+}
+
+// ConsumeExemplars implements the ExemplarConsumer interface.
+func (c *renameConsumer) ConsumeExemplars(
+	ctx context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	exemplars []Exemplar,
+) {
+	for _, dims := range c.rename(dimensions) {
+		consumeExemplars(ctx, c.Consumer, dims, timestamp, exemplars)
+	}
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestMapMetricsRenameRules(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"http.server.duration", "db.client.connections.usage", "jvm.memory.used", "system.cpu.time"} {
+		met := metrics.AppendEmpty()
+		met.SetName(name)
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(1)
+	}
+	hist := metrics.AppendEmpty()
+	hist.SetName("rpc.server.duration")
+	hist.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	hdp := hist.Histogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(seconds(1))
+	hdp.SetCount(1)
+	hdp.BucketCounts().FromRaw([]uint64{1})
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithMetricRenameRules([]RenameRule{
+			// Capture groups are expanded in the replacement.
+			{Pattern: `^http\.(\w+)\.duration$`, Replacement: "web.$1.latency"},
+			{Pattern: `^db\.client\.(?P<kind>\w+)\.usage$`, Replacement: "database.${kind}.in_use", DuplicateOnMatch: true},
+			// Only the first matching rule is applied.
+			{Pattern: `^web\.`, Replacement: "unused."},
+			{Pattern: `^http\.`, Replacement: "unused."},
+		}),
+		WithMetricRenameRules([]RenameRule{
+			{Pattern: `^jvm\.`, Replacement: "java."},
+		}),
+	)
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	var names []string
+	for _, m := range consumer.metrics {
+		names = append(names, m.name)
+	}
+	assert.ElementsMatch(t, []string{
+		"web.server.latency",
+		"db.client.connections.usage",
+		"database.connections.in_use",
+		"java.memory.used",
+		"system.cpu.time",
+	}, names)
+	require.Len(t, consumer.sketches, 1)
+	assert.Equal(t, "rpc.server.duration", consumer.sketches[0].name)
+}
+
+func TestMapMetricsRenameRulesBeforeHooks(t *testing.T) {
+	md := pmetric.NewMetrics()
+	met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("app.requests")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+
+	var seen []string
+	tr, err := NewTranslator(zap.NewNop(),
+		WithMetricRenameRules([]RenameRule{{Pattern: `^app\.(.*)$`, Replacement: "service.$1", DuplicateOnMatch: true}}),
+		WithTranslationHook(func(dims *Dimensions) *Dimensions {
+			seen = append(seen, dims.Name())
+			return dims
+		}),
+	)
+	require.NoError(t, err)
+	_, err = tr.MapMetrics(context.Background(), md, &mockFullConsumer{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app.requests", "service.requests"}, seen)
+}
+
+func TestWithMetricRenameRulesInvalid(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithMetricRenameRules([]RenameRule{{Pattern: `(`, Replacement: "x"}}))
+	assert.ErrorContains(t, err, "invalid rename pattern \"(\"")
+}
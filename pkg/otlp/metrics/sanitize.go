@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"strings"
+)
+
+// maxMetricNameLength is the maximum length of a Datadog metric name.
+const maxMetricNameLength = 200
+
+// isAlpha checks if a character is an ASCII letter.
+func isAlpha(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// isValidMetricNameChar checks if a character is allowed in a Datadog metric name.
+func isValidMetricNameChar(c byte) bool {
+	return isAlpha(c) || ('0' <= c && c <= '9') || c == '_' || c == '.'
+}
+
+// sanitizeMetricName makes a metric name follow the Datadog metric naming constraints:
+// names must match `[a-zA-Z][a-zA-Z0-9_.]*` and be at most 200 characters long.
+// Illegal characters are replaced by dots, consecutive dots are collapsed, leading
+// characters other than letters and trailing dots are trimmed, and the result is truncated.
+func sanitizeMetricName(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !isValidMetricNameChar(c) {
+			c = '.'
+		}
+		if b.Len() == 0 && !isAlpha(c) {
+			// Names must start with a letter.
+			continue
+		}
+		if c == '.' && strings.HasSuffix(b.String(), ".") {
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	sanitized := b.String()
+	if len(sanitized) > maxMetricNameLength {
+		sanitized = sanitized[:maxMetricNameLength]
+	}
+	return strings.TrimRight(sanitized, ".")
+}
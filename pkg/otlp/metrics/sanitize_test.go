@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestSanitizeMetricName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "http.server.request.duration", expected: "http.server.request.duration"},
+		{name: "system_cpu.time_2", expected: "system_cpu.time_2"},
+		{name: "http-server/request:duration", expected: "http.server.request.duration"},
+		{name: "http..server---duration", expected: "http.server.duration"},
+		{name: ".leading.and.trailing.", expected: "leading.and.trailing"},
+		{name: "1_metric.name", expected: "metric.name"},
+		{name: "métrique", expected: "m.trique"},
+		{name: "!!!", expected: ""},
+		{name: strings.Repeat("a", 250), expected: strings.Repeat("a", 200)},
+		{name: strings.Repeat("a", 199) + "-b", expected: strings.Repeat("a", 199)},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			assert.Equal(t, testInstance.expected, sanitizeMetricName(testInstance.name))
+		})
+	}
+}
+
+func TestMapMetricsNameSanitization(t *testing.T) {
+	md := pmetric.NewMetrics()
+	met := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	met.SetName("http-server/request:duration")
+	dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+
+	tests := []struct {
+		name     string
+		options  []TranslatorOption
+		expected string
+	}{
+		{
+			name:     "disabled by default",
+			expected: "http-server/request:duration",
+		},
+		{
+			name:     "disabled",
+			options:  []TranslatorOption{WithMetricNameSanitization(false)},
+			expected: "http-server/request:duration",
+		},
+		{
+			name:     "enabled",
+			options:  []TranslatorOption{WithMetricNameSanitization(true)},
+			expected: "http.server.request.duration",
+		},
+		{
+			name:     "enabled with prefix",
+			options:  []TranslatorOption{WithMetricNameSanitization(true), WithMetricNamePrefix("otel.")},
+			expected: "otel.http.server.request.duration",
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			tr, err := NewTranslator(zap.NewNop(), testInstance.options...)
+			require.NoError(t, err)
+			consumer := &mockFullConsumer{}
+			_, err = tr.MapMetrics(context.Background(), md, consumer)
+			require.NoError(t, err)
+			require.Len(t, consumer.metrics, 1)
+			assert.Equal(t, testInstance.expected, consumer.metrics[0].name)
+		})
+	}
+}
+
+func TestMapMetricsNameSanitizationEmpty(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"123", "!!!", "requests"} {
+		met := metrics.AppendEmpty()
+		met.SetName(name)
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(1)
+	}
+
+	tr, err := NewTranslator(zap.NewNop(), WithMetricNameSanitization(true))
+	require.NoError(t, err)
+	consumer := &mockFullConsumer{}
+	metadata, err := tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+	require.Len(t, consumer.metrics, 1)
+	assert.Equal(t, "requests", consumer.metrics[0].name)
+	assert.Equal(t, 2, metadata.Stats.DroppedMetrics)
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// ServiceCheckRule maps the values of a gauge to the status of a Datadog service check.
+type ServiceCheckRule struct {
+	// MetricName is the Datadog name of the gauge. It is either an exact metric name
+	// or a glob pattern following the path.Match syntax (e.g. "*.health").
+	MetricName string
+	// Min is the inclusive lower bound of the values matched by the rule.
+	Min float64
+	// Max is the exclusive upper bound of the values matched by the rule.
+	Max float64
+	// CheckName is the name of the service check.
+	CheckName string
+	// Status is the status of the service check when the rule matches.
+	Status ServiceCheckStatus
+}
+
+// matches checks if the rule applies to a datapoint with the given metric name and value.
+func (r ServiceCheckRule) matches(name string, value float64) bool {
+	return value >= r.Min && value < r.Max && matchesPattern(r.MetricName, name)
+}
+
+// validate checks that the rule is well formed.
+func (r ServiceCheckRule) validate() error {
+	if r.MetricName == "" || r.CheckName == "" {
+		return fmt.Errorf("service check rule must have a metric name and a check name")
+	}
+	if err := validatePatterns([]string{r.MetricName}); err != nil {
+		return err
+	}
+	if math.IsNaN(r.Min) || math.IsNaN(r.Max) || r.Min >= r.Max {
+		return fmt.Errorf("invalid value range [%v, %v) for service check %q", r.Min, r.Max, r.CheckName)
+	}
+	if r.Status < ServiceCheckOK || r.Status > ServiceCheckUnknown {
+		return fmt.Errorf("invalid status %d for service check %q", r.Status, r.CheckName)
+	}
+	return nil
+}
+
+// mapServiceChecks reports a service check for each gauge datapoint matching a service check rule.
+// The first matching rule is used. Datapoint values are compared before any unit conversion.
+func (t *Translator) mapServiceChecks(
+	ctx context.Context,
+	consumer ServiceCheckConsumer,
+	dims *Dimensions,
+	slice pmetric.NumberDataPointSlice,
+) {
+	for i := 0; i < slice.Len(); i++ {
+		p := slice.At(i)
+		if p.Flags().NoRecordedValue() {
+			continue
+		}
+		var val float64
+		switch p.ValueType() {
+		case pmetric.NumberDataPointValueTypeDouble:
+			val = p.DoubleValue()
+		case pmetric.NumberDataPointValueTypeInt:
+			val = float64(p.IntValue())
+		}
+
+		for _, rule := range t.cfg.ServiceCheckRules {
+			if rule.matches(dims.name, val) {
+				pointDims := t.pointDimensions(ctx, dims, p.Attributes())
+				consumer.ConsumeServiceCheck(ctx, pointDims.WithName(rule.CheckName), uint64(p.Timestamp()), rule.Status)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+type serviceCheck struct {
+	name      string
+	tags      []string
+	host      string
+	timestamp uint64
+	status    ServiceCheckStatus
+}
+
+type mockServiceCheckConsumer struct {
+	mockFullConsumer
+	serviceChecks []serviceCheck
+}
+
+func (c *mockServiceCheckConsumer) ConsumeServiceCheck(
+	_ context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	status ServiceCheckStatus,
+) {
+	c.serviceChecks = append(c.serviceChecks, serviceCheck{
+		name:      dimensions.Name(),
+		tags:      dimensions.Tags(),
+		host:      dimensions.Host(),
+		timestamp: timestamp,
+		status:    status,
+	})
+}
+
+var healthRules = []ServiceCheckRule{
+	{MetricName: "*.health", Min: 0, Max: 1, CheckName: "app.can_connect", Status: ServiceCheckOK},
+	{MetricName: "*.health", Min: 1, Max: 2, CheckName: "app.can_connect", Status: ServiceCheckWarning},
+	{MetricName: "*.health", Min: 2, Max: math.Inf(1), CheckName: "app.can_connect", Status: ServiceCheckCritical},
+}
+
+func TestMapMetricsServiceChecks(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	health := metricsArray.AppendEmpty()
+	health.SetName("app.health")
+	healthPoints := health.SetEmptyGauge().DataPoints()
+	values := []float64{0, 0.999, 1, 1.5, 2, 5, -1}
+	for i, val := range values {
+		dp := healthPoints.AppendEmpty()
+		dp.SetTimestamp(seconds(i))
+		dp.SetDoubleValue(val)
+		dp.Attributes().PutStr("env", "prod")
+	}
+	other := metricsArray.AppendEmpty()
+	other.SetName("app.load")
+	otherDp := other.SetEmptyGauge().DataPoints().AppendEmpty()
+	otherDp.SetTimestamp(seconds(0))
+	otherDp.SetIntValue(1)
+
+	tr, err := NewTranslator(zap.NewNop(), WithServiceCheckMapping(healthRules))
+	require.NoError(t, err)
+	consumer := &mockServiceCheckConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	// The gauges are still reported.
+	assert.Len(t, consumer.metrics, len(values)+1)
+
+	tags := []string{"env:prod"}
+	assert.Equal(t, []serviceCheck{
+		{name: "app.can_connect", tags: tags, timestamp: uint64(seconds(0)), status: ServiceCheckOK},
+		{name: "app.can_connect", tags: tags, timestamp: uint64(seconds(1)), status: ServiceCheckOK},
+		{name: "app.can_connect", tags: tags, timestamp: uint64(seconds(2)), status: ServiceCheckWarning},
+		{name: "app.can_connect", tags: tags, timestamp: uint64(seconds(3)), status: ServiceCheckWarning},
+		{name: "app.can_connect", tags: tags, timestamp: uint64(seconds(4)), status: ServiceCheckCritical},
+		{name: "app.can_connect", tags: tags, timestamp: uint64(seconds(5)), status: ServiceCheckCritical},
+	}, consumer.serviceChecks)
+}
+
+func TestWithServiceCheckMappingInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule ServiceCheckRule
+		err  string
+	}{
+		{
+			name: "missing check name",
+			rule: ServiceCheckRule{MetricName: "app.health", Max: 1},
+			err:  "service check rule must have a metric name and a check name",
+		},
+		{
+			name: "invalid pattern",
+			rule: ServiceCheckRule{MetricName: "app.[health", Max: 1, CheckName: "app.can_connect"},
+			err:  `invalid pattern "app.[health": syntax error in pattern`,
+		},
+		{
+			name: "empty range",
+			rule: ServiceCheckRule{MetricName: "app.health", Min: 1, Max: 1, CheckName: "app.can_connect"},
+			err:  `invalid value range [1, 1) for service check "app.can_connect"`,
+		},
+		{
+			name: "invalid status",
+			rule: ServiceCheckRule{MetricName: "app.health", Max: 1, CheckName: "app.can_connect", Status: 4},
+			err:  `invalid status 4 for service check "app.can_connect"`,
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			_, err := NewTranslator(zap.NewNop(), WithServiceCheckMapping([]ServiceCheckRule{testInstance.rule}))
+			assert.EqualError(t, err, testInstance.err)
+		})
+	}
+}
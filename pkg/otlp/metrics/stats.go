@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import "context"
+
+// TranslatorStats are diagnostics about the translation of a MapMetrics call.
+type TranslatorStats struct {
+	// DroppedMetrics is the number of metrics that were not translated: metrics excluded by the
+	// metric filter, metrics with an unsupported aggregation temporality, skipped summaries and metrics
+	// whose name is empty once sanitized (see WithMetricNameSanitization).
+	DroppedMetrics int
+	// UnsupportedMetricTypes is the number of metrics with an unknown or unsupported type, and of metrics
+	// excluded by the metric filter, which are also counted in DroppedMetrics.
+	UnsupportedMetricTypes int
+	// EmptyDataPoints is the number of metrics skipped because they have no datapoints.
+	EmptyDataPoints int
+	// NegativeDeltasReset is the number of resets detected on cumulative monotonic sums, either because
+	// the value decreased or because the start timestamp changed.
+	NegativeDeltasReset int
+	// InvalidCumulativeValues is the number of points of cumulative monotonic sums whose value decreased
+	// although their start timestamp was unchanged (see WithInvalidCumulativeHandling).
+	InvalidCumulativeValues int
+	// CacheEvictions is the number of expired timeseries, and of timeseries evicted because the cache was
+	// full (see WithDeltaCacheMaxSize), removed from the in-memory delta store since the previous MapMetrics call.
+	// It is always zero with a store set by WithDeltaStore.
+	CacheEvictions int
+	// DroppedStalePoints is the number of datapoints dropped because they were older than the maximum
+	// timestamp lag (see WithMetricTimestampLag).
+	DroppedStalePoints int
+	// TagsTruncated is the number of datapoints whose tags were truncated to the maximum tag count.
+	TagsTruncated int
+
+	// The following fields are only reported in dry run mode (see WithDryRun).
+
+	// TimeSeries is the number of gauge and count datapoints that would have been consumed.
+	TimeSeries int
+	// Sketches is the number of sketches that would have been consumed.
+	Sketches int
+	// ServiceChecks is the number of service checks that would have been consumed.
+	ServiceChecks int
+	// Exemplars is the number of exemplars that would have been consumed.
+	Exemplars int
+	// APMStats is the number of APM stats payloads that would have been consumed.
+	APMStats int
+}
+
+// statsKey is the context key of the stats of a MapMetrics call.
+type statsKey struct{}
+
+// contextWithStats returns a context carrying the stats of a MapMetrics call.
+func contextWithStats(ctx context.Context, stats *TranslatorStats) context.Context {
+	return context.WithValue(ctx, statsKey{}, stats)
+}
+
+// recordStats updates the stats carried by the context, if any.
+func recordStats(ctx context.Context, update func(stats *TranslatorStats)) {
+	if stats, ok := ctx.Value(statsKey{}).(*TranslatorStats); ok {
+		update(stats)
+	}
+}
+
+// evictionCounter is implemented by the delta stores that count their evictions.
+type evictionCounter interface {
+	// takeEvictions returns the number of evictions since the previous call.
+	takeEvictions() int
+}
+
+// resetter is implemented by the delta stores that can be cleared by Translator.Reset.
+type resetter interface {
+	// reset removes all the entries and resets the counters, and returns the number of removed timeseries.
+	reset() int
+}
@@ -8,6 +8,7 @@ package metrics
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -52,6 +53,25 @@ func TestSummaryMetrics(t *testing.T) {
 			},
 			tags: []string{"attribute_tag:attribute_value"},
 		},
+
+		{
+			name:     "quantiles-skip",
+			otlpfile: "testdata/otlpdata/summary/quantiles.json",
+			ddogfile: "testdata/datadogdata/summary/quantiles_summary-skip.json",
+			options:  []TranslatorOption{WithSummaryMode(SummaryModeSkip)},
+		},
+		{
+			name:     "quantiles-nobuckets",
+			otlpfile: "testdata/otlpdata/summary/quantiles.json",
+			ddogfile: "testdata/datadogdata/summary/quantiles_summary-nobuckets.json",
+			options:  []TranslatorOption{WithSummaryMode(SummaryModeNoBuckets)},
+		},
+		{
+			name:     "quantiles-gauges",
+			otlpfile: "testdata/otlpdata/summary/quantiles.json",
+			ddogfile: "testdata/datadogdata/summary/quantiles_summary-gauges.json",
+			options:  []TranslatorOption{WithSummaryMode(SummaryModeGauges)},
+		},
 	}
 
 	for _, testinstance := range tests {
@@ -62,3 +82,8 @@ func TestSummaryMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestWithSummaryModeUnknown(t *testing.T) {
+	_, err := NewTranslator(zap.NewNop(), WithSummaryMode("invalid"))
+	assert.EqualError(t, err, `unknown summary mode: "invalid"`)
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"sort"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+)
+
+var _ Consumer = (*sortedTagsConsumer)(nil)
+var _ TimeSeriesIntervalConsumer = (*sortedTagsConsumer)(nil)
+var _ ExemplarConsumer = (*sortedTagsConsumer)(nil)
+
+// sortedTagsConsumer is a Consumer that sorts the tags of the metrics before passing them to another consumer.
+type sortedTagsConsumer struct {
+	Consumer
+}
+
+// sortedCopy returns a sorted copy of the tags: the tags of dimensions are shared and must not be modified.
+func sortedCopy(tags []string) []string {
+	if sort.StringsAreSorted(tags) {
+		return tags
+	}
+	sorted := make([]string, len(tags))
+	copy(sorted, tags)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// sort returns the dimensions with sorted tags and host tags.
+func (c *sortedTagsConsumer) sort(dims *Dimensions) *Dimensions {
+	return &Dimensions{
+		name:         dims.name,
+		tags:         sortedCopy(dims.tags),
+		host:         dims.host,
+		hostTags:     sortedCopy(dims.hostTags),
+		originID:     dims.originID,
+		scopeVersion: dims.scopeVersion,
+	}
+}
+
+// ConsumeTimeSeries implements the TimeSeriesConsumer interface.
+func (c *sortedTagsConsumer) ConsumeTimeSeries(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	value float64,
+) {
+	c.Consumer.ConsumeTimeSeries(ctx, c.sort(dimensions), typ, timestamp, value)
+}
+
+// ConsumeTimeSeriesWithInterval implements the TimeSeriesIntervalConsumer interface.
+func (c *sortedTagsConsumer) ConsumeTimeSeriesWithInterval(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	interval int64,
+	value float64,
+) {
+	consumeTimeSeriesWithInterval(ctx, c.Consumer, c.sort(dimensions), typ, timestamp, interval, value)
+}
+
+// ConsumeSketch implements the SketchConsumer interface.
+func (c *sortedTagsConsumer) ConsumeSketch(
+	ctx context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	sketch *quantile.Sketch,
+) {
+	c.Consumer.ConsumeSketch(ctx, c.sort(dimensions), timestamp, sketch)
+}
+
+// ConsumeExemplars implements the ExemplarConsumer interface.
+func (c *sortedTagsConsumer) ConsumeExemplars(
+	ctx context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	exemplars []Exemplar,
+) {
+	consumeExemplars(ctx, c.Consumer, c.sort(dimensions), timestamp, exemplars)
+}
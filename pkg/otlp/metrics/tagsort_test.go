@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestMapMetricsTagSorting(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("zone", "eu-west-1a")
+	rm.Resource().Attributes().PutStr("cluster", "prod")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("app.queue_size")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetDoubleValue(1)
+	dp.Attributes().PutStr("queue", "orders")
+	dp.Attributes().PutStr("env", "prod")
+	dp.Attributes().PutStr("app", "shop")
+	hist := metrics.AppendEmpty()
+	hist.SetName("app.latency")
+	hist.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	hdp := hist.Histogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(seconds(1))
+	hdp.SetCount(1)
+	hdp.BucketCounts().FromRaw([]uint64{1})
+	hdp.Attributes().PutStr("route", "/checkout")
+	hdp.Attributes().PutStr("method", "GET")
+
+	tr, err := NewTranslator(zap.NewNop(),
+		WithTagSorting(),
+		WithHostTagAttributes("zone", "cluster"),
+		WithTranslationHook(func(dims *Dimensions) *Dimensions {
+			return dims.AddTags("team:payments")
+		}),
+	)
+	require.NoError(t, err)
+	assert.True(t, tr.Config().TagSorting)
+
+	var consumer testConsumer
+	_, err = tr.MapMetrics(context.Background(), md, &consumer)
+	require.NoError(t, err)
+
+	// Tags added by hooks are sorted along with the translated ones.
+	require.Len(t, consumer.testMetrics.TimeSeries, 1)
+	assert.Equal(t, []string{"app:shop", "env:prod", "queue:orders", "team:payments"}, consumer.testMetrics.TimeSeries[0].Tags)
+	assert.Equal(t, []string{"cluster:prod", "zone:eu-west-1a"}, consumer.testMetrics.TimeSeries[0].HostTags)
+
+	require.Len(t, consumer.testMetrics.Sketches, 1)
+	assert.Equal(t, []string{"method:GET", "route:/checkout", "team:payments"}, consumer.testMetrics.Sketches[0].Tags)
+	assert.Equal(t, []string{"cluster:prod", "zone:eu-west-1a"}, consumer.testMetrics.Sketches[0].HostTags)
+}
+
+func TestSortedCopy(t *testing.T) {
+	tags := []string{"b:2", "a:1", "c:3"}
+	assert.Equal(t, []string{"a:1", "b:2", "c:3"}, sortedCopy(tags))
+	// The original tags are not modified.
+	assert.Equal(t, []string{"b:2", "a:1", "c:3"}, tags)
+	assert.Empty(t, sortedCopy(nil))
+}
@@ -24,20 +24,23 @@ import (
 )
 
 // TestMetrics is the struct used for serializing Datadog metrics for generating testdata.
-// It contains sketches (distributions) and timeseries (all other types).
+// It contains sketches (distributions) and timeseries (all other types), along with their exemplars, if any.
 // This structure is not meant to be used directly; use AssertTranslatorMap instead.
 type TestMetrics struct {
 	Sketches   []TestSketch
 	TimeSeries []TestTimeSeries
+	Exemplars  []TestExemplars `json:",omitempty"`
 }
 
 // TestDimensions copies the Dimensions struct with public fields.
 // NOTE: Keep this in sync with the Dimensions struct.
 type TestDimensions struct {
-	Name     string
-	Tags     []string
-	Host     string
-	OriginID string
+	Name         string
+	Tags         []string
+	Host         string
+	HostTags     []string `json:",omitempty"`
+	OriginID     string
+	ScopeVersion string `json:",omitempty"`
 }
 
 type TestSketch struct {
@@ -55,6 +58,20 @@ type TestTimeSeries struct {
 	Value     float64
 }
 
+type TestExemplars struct {
+	TestDimensions
+	Timestamp uint64
+	Exemplars []TestExemplar
+}
+
+type TestExemplar struct {
+	TraceID   string
+	SpanID    string
+	Timestamp uint64
+	Value     float64
+	Tags      []string
+}
+
 // TestingT is an interface that defines a testing.T like object.
 type TestingT interface {
 	require.TestingT
@@ -70,11 +87,23 @@ type TestingT interface {
 // To generate OTLP data to be used on this assert, use the pmetric.JSONMarshaler and json.Indent.
 // If the Datadog data does not match, a file ending in .actual will be generated containing the actual translator output.
 func AssertTranslatorMap(t TestingT, translator *Translator, otlpfilename string, datadogfilename string) bool {
+	_, ok := assertTranslatorMap(t, translator, otlpfilename, datadogfilename)
+	return ok
+}
+
+// AssertTranslatorMapWithStats is like AssertTranslatorMap and additionally asserts the translation stats.
+func AssertTranslatorMapWithStats(t TestingT, translator *Translator, otlpfilename string, datadogfilename string, stats TranslatorStats) bool {
+	metadata, ok := assertTranslatorMap(t, translator, otlpfilename, datadogfilename)
+	return assert.Equal(t, stats, metadata.Stats, "unexpected translation stats") && ok
+}
+
+// assertTranslatorMap implements AssertTranslatorMap and returns the metadata of the translation.
+func assertTranslatorMap(t TestingT, translator *Translator, otlpfilename string, datadogfilename string) (Metadata, bool) {
 	// Check that the filenames follow conventions.
 	prefix := strings.TrimSuffix(filepath.Base(otlpfilename), ".json")
 	if !strings.HasPrefix(filepath.Base(datadogfilename), prefix) {
 		t.Errorf("%q and %q do not follow prefix convention", otlpfilename, datadogfilename)
-		return false
+		return Metadata{}, false
 	}
 
 	// Unmarshal OTLP data.
@@ -95,7 +124,7 @@ func AssertTranslatorMap(t TestingT, translator *Translator, otlpfilename string
 
 	// Map metrics using translator.
 	var consumer testConsumer
-	_, err = translator.MapMetrics(context.Background(), otlpdata, &consumer)
+	metadata, err := translator.MapMetrics(context.Background(), otlpdata, &consumer)
 	require.NoError(t, err)
 
 	if !assert.Equal(t, expecteddata, consumer.testMetrics) {
@@ -106,13 +135,14 @@ func AssertTranslatorMap(t TestingT, translator *Translator, otlpfilename string
 
 		err = os.WriteFile(actualfile, b, 0660)
 		require.NoError(t, err)
-		return false
+		return metadata, false
 	}
 
-	return true
+	return metadata, true
 }
 
 var _ Consumer = (*testConsumer)(nil)
+var _ ExemplarConsumer = (*testConsumer)(nil)
 
 type testConsumer struct {
 	testMetrics TestMetrics
@@ -134,10 +164,12 @@ func (t *testConsumer) ConsumeTimeSeries(
 	t.testMetrics.TimeSeries = append(t.testMetrics.TimeSeries,
 		TestTimeSeries{
 			TestDimensions: TestDimensions{
-				Name:     dimensions.Name(),
-				Tags:     dimensions.Tags(),
-				Host:     dimensions.Host(),
-				OriginID: dimensions.OriginID(),
+				Name:         dimensions.Name(),
+				Tags:         dimensions.Tags(),
+				Host:         dimensions.Host(),
+				HostTags:     dimensions.HostTags(),
+				OriginID:     dimensions.OriginID(),
+				ScopeVersion: dimensions.ScopeVersion(),
 			},
 			Type:      typ,
 			Timestamp: timestamp,
@@ -155,10 +187,12 @@ func (t *testConsumer) ConsumeSketch(
 	t.testMetrics.Sketches = append(t.testMetrics.Sketches,
 		TestSketch{
 			TestDimensions: TestDimensions{
-				Name:     dimensions.Name(),
-				Tags:     dimensions.Tags(),
-				Host:     dimensions.Host(),
-				OriginID: dimensions.OriginID(),
+				Name:         dimensions.Name(),
+				Tags:         dimensions.Tags(),
+				Host:         dimensions.Host(),
+				HostTags:     dimensions.HostTags(),
+				OriginID:     dimensions.OriginID(),
+				ScopeVersion: dimensions.ScopeVersion(),
 			},
 			Timestamp: timestamp,
 			Summary:   sketch.Basic,
@@ -168,6 +202,38 @@ func (t *testConsumer) ConsumeSketch(
 	)
 }
 
+func (t *testConsumer) ConsumeExemplars(
+	_ context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	exemplars []Exemplar,
+) {
+	testExemplars := make([]TestExemplar, 0, len(exemplars))
+	for _, e := range exemplars {
+		testExemplars = append(testExemplars, TestExemplar{
+			TraceID:   e.TraceID.String(),
+			SpanID:    e.SpanID.String(),
+			Timestamp: e.Timestamp,
+			Value:     e.Value,
+			Tags:      e.Tags,
+		})
+	}
+	t.testMetrics.Exemplars = append(t.testMetrics.Exemplars,
+		TestExemplars{
+			TestDimensions: TestDimensions{
+				Name:         dimensions.Name(),
+				Tags:         dimensions.Tags(),
+				Host:         dimensions.Host(),
+				HostTags:     dimensions.HostTags(),
+				OriginID:     dimensions.OriginID(),
+				ScopeVersion: dimensions.ScopeVersion(),
+			},
+			Timestamp: timestamp,
+			Exemplars: testExemplars,
+		},
+	)
+}
+
 // TestTestDimensions tests that TestDimensions fields match those of Dimensions.
 func TestTestDimensions(t *testing.T) {
 	testType := reflect.TypeOf(TestDimensions{})
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+//go:build testing
+// +build testing
+
+package metrics
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// NewTestTranslator creates a Translator with the given options and a no-op logger for use in tests.
+// The test fails immediately if the options are invalid. The in-memory delta cache, if used,
+// is flushed when the test completes, and its sweep goroutine (see WithCacheStatsCallback) is stopped.
+func NewTestTranslator(t *testing.T, opts ...TranslatorOption) *Translator {
+	t.Helper()
+	tr, err := NewTranslator(zap.NewNop(), opts...)
+	if err != nil {
+		t.Fatalf("failed to create translator: %v", err)
+	}
+	t.Cleanup(func() {
+		if store, ok := tr.prevPts.store.(sweepStopper); ok {
+			store.stopSweeps()
+		}
+		if store, ok := tr.prevPts.store.(resetter); ok {
+			store.reset()
+		}
+	})
+	return tr
+}
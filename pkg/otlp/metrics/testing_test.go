@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+//go:build testing
+// +build testing
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestTranslator(t *testing.T) {
+	var store *inMemoryDeltaStore
+	t.Run("translator", func(t *testing.T) {
+		tr := NewTestTranslator(t, WithHistogramMode(HistogramModeCounters))
+		assert.Equal(t, HistogramModeCounters, tr.Config().HistMode)
+
+		_, err := tr.MapMetrics(context.Background(), createTestIntCumulativeMonotonicMetrics(false), &mockFullConsumer{})
+		require.NoError(t, err)
+		store = tr.prevPts.store.(*inMemoryDeltaStore)
+		assert.NotZero(t, store.cache.ItemCount())
+	})
+	// The delta cache is flushed on cleanup.
+	assert.Zero(t, store.cache.ItemCount())
+}
+
+func TestNewTestTranslatorCacheStats(t *testing.T) {
+	var store *statsDeltaStore
+	t.Run("translator", func(t *testing.T) {
+		tr := NewTestTranslator(t, WithCacheStatsCallback(func(CacheStats) {}))
+		_, err := tr.MapMetrics(context.Background(), createTestIntCumulativeMonotonicMetrics(false), &mockFullConsumer{})
+		require.NoError(t, err)
+		store = tr.prevPts.store.(*statsDeltaStore)
+		assert.NotZero(t, store.cache.ItemCount())
+	})
+	// The delta cache is flushed and the sweep goroutine is stopped on cleanup.
+	assert.Zero(t, store.cache.ItemCount())
+	select {
+	case <-store.stop:
+	default:
+		t.Error("expected the sweeps to be stopped")
+	}
+}
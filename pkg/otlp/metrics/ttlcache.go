@@ -14,39 +14,128 @@
 
 package metrics
 
-import (
-	"time"
-
-	gocache "github.com/patrickmn/go-cache"
-)
-
 type ttlCache struct {
-	cache *gocache.Cache
+	store DeltaStore
+	// ttlRules are the per-metric time to live of the timeseries, used if the store implements TTLDeltaStore.
+	ttlRules map[string]int64
 }
 
 // numberCounter keeps the value of a number
-// monotonic counter at a given point in time
+// monotonic counter (or of an extrema) at a given point in time
 type numberCounter struct {
 	ts      uint64
 	startTs uint64
 	value   float64
 }
 
+// startTsKeySuffix is appended to a timeseries key to build the key under which the start timestamp
+// of the timeseries is stored. Since timeseries keys always end with dimensionSeparator, the resulting
+// key never collides with a timeseries key.
+const startTsKeySuffix = "startTs"
+
+// accumulatedKeySuffix is appended to a timeseries key to build the key under which the running total
+// of a delta timeseries is stored (see Accumulate), so that it never collides with the timeseries key.
+const accumulatedKeySuffix = "accumulated"
+
 func newTTLCache(sweepInterval int64, deltaTTL int64) *ttlCache {
-	cache := gocache.New(time.Duration(deltaTTL)*time.Second, time.Duration(sweepInterval)*time.Second)
-	return &ttlCache{cache}
+	return newTTLCacheWithStore(NewInMemoryDeltaStore(sweepInterval, deltaTTL))
+}
+
+func newTTLCacheWithStore(store DeltaStore) *ttlCache {
+	return &ttlCache{store: store}
+}
+
+// get returns the point stored for the given key, if any.
+func (t *ttlCache) get(key string) (numberCounter, bool) {
+	value, ts, found := t.store.Get(key)
+	if !found {
+		return numberCounter{}, false
+	}
+	// A missing start timestamp is equivalent to an unknown start timestamp.
+	_, startTs, _ := t.store.Get(key + startTsKeySuffix)
+	return numberCounter{
+		ts:      uint64(ts),
+		startTs: uint64(startTs),
+		value:   value,
+	}, true
+}
+
+// set stores a point for the given key of a timeseries with the given metric name.
+// The value is stored before the start timestamp: if the store is cleared in between, a start timestamp
+// without a value is ignored by get, whereas a value without its start timestamp would be compared
+// with the next point as if its start timestamp were unknown.
+func (t *ttlCache) set(name string, key string, cnt numberCounter) {
+	if store, ok := t.store.(TTLDeltaStore); ok {
+		if ttl, found := lookupPatternRule(t.ttlRules, name); found {
+			store.SetWithTTL(key, cnt.value, int64(cnt.ts), ttl)
+			store.SetWithTTL(key+startTsKeySuffix, 0, int64(cnt.startTs), ttl)
+			return
+		}
+	}
+	t.store.Set(key, cnt.value, int64(cnt.ts))
+	t.store.Set(key+startTsKeySuffix, 0, int64(cnt.startTs))
 }
 
 // Diff submits a new value for a given non-monotonic metric and returns the difference with the
 // last submitted value (ordered by timestamp). The diff value is only valid if `ok` is true.
 func (t *ttlCache) Diff(dimensions *Dimensions, startTs, ts uint64, val float64) (float64, bool) {
-	return t.putAndGetDiff(dimensions, false, startTs, ts, val)
+	dx, ok, _, _, _ := t.putAndGetDiff(dimensions, false, false, startTs, ts, val)
+	return dx, ok
 }
 
 // MonotonicDiff submits a new value for a given monotonic metric and returns the difference with the
 // last submitted value (ordered by timestamp). The diff value is only valid if `ok` is true.
 func (t *ttlCache) MonotonicDiff(dimensions *Dimensions, startTs, ts uint64, val float64) (float64, bool) {
-	return t.putAndGetDiff(dimensions, true, startTs, ts, val)
+	dx, ok, _, _, _ := t.putAndGetDiff(dimensions, true, false, startTs, ts, val)
+	return dx, ok
+}
+
+// DiffWithReset works like Diff, but also reports whether the point is the first one after a reset
+// of a timeseries that was already known, because the start timestamp changed.
+// The diff value is only valid if `ok` is true.
+func (t *ttlCache) DiffWithReset(dimensions *Dimensions, startTs, ts uint64, val float64) (dx float64, ok bool, reset bool) {
+	dx, ok, reset, _, _ = t.putAndGetDiff(dimensions, false, false, startTs, ts, val)
+	return
+}
+
+// MonotonicDiffWithReset works like MonotonicDiff, but also reports whether the point is the first one
+// after a reset of a timeseries that was already known, either because the start timestamp changed or
+// because the value decreased, and returns the timestamp of the point the diff was computed from.
+// The diff value and previous timestamp are only valid if `ok` is true.
+//
+// It also reports whether the value is invalid: the value decreased although the start timestamp is known
+// and unchanged, which is a data quality issue rather than a reset. The (negative) diff is then valid.
+// An invalid point is handled as a reset, unless dropInvalid is set: it is then dropped, neither reported
+// as a reset nor stored, so that the next diff is computed from the last valid point.
+func (t *ttlCache) MonotonicDiffWithReset(
+	dimensions *Dimensions,
+	startTs, ts uint64,
+	val float64,
+	dropInvalid bool,
+) (dx float64, ok bool, reset bool, invalid bool, prevTs uint64) {
+	return t.putAndGetDiff(dimensions, true, dropInvalid, startTs, ts, val)
+}
+
+// Accumulate adds the value of a delta point to the running total of its timeseries, and returns the total
+// along with the start timestamp of the first point of the timeseries. It reports whether the point is the
+// first one of the timeseries. Points older than the last one are accumulated as well, since they still
+// count towards the total.
+func (t *ttlCache) Accumulate(
+	dimensions *Dimensions,
+	startTs, ts uint64,
+	val float64,
+) (total float64, firstStartTs uint64, first bool) {
+	key := dimensions.String() + accumulatedKeySuffix
+	cnt, found := t.get(key)
+	if !found {
+		cnt.startTs = startTs
+	}
+	cnt.value += val
+	if ts > cnt.ts {
+		cnt.ts = ts
+	}
+	t.set(dimensions.name, key, cnt)
+	return cnt.value, cnt.startTs, !found
 }
 
 // isNotFirstPoint determines if this is NOT the first point on a cumulative series:
@@ -66,45 +155,45 @@ func isNotFirstPoint(startTs, ts, oldStartTs uint64) (isNotFirst bool) {
 }
 
 // putAndGetDiff submits a new value for a given metric and returns the difference with the
-// last submitted value (ordered by timestamp). The diff value is only valid if `ok` is true.
+// last submitted value (ordered by timestamp), along with the timestamp of that value.
+// The diff value is only valid if `ok` is true. The reset value indicates whether a known timeseries was reset.
+// The invalid value indicates whether a monotonic value decreased although the start timestamp is known and unchanged;
+// such values are not stored if dropInvalid is set (see MonotonicDiffWithReset).
 func (t *ttlCache) putAndGetDiff(
 	dimensions *Dimensions,
 	monotonic bool,
+	dropInvalid bool,
 	startTs, ts uint64,
 	val float64,
-) (dx float64, ok bool) {
+) (dx float64, ok bool, reset bool, invalid bool, prevTs uint64) {
 	key := dimensions.String()
-	if c, found := t.cache.Get(key); found {
-		cnt := c.(numberCounter)
+	if cnt, found := t.get(key); found {
 		if cnt.ts > ts {
 			// We were given a point older than the one in memory so we drop it
 			// We keep the existing point in memory since it is the most recent
-			return 0, false
+			return 0, false, false, false, 0
 		}
 		dx = val - cnt.value
+		prevTs = cnt.ts
 		// If sequence is monotonic and diff is negative, there has been a reset.
 		// This must never happen if we know the startTs; we also override the value in this case.
-		ok = isNotFirstPoint(startTs, ts, cnt.startTs) && !(monotonic && dx < 0)
+		isNotFirst := isNotFirstPoint(startTs, ts, cnt.startTs)
+		ok = isNotFirst && !(monotonic && dx < 0)
+		reset = !ok
+		invalid = monotonic && dx < 0 && startTs != 0 && isNotFirst
+		if invalid && dropInvalid {
+			return dx, false, false, true, prevTs
+		}
 	}
 
-	t.cache.Set(
-		key,
-		numberCounter{
-			startTs: startTs,
-			ts:      ts,
-			value:   val,
-		},
-		gocache.DefaultExpiration,
-	)
+	t.set(dimensions.name, key, numberCounter{
+		startTs: startTs,
+		ts:      ts,
+		value:   val,
+	})
 	return
 }
 
-type extrema struct {
-	ts            uint64
-	startTs       uint64
-	storedExtrema float64
-}
-
 // putAndCheckExtrema stores a new extrema for a cumulative timeseries and checks if the
 // extrema is the one from the last time window. The min flag indicates whether it is a minimum extrema (true) or a maximum extrema (false).
 func (t *ttlCache) putAndCheckExtrema(
@@ -114,8 +203,7 @@ func (t *ttlCache) putAndCheckExtrema(
 	min bool,
 ) (assumeFromLastWindow bool) {
 	key := dimensions.String()
-	if c, found := t.cache.Get(key); found {
-		cnt := c.(extrema)
+	if cnt, found := t.get(key); found {
 		if cnt.ts > ts {
 			// We were given a point older than the one in memory so we drop it
 			// We keep the existing point in memory since it is the most recent
@@ -128,22 +216,19 @@ func (t *ttlCache) putAndCheckExtrema(
 			// We assume the minimum comes from the last time window if either of the following is true:
 			// - the point is NOT the first in the timeseries AND is lower than the previous one
 			// - the global minimum is bigger than the stored minimum (and therefore a reset must have happened)
-			assumeFromLastWindow = (isNotFirst && curExtrema < cnt.storedExtrema) || (curExtrema > cnt.storedExtrema)
+			assumeFromLastWindow = (isNotFirst && curExtrema < cnt.value) || (curExtrema > cnt.value)
 		} else { // not min, therefore max
 			// symmetric to the min
-			assumeFromLastWindow = (isNotFirst && curExtrema > cnt.storedExtrema) || (curExtrema < cnt.storedExtrema)
+			assumeFromLastWindow = (isNotFirst && curExtrema > cnt.value) || (curExtrema < cnt.value)
 		}
 
 	}
 
-	t.cache.Set(key,
-		extrema{
-			startTs:       startTs,
-			ts:            ts,
-			storedExtrema: curExtrema,
-		},
-		gocache.DefaultExpiration,
-	)
+	t.set(dimensions.name, key, numberCounter{
+		startTs: startTs,
+		ts:      ts,
+		value:   curExtrema,
+	})
 
 	return
 }
@@ -84,6 +84,54 @@ func TestMonotonicDiffKnownStart(t *testing.T) {
 	assert.Equal(t, 9.0, dx, "expected diff 9.0 with (6,7,1) value")
 }
 
+func TestMonotonicDiffWithReset(t *testing.T) {
+	startTs := uint64(1)
+	prevPts := newTestCache()
+	_, ok, reset, _, _ := prevPts.MonotonicDiffWithReset(dims, startTs, 1, 5, false)
+	assert.False(t, ok, "expected no diff: first point")
+	assert.False(t, reset, "expected no reset: first point")
+	_, ok, reset, _, _ = prevPts.MonotonicDiffWithReset(dims, startTs, 0, 0, false)
+	assert.False(t, ok, "expected no diff: old point")
+	assert.False(t, reset, "expected no reset: old point")
+	_, ok, reset, _, _ = prevPts.MonotonicDiffWithReset(dims, startTs, 2, 2, false)
+	assert.False(t, ok, "expected no diff: new < old")
+	assert.True(t, reset, "expected reset: new < old")
+	dx, ok, reset, _, prevTs := prevPts.MonotonicDiffWithReset(dims, startTs, 3, 4, false)
+	assert.True(t, ok, "expected diff: same startTs, old >= new")
+	assert.False(t, reset, "expected no reset: same startTs, old >= new")
+	assert.Equal(t, 2.0, dx, "expected diff 2.0 with (0,2,2) value")
+	assert.Equal(t, uint64(2), prevTs, "expected diff from the point at timestamp 2")
+
+	startTs = uint64(6)
+	_, ok, reset, _, _ = prevPts.MonotonicDiffWithReset(dims, startTs, 7, 10, false)
+	assert.False(t, ok, "expected no diff: reset with known start")
+	assert.True(t, reset, "expected reset: reset with known start")
+}
+
+func TestMonotonicDiffWithResetInvalid(t *testing.T) {
+	startTs := uint64(1)
+	prevPts := newTestCache()
+	prevPts.MonotonicDiffWithReset(dims, startTs, 2, 5, true)
+	dx, ok, reset, invalid, _ := prevPts.MonotonicDiffWithReset(dims, startTs, 3, 2, true)
+	assert.False(t, ok, "expected no diff: new < old")
+	assert.False(t, reset, "expected no reset: invalid point is dropped")
+	assert.True(t, invalid, "expected invalid: same startTs, new < old")
+	assert.Equal(t, -3.0, dx)
+	dx, ok, _, _, prevTs := prevPts.MonotonicDiffWithReset(dims, startTs, 4, 7, true)
+	assert.True(t, ok, "expected diff from the last valid point")
+	assert.Equal(t, 2.0, dx)
+	assert.Equal(t, uint64(2), prevTs)
+
+	// A decrease is not invalid if the start timestamp is unknown or changed.
+	_, _, reset, invalid, _ = prevPts.MonotonicDiffWithReset(dims, 5, 6, 1, true)
+	assert.True(t, reset, "expected reset: new startTs")
+	assert.False(t, invalid, "expected valid: new startTs")
+	prevPts.MonotonicDiffWithReset(dims, 0, 7, 10, true)
+	_, _, reset, invalid, _ = prevPts.MonotonicDiffWithReset(dims, 0, 8, 1, true)
+	assert.True(t, reset, "expected reset: unknown startTs")
+	assert.False(t, invalid, "expected valid: unknown startTs")
+}
+
 func TestDiffKnownStart(t *testing.T) {
 	startTs := uint64(1)
 	prevPts := newTestCache()
@@ -113,6 +161,27 @@ func TestDiffKnownStart(t *testing.T) {
 	assert.Equal(t, 9.0, dx, "expected diff 9.0 with (6,7,1) value")
 }
 
+func TestAccumulate(t *testing.T) {
+	prevPts := newTestCache()
+	total, startTs, first := prevPts.Accumulate(dims, 1, 2, 3)
+	assert.Equal(t, 3.0, total)
+	assert.Equal(t, uint64(1), startTs)
+	assert.True(t, first)
+	total, startTs, first = prevPts.Accumulate(dims, 2, 3, 5)
+	assert.Equal(t, 8.0, total)
+	assert.Equal(t, uint64(1), startTs, "expected the start timestamp of the first point")
+	assert.False(t, first)
+	// Older points are accumulated as well.
+	total, _, _ = prevPts.Accumulate(dims, 0, 1, 2)
+	assert.Equal(t, 10.0, total)
+
+	// The total doesn't collide with the diffs of the timeseries.
+	_, ok := prevPts.MonotonicDiff(dims, 1, 4, 20)
+	assert.False(t, ok, "expected no diff: first point")
+	total, _, _ = prevPts.Accumulate(dims, 3, 4, 1)
+	assert.Equal(t, 11.0, total)
+}
+
 func TestPutAndGetExtrema(t *testing.T) {
 	points := []struct {
 		min                  float64
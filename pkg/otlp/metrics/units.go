@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// UnitConversion describes how to normalize metrics with a given OTLP unit.
+type UnitConversion struct {
+	// Scale is the factor by which values are multiplied.
+	Scale float64
+	// Suffix is appended to the metric name. It may be empty.
+	Suffix string
+}
+
+// UnitConversionTable maps OTLP units (e.g. "ms", "By") to their conversion.
+type UnitConversionTable map[string]UnitConversion
+
+var _ TimeSeriesConsumer = (*scaledTimeSeriesConsumer)(nil)
+var _ TimeSeriesIntervalConsumer = (*scaledTimeSeriesConsumer)(nil)
+var _ ExemplarConsumer = (*scaledTimeSeriesConsumer)(nil)
+
+// scaledTimeSeriesConsumer is a TimeSeriesConsumer that scales values before passing them to another consumer.
+type scaledTimeSeriesConsumer struct {
+	consumer TimeSeriesConsumer
+	scale    float64
+}
+
+// ConsumeTimeSeries implements the TimeSeriesConsumer interface.
+func (c *scaledTimeSeriesConsumer) ConsumeTimeSeries(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	value float64,
+) {
+	c.consumer.ConsumeTimeSeries(ctx, dimensions, typ, timestamp, value*c.scale)
+}
+
+// ConsumeTimeSeriesWithInterval implements the TimeSeriesIntervalConsumer interface.
+func (c *scaledTimeSeriesConsumer) ConsumeTimeSeriesWithInterval(
+	ctx context.Context,
+	dimensions *Dimensions,
+	typ DataType,
+	timestamp uint64,
+	interval int64,
+	value float64,
+) {
+	consumeTimeSeriesWithInterval(ctx, c.consumer, dimensions, typ, timestamp, interval, value*c.scale)
+}
+
+// ConsumeExemplars implements the ExemplarConsumer interface.
+func (c *scaledTimeSeriesConsumer) ConsumeExemplars(
+	ctx context.Context,
+	dimensions *Dimensions,
+	timestamp uint64,
+	exemplars []Exemplar,
+) {
+	scaled := make([]Exemplar, len(exemplars))
+	for i, e := range exemplars {
+		e.Value *= c.scale
+		scaled[i] = e
+	}
+	consumeExemplars(ctx, c.consumer, dimensions, timestamp, scaled)
+}
+
+// unitConversion returns the unit conversion to apply to a metric, if any.
+// Unit conversions only apply to Gauge and Sum metrics.
+func (t *Translator) unitConversion(md pmetric.Metric) (UnitConversion, bool) {
+	if t.cfg.unitConversionTable == nil || md.Unit() == "" {
+		return UnitConversion{}, false
+	}
+
+	switch md.Type() {
+	case pmetric.MetricTypeGauge, pmetric.MetricTypeSum:
+	default:
+		return UnitConversion{}, false
+	}
+
+	conversion, ok := t.cfg.unitConversionTable[md.Unit()]
+	if !ok {
+		t.logger.Debug("Unknown unit, skipping unit normalization",
+			zap.String(metricName, md.Name()),
+			zap.String("unit", md.Unit()),
+		)
+	}
+	return conversion, ok
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metrics
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithUnitNormalizationInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		table UnitConversionTable
+		err   string
+	}{
+		{
+			name:  "zero scale",
+			table: UnitConversionTable{"ms": {Scale: 0}},
+			err:   `invalid scale for unit "ms": 0`,
+		},
+		{
+			name:  "negative scale",
+			table: UnitConversionTable{"ms": {Scale: -1}},
+			err:   `invalid scale for unit "ms": -1`,
+		},
+		{
+			name:  "NaN scale",
+			table: UnitConversionTable{"ms": {Scale: math.NaN()}},
+			err:   `invalid scale for unit "ms": NaN`,
+		},
+		{
+			name:  "invalid suffix",
+			table: UnitConversionTable{"ms": {Scale: 0.001, Suffix: "/s"}},
+			err:   `invalid suffix for unit "ms": "/s"`,
+		},
+	}
+
+	for _, testInstance := range tests {
+		t.Run(testInstance.name, func(t *testing.T) {
+			_, err := NewTranslator(zap.NewNop(), WithUnitNormalization(testInstance.table))
+			assert.EqualError(t, err, testInstance.err)
+		})
+	}
+}
+
+func TestMapMetricsUnitNormalization(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metricsArray := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+
+	newGaugeMetric := func(name, unit string, val float64) {
+		met := metricsArray.AppendEmpty()
+		met.SetName(name)
+		met.SetUnit(unit)
+		dp := met.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(seconds(1))
+		dp.SetDoubleValue(val)
+	}
+	newGaugeMetric("request.duration", "ms", 1500)
+	newGaugeMetric("memory.usage", "KiBy", 2)
+	newGaugeMetric("queue.size", "{requests}", 10)
+	newGaugeMetric("cpu.utilization", "", 0.5)
+
+	delta := metricsArray.AppendEmpty()
+	delta.SetName("request.time")
+	delta.SetUnit("ms")
+	sum := delta.SetEmptySum()
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	sum.SetIsMonotonic(true)
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetTimestamp(seconds(1))
+	dp.SetIntValue(250)
+
+	core, observed := observer.New(zapcore.DebugLevel)
+	tr, err := NewTranslator(zap.New(core), WithUnitNormalization(UnitConversionTable{
+		"ms":   {Scale: 0.001},
+		"KiBy": {Scale: 1024, Suffix: ".bytes"},
+	}))
+	require.NoError(t, err)
+
+	consumer := &mockFullConsumer{}
+	_, err = tr.MapMetrics(context.Background(), md, consumer)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []metric{
+		newGauge(newDims("request.duration"), uint64(seconds(1)), 1.5),
+		newGauge(newDims("memory.usage.bytes"), uint64(seconds(1)), 2048),
+		newGauge(newDims("queue.size"), uint64(seconds(1)), 10),
+		newGauge(newDims("cpu.utilization"), uint64(seconds(1)), 0.5),
+		newCount(newDims("request.time"), uint64(seconds(1)), 0.25),
+	}, consumer.metrics)
+
+	logs := observed.FilterMessage("Unknown unit, skipping unit normalization")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "{requests}", logs.All()[0].ContextMap()["unit"])
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/DataDog/sketches-go/ddsketch"
 	"github.com/DataDog/sketches-go/ddsketch/mapping"
 	"github.com/DataDog/sketches-go/ddsketch/store"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile/summary"
 )
@@ -216,3 +217,48 @@ func ConvertDDSketchIntoSketch(inputSketch *ddsketch.DDSketch) (*Sketch, error)
 
 	return outputSketch, nil
 }
+
+// ConvertSketchIntoDDSketch converts a Sketch into a DDSketch with a logarithmic mapping
+// that matches the Sketch parameters and collapsing lowest dense stores.
+// Summary statistics (sum, min and max) are not kept by the DDSketch.
+func ConvertSketchIntoDDSketch(s *Sketch) (*ddsketch.DDSketch, error) {
+	sketchConfig := Default()
+
+	// Note: there's a 0.5 shift here because we take the floor value on DDSketch, vs. rounding to
+	// integer in the Agent sketch.
+	offset := float64(sketchConfig.norm.bias) + 0.5
+	sketchMapping, err := mapping.NewLogarithmicMappingWithGamma(sketchConfig.gamma.v, offset)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create LogarithmicMapping for DDSketch: %w", err)
+	}
+
+	positiveStore := store.NewCollapsingLowestDenseStore(sketchConfig.binLimit)
+	negativeStore := store.NewCollapsingLowestDenseStore(sketchConfig.binLimit)
+	outputSketch := ddsketch.NewDDSketch(sketchMapping, positiveStore, negativeStore)
+
+	for _, b := range s.bins {
+		switch {
+		case b.k > 0:
+			positiveStore.AddWithCount(int(b.k), float64(b.n))
+		case b.k < 0:
+			negativeStore.AddWithCount(int(-b.k), float64(b.n))
+		default:
+			// Zeroes are counted separately by the DDSketch.
+			if err := outputSketch.AddWithCount(0, float64(b.n)); err != nil {
+				return nil, fmt.Errorf("couldn't add zeroes to DDSketch: %w", err)
+			}
+		}
+	}
+
+	return outputSketch, nil
+}
+
+// SketchPayload serializes the Sketch into a DDSketch protobuf message, after converting it
+// with ConvertSketchIntoDDSketch.
+func (s *Sketch) SketchPayload() ([]byte, error) {
+	ddSketch, err := ConvertSketchIntoDDSketch(s)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't convert Sketch into DDSketch: %w", err)
+	}
+	return proto.Marshal(ddSketch.ToProto())
+}
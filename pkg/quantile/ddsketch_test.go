@@ -7,11 +7,14 @@ package quantile
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/internal/sketchtest"
 )
@@ -353,3 +356,64 @@ func TestConvertDDSketchIntoSketch(t *testing.T) {
 		})
 	}
 }
+
+// quantileValues returns N+1 values placed where the quantiles of the distribution are.
+func quantileValues(quantile sketchtest.QuantileFunction, N int) []float64 {
+	values := make([]float64, 0, N+1)
+	for i := 0; i <= N; i++ {
+		values = append(values, quantile(float64(i)/float64(N)))
+	}
+	return values
+}
+
+func TestSketchPayloadRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+	}{
+		{
+			name:   "positive values",
+			values: quantileValues(sketchtest.UniformQ(1, 1_000), 1_000),
+		},
+		{
+			name:   "negative and positive values",
+			values: quantileValues(sketchtest.UniformQ(-1_000, 1_000), 1_000),
+		},
+		{
+			name:   "with zeroes",
+			values: append(quantileValues(sketchtest.UniformQ(1, 100), 100), make([]float64, 50)...),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sketch := &Sketch{}
+			sketch.Insert(Default(), test.values...)
+
+			payload, err := sketch.SketchPayload()
+			require.NoError(t, err)
+
+			var pb sketchpb.DDSketch
+			require.NoError(t, proto.Unmarshal(payload, &pb))
+			ddSketch, err := ddsketch.FromProto(&pb)
+			require.NoError(t, err)
+
+			assert.Equal(t, float64(len(test.values)), ddSketch.GetCount())
+
+			sorted := append([]float64(nil), test.values...)
+			sort.Float64s(sorted)
+			for i := 0; i <= 100; i++ {
+				q := float64(i) / 100.0
+				expected := sorted[int(q*float64(len(sorted)-1))]
+				actual, err := ddSketch.GetValueAtQuantile(q)
+				require.NoError(t, err)
+				if expected == 0 {
+					assert.InDelta(t, expected, actual, acceptableFloatError, "quantile %g", q)
+				} else {
+					// Values are mapped to the same bins as in the Sketch.
+					assert.InEpsilon(t, expected, actual, 2*defaultEps, "quantile %g", q)
+				}
+			}
+		})
+	}
+}
@@ -7,12 +7,12 @@ require (
 	github.com/DataDog/sketches-go v1.4.2
 	github.com/dustin/go-humanize v1.0.1
 	github.com/stretchr/testify v1.8.4
+	google.golang.org/protobuf v1.28.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	google.golang.org/protobuf v1.28.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 